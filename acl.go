@@ -0,0 +1,62 @@
+package grpcpool
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// WithAllowedMethods rejects calls to every method not matching one of patterns with codes.PermissionDenied,
+// without sending them.
+//
+// A pattern is either a full method name, e.g. "/pkg.Service/Get", or a prefix followed by "*", e.g. "/pkg.Service/*".
+// It is a guardrail for pools handed to semi-trusted code: calls made directly on the ClientConns obtained from Conn
+// and Conns are rejected as well.
+func WithAllowedMethods(patterns ...string) Option {
+	return func(o *options) {
+		o.allowed = newMethodMatcher(patterns)
+	}
+}
+
+// WithDeniedMethods rejects calls to methods matching one of patterns with codes.PermissionDenied,
+// without sending them.
+//
+// Patterns are as for WithAllowedMethods. Denied methods take precedence over allowed ones.
+func WithDeniedMethods(patterns ...string) Option {
+	return func(o *options) {
+		o.denied = newMethodMatcher(patterns)
+	}
+}
+
+// checkMethod returns an error if calls to method are not permitted.
+func (rt *runtimeConfig) checkMethod(method string) error {
+	if rt.denied != nil && rt.denied.match(method) {
+		return status.Errorf(codes.PermissionDenied, "grpcpool: method %s is denied", method)
+	}
+	if rt.allowed != nil && !rt.allowed.match(method) {
+		return status.Errorf(codes.PermissionDenied, "grpcpool: method %s is not allowed", method)
+	}
+	return nil
+}
+
+// aclDialOptions returns the interceptors checking the methods of calls made on the connections of p, so calls
+// bypassing Invoke and NewStream through Conn or Conns are rejected too. They read the current runtimeConfig, so
+// SetAllowedMethods and SetDeniedMethods apply to them. Only pools created with WithAllowedMethods or
+// WithDeniedMethods get them, as chaining interceptors allocates on every call.
+func (p *Pool) aclDialOptions() []grpc.DialOption {
+	unary := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := p.runtime().checkMethod(method); err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	stream := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if err := p.runtime().checkMethod(method); err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+	return []grpc.DialOption{grpc.WithChainUnaryInterceptor(unary), grpc.WithChainStreamInterceptor(stream)}
+}
@@ -0,0 +1,61 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestAllowedMethods(t *testing.T) {
+	l := healthTestServer(t, "", healthpb.HealthCheckResponse_SERVING)
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithAllowedMethods("/grpc.health.v1.Health/*"),
+		WithDeniedMethods("/grpc.health.v1.Health/Watch"),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	client := healthpb.NewHealthClient(pool)
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Errorf("allowed Check failed: %v", err)
+	}
+	if _, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("denied Watch got %v; want PermissionDenied", err)
+	}
+	err = pool.Invoke(context.Background(), "/pkg.Service/Get", &healthpb.HealthCheckRequest{}, &healthpb.HealthCheckResponse{})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("unlisted method got %v; want PermissionDenied", err)
+	}
+	if got := pool.GroupStats("").Calls; got != 1 {
+		t.Errorf("pool sent %d calls; want 1", got)
+	}
+
+	// Calls made directly on the ClientConns of the pool are checked too.
+	conn := healthpb.NewHealthClient(pool.Conn())
+	if _, err := conn.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Errorf("allowed Check on Conn() failed: %v", err)
+	}
+	watch, err := conn.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	if err == nil {
+		_, err = watch.Recv()
+	}
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("denied Watch on Conn() got %v; want PermissionDenied", err)
+	}
+	err = pool.Conns()[0].ClientConn().Invoke(context.Background(), "/pkg.Service/Get", &healthpb.HealthCheckRequest{}, &healthpb.HealthCheckResponse{})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("unlisted method on Conns() got %v; want PermissionDenied", err)
+	}
+	pool.SetDeniedMethods("/grpc.health.v1.Health/Check")
+	if _, err := conn.Check(context.Background(), &healthpb.HealthCheckRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Check on Conn() denied by SetDeniedMethods got %v; want PermissionDenied", err)
+	}
+}
@@ -0,0 +1,66 @@
+package grpcpool
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// WithAffinityFunc routes every call for which f returns a key to a connection chosen by hashing the key, so calls
+// with the same key land on the same connection.
+//
+// It lets applications derive affinity from their own context values, such as a tenant ID, user ID or shard key.
+// Calls for which f returns false use the pool's Picker. WithAffinityFunc replaces WithAffinityMetadata.
+func WithAffinityFunc(f func(ctx context.Context) (key string, ok bool)) Option {
+	return func(o *options) {
+		o.affinity = f
+	}
+}
+
+// WithAffinityMetadata routes every call whose outgoing metadata contains key to a connection chosen by hashing its
+// value, so calls with the same value land on the same connection, e.g. to benefit from backend-local caching.
+//
+// Calls without key use the pool's Picker. WithAffinityMetadata replaces WithAffinityFunc.
+func WithAffinityMetadata(key string) Option {
+	return WithAffinityFunc(func(ctx context.Context) (string, bool) {
+		md, ok := metadata.FromOutgoingContext(ctx)
+		if !ok {
+			return "", false
+		}
+		if v := md.Get(key); len(v) > 0 && v[0] != "" {
+			return v[0], true
+		}
+		return "", false
+	})
+}
+
+// affinityKey returns the affinity key of a call, if any.
+func (o *options) affinityKey(ctx context.Context) (string, bool) {
+	if o.affinity == nil || ctx == nil {
+		return "", false
+	}
+	key, ok := o.affinity(ctx)
+	return key, ok && key != ""
+}
+
+// rendezvous returns the connection in conns with the highest hash for key.
+//
+// Rendezvous hashing keeps the mapping of most keys stable when connections are added, removed or become
+// unhealthy.
+func rendezvous(key string, conns []*PoolConn) *PoolConn {
+	var best *PoolConn
+	var bestScore uint64
+	for _, c := range conns {
+		h := fnv.New64a()
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		h.Write([]byte(c.endpoint.Addr))
+		h.Write([]byte(strconv.Itoa(c.index)))
+		if score := h.Sum64(); best == nil || score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	return best
+}
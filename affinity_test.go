@@ -0,0 +1,135 @@
+package grpcpool
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+)
+
+// callsPerConn returns the number of finished calls on every conn of pool.
+func callsPerConn(pool *Pool) []int64 {
+	var calls []int64
+	for _, c := range pool.Conns() {
+		calls = append(calls, c.calls.Load())
+	}
+	return calls
+}
+
+func TestAffinityMetadata(t *testing.T) {
+	l := healthTestServer(t, "", healthpb.HealthCheckResponse_SERVING)
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(4),
+		WithAffinityMetadata("x-affinity-key"),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	client := healthpb.NewHealthClient(pool)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-affinity-key", "tenant-1")
+	for i := 0; i < 10; i++ {
+		if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	used := 0
+	for _, n := range callsPerConn(pool) {
+		if n > 0 {
+			used++
+			if n != 10 {
+				t.Errorf("affinity conn got %d calls; want 10", n)
+			}
+		}
+	}
+	if used != 1 {
+		t.Errorf("calls with one affinity key used %d conns; want 1", used)
+	}
+
+	// Calls without the key are spread in round-robin order.
+	for i := 0; i < 4; i++ {
+		if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, n := range callsPerConn(pool) {
+		if n == 0 {
+			t.Errorf("conn #%d got no calls without an affinity key", i)
+		}
+	}
+}
+
+func TestRendezvous(t *testing.T) {
+	var conns []*PoolConn
+	for i := 0; i < 8; i++ {
+		conns = append(conns, &PoolConn{endpoint: Endpoint{Addr: "localhost:443"}, index: i})
+	}
+
+	counts := map[*PoolConn]int{}
+	moved := 0
+	for k := 0; k < 1000; k++ {
+		key := fmt.Sprintf("key-%d", k)
+		c := rendezvous(key, conns)
+		if rendezvous(key, conns) != c {
+			t.Fatalf("rendezvous(%q) is not deterministic", key)
+		}
+		counts[c]++
+		// Removing an unrelated conn must not move the key.
+		if c != conns[0] && rendezvous(key, conns[1:]) != c {
+			moved++
+		}
+	}
+	if moved != 0 {
+		t.Errorf("removing a conn moved %d keys mapped to other conns", moved)
+	}
+	for _, c := range conns {
+		if counts[c] < 60 || counts[c] > 190 {
+			t.Errorf("conn #%d got %d of 1000 keys; want roughly 125", c.index, counts[c])
+		}
+	}
+}
+
+type tenantKey struct{}
+
+func TestAffinityFunc(t *testing.T) {
+	_, l := mockServer(t)
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(8),
+		WithAffinityFunc(func(ctx context.Context) (string, bool) {
+			tenant, ok := ctx.Value(tenantKey{}).(string)
+			return tenant, ok
+		}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	s := pool.set.Load()
+	pick := func(ctx context.Context) *PoolConn {
+		return pool.pick(s, pool.pickInfo(ctx, "/pkg.Service/Method", false, nil, nil))
+	}
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "tenant-1")
+	want := pick(ctx)
+	for i := 0; i < 10; i++ {
+		if got := pick(ctx); got != want {
+			t.Fatalf("pick #%d for tenant-1 got conn #%d; want conn #%d", i, got.index, want.index)
+		}
+	}
+
+	seen := map[*PoolConn]bool{}
+	for i := 0; i < 8; i++ {
+		seen[pick(context.Background())] = true
+	}
+	if len(seen) != 8 {
+		t.Errorf("picks without a tenant used %d conns; want 8", len(seen))
+	}
+}
@@ -0,0 +1,99 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// TestAllocs guards the allocations of the hot path: picking and unary calls allocate nothing when the pool adds
+// no options, and streams allocate only their release bookkeeping.
+func TestAllocs(t *testing.T) {
+	failStream := func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, grpc.Streamer, ...grpc.CallOption) (grpc.ClientStream, error) {
+		return nil, context.Canceled
+	}
+	ctx := context.Background()
+	desc := &grpc.StreamDesc{}
+	for _, size := range []uint{1, 4} {
+		pool, err := NewPool(ctx, "localhost:1",
+			WithSize(size),
+			WithDialOptions(
+				grpc.WithTransportCredentials(insecure.NewCredentials()),
+				grpc.WithUnaryInterceptor(nopInvoker),
+				grpc.WithStreamInterceptor(failStream),
+			),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer pool.Close()
+
+		for name, tc := range map[string]struct {
+			f    func()
+			want float64
+		}{
+			"Conn":      {func() { pool.Conn() }, 0},
+			"Invoke":    {func() { pool.Invoke(ctx, "/test.Test/Call", nil, nil) }, 0},
+			"NewStream": {func() { pool.NewStream(ctx, desc, "/test.Test/Stream") }, 2},
+		} {
+			if got := testing.AllocsPerRun(100, tc.f); got > tc.want {
+				t.Errorf("%s with %d conns got %v allocs; want at most %v", name, size, got, tc.want)
+			}
+		}
+	}
+
+	legacy := &roundRobinConnPool{conns: make([]*grpc.ClientConn, 3)}
+	if got := testing.AllocsPerRun(100, func() { legacy.Conn() }); got != 0 {
+		t.Errorf("legacy Conn got %v allocs; want 0", got)
+	}
+}
+
+// TestAllocsBookkeeping guards that the per-call bookkeeping of call stats, labels, concurrency limits and ORCA
+// load reports doesn't allocate.
+func TestAllocsBookkeeping(t *testing.T) {
+	ctx := ContextWithCallLabel(context.Background(), "batch")
+	for name, opt := range map[string]Option{
+		"ConcurrencyLimit": WithConcurrencyLimit(10, 10),
+		"ORCA":             WithORCA(),
+		"StreamGrowth":     WithStreamGrowth(StreamGrowthConfig{MaxConns: 8}),
+	} {
+		pool, err := NewPool(ctx, "localhost:1",
+			WithSize(4),
+			opt,
+			WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithUnaryInterceptor(nopInvoker)),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer pool.Close()
+
+		if got := testing.AllocsPerRun(100, func() { pool.Invoke(ctx, "/test.Test/Call", nil, nil) }); got != 0 {
+			t.Errorf("labeled Invoke with %s got %v allocs; want 0", name, got)
+		}
+	}
+}
+
+func BenchmarkPoolNewStream(b *testing.B) {
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithSize(4),
+		WithDialOptions(
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithStreamInterceptor(func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, grpc.Streamer, ...grpc.CallOption) (grpc.ClientStream, error) {
+				return nil, context.Canceled
+			}),
+		),
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer pool.Close()
+	desc := &grpc.StreamDesc{}
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			pool.NewStream(context.Background(), desc, "/test.Test/Stream")
+		}
+	})
+}
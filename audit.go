@@ -0,0 +1,173 @@
+package grpcpool
+
+import (
+	"context"
+	"crypto/x509"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultAuditBuffer is the number of audit records buffered for delivery if WithAudit is given no buffer size.
+const DefaultAuditBuffer = 1024
+
+// AuditRecord describes a finished outbound call or stream, see WithAudit.
+type AuditRecord struct {
+	Method string
+	Target string // address of the endpoint of the connection
+	Conn   int    // index of the connection
+
+	// Principal is the identity of the server authenticated by the transport credentials: the SPIFFE ID or,
+	// without one, the common name or first DNS name of its certificate. It is empty without TLS.
+	Principal string
+
+	Code     codes.Code
+	Start    time.Time
+	Duration time.Duration
+}
+
+// WithAudit delivers an AuditRecord for every call and stream of the pool to f, for outbound-call audit trails.
+//
+// Records are buffered, up to buffer of them (DefaultAuditBuffer if zero), and delivered in order by a single
+// goroutine, so f doesn't slow down calls. Records that don't fit in the buffer are dropped and counted, see
+// Pool.AuditDropped. Close delivers the buffered records before returning.
+func WithAudit(f func(AuditRecord), buffer int) Option {
+	if buffer <= 0 {
+		buffer = DefaultAuditBuffer
+	}
+	return func(o *options) {
+		o.audit = &audit{f: f, records: make(chan AuditRecord, buffer)}
+	}
+}
+
+// AuditDropped returns the number of audit records dropped because the buffer was full, see WithAudit.
+func (p *Pool) AuditDropped() int64 {
+	if p.opts.audit == nil {
+		return 0
+	}
+	return p.opts.audit.dropped.Load()
+}
+
+type audit struct {
+	f       func(AuditRecord)
+	records chan AuditRecord
+	dropped atomic.Int64
+}
+
+// start returns the start time of a call, or the zero time if a is nil.
+func (a *audit) start(clock Clock) time.Time {
+	if a == nil {
+		return time.Time{}
+	}
+	return clock.Now()
+}
+
+// record queues the record of a call on c started at start. a may be nil.
+func (a *audit) record(clock Clock, c *PoolConn, method string, start time.Time, err error) {
+	if a == nil {
+		return
+	}
+	r := AuditRecord{
+		Method:   method,
+		Target:   c.endpoint.Addr,
+		Conn:     c.index,
+		Code:     status.Code(err),
+		Start:    start,
+		Duration: clock.Now().Sub(start),
+	}
+	if principal := c.principal.Load(); principal != nil {
+		r.Principal = *principal
+	}
+	select {
+	case a.records <- r:
+	default:
+		a.dropped.Add(1)
+	}
+}
+
+// run delivers records until stop is called, then delivers the buffered ones.
+func (a *audit) run() (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case r := <-a.records:
+				a.f(r)
+			case <-done:
+				for {
+					select {
+					case r := <-a.records:
+						a.f(r)
+					default:
+						return
+					}
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// auditDialOptions returns the dial options recording the server principal of c, if audited.
+func (o *options) auditDialOptions(c *PoolConn) []grpc.DialOption {
+	if o.audit == nil {
+		return nil
+	}
+	return []grpc.DialOption{grpc.WithStatsHandler(principalWatcher{c})}
+}
+
+// principalWatcher is a stats.Handler recording the server principal of the transports of a connection.
+type principalWatcher struct {
+	conn *PoolConn
+}
+
+func (w principalWatcher) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (w principalWatcher) HandleRPC(context.Context, stats.RPCStats) {}
+
+func (w principalWatcher) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (w principalWatcher) HandleConn(ctx context.Context, s stats.ConnStats) {
+	if _, ok := s.(*stats.ConnBegin); !ok {
+		return
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.PeerCertificates) > 0 {
+			principal := certPrincipal(info.State.PeerCertificates[0])
+			w.conn.principal.Store(&principal)
+		}
+	}
+}
+
+// certPrincipal returns the identity in cert, see AuditRecord.Principal.
+func certPrincipal(cert *x509.Certificate) string {
+	for _, u := range cert.URIs {
+		if u.Scheme == "spiffe" {
+			return u.String()
+		}
+	}
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	if len(cert.DNSNames) > 0 {
+		return cert.DNSNames[0]
+	}
+	return ""
+}
@@ -0,0 +1,138 @@
+package grpcpool
+
+import (
+	"context"
+	"crypto/tls"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestAudit(t *testing.T) {
+	_, l := mockServer(t)
+
+	var mu sync.Mutex
+	var records []AuditRecord
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(2),
+		WithAudit(func(r AuditRecord) {
+			mu.Lock()
+			records = append(records, r)
+			mu.Unlock()
+		}, 0),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pool.Invoke(ctx, "/test.Service/Unary", &emptypb.Empty{}, &emptypb.Empty{}); status.Code(err) != codes.Unimplemented {
+		t.Fatalf("Invoke() got %v; want Unimplemented", err)
+	}
+	cs, err := pool.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, "/test.Service/Stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs.RecvMsg(&emptypb.Empty{})
+	pool.Close() // delivers the buffered records
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(records) != 2 {
+		t.Fatalf("got %d audit records; want 2", len(records))
+	}
+	for i, method := range []string{"/test.Service/Unary", "/test.Service/Stream"} {
+		r := records[i]
+		if r.Method != method || r.Target != l.Addr().String() || r.Code != codes.Unimplemented || r.Principal != "" {
+			t.Errorf("record %d got %+v; want %s on %s with Unimplemented", i, r, method, l.Addr())
+		}
+		if r.Start.IsZero() || r.Duration < 0 {
+			t.Errorf("record %d got start %v, duration %v", i, r.Start, r.Duration)
+		}
+	}
+	if records[0].Conn == records[1].Conn {
+		t.Errorf("both calls recorded on conn %d; want round robin over both conns", records[0].Conn)
+	}
+}
+
+func TestAuditCloseAfterShutdown(t *testing.T) {
+	_, l := mockServer(t)
+
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithAudit(func(AuditRecord) {}, 0),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pool.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	pool.Close() // stops the audit again
+	pool.Close()
+}
+
+func TestAuditPrincipal(t *testing.T) {
+	ca := newTestCA(t)
+	l, _ := mtlsServer(t, ca)
+
+	clientCert := ca.issue(t, 1, time.Now().Add(time.Hour))
+	records := make(chan AuditRecord, 1)
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithCertificateRotation(&tls.Config{RootCAs: ca.pool, ServerName: "localhost"}, func() (*tls.Certificate, error) {
+			return &clientCert, nil
+		}, time.Hour),
+		WithAudit(func(r AuditRecord) { records <- r }, 0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	pool.Invoke(ctx, "/test.Service/Unary", &emptypb.Empty{}, &emptypb.Empty{})
+	select {
+	case r := <-records:
+		if r.Principal != testServerID {
+			t.Errorf("Principal got %q; want %q", r.Principal, testServerID)
+		}
+	case <-ctx.Done():
+		t.Fatal("no audit record delivered")
+	}
+}
+
+func TestAuditDropped(t *testing.T) {
+	_, l := mockServer(t)
+
+	block := make(chan struct{})
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithAudit(func(AuditRecord) { <-block }, 1),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	defer close(block)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for i := 0; i < 5; i++ {
+		pool.Invoke(ctx, "/test.Service/Unary", &emptypb.Empty{}, &emptypb.Empty{})
+	}
+	// One record is being delivered and one is buffered.
+	if got := pool.AuditDropped(); got < 3 {
+		t.Errorf("AuditDropped() got %d; want at least 3", got)
+	}
+}
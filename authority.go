@@ -0,0 +1,12 @@
+package grpcpool
+
+// WithAuthorityFunc sets a function deriving the :authority of endpoints without Endpoint.Authority.
+//
+// Every connection presents the authority of its endpoint, so a pool can spread over the addresses behind one
+// virtual host, e.g. with f returning the host name for every address, or over the virtual hosts of one address,
+// with endpoints that have the same Addr and different authorities.
+func WithAuthorityFunc(f func(Endpoint) string) Option {
+	return func(o *options) {
+		o.authorityFunc = f
+	}
+}
@@ -0,0 +1,90 @@
+package grpcpool
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// authorityServer starts a server recording the :authority of every call.
+func authorityServer(t *testing.T) (net.Listener, func() map[string]int) {
+	t.Helper()
+	var mu sync.Mutex
+	seen := map[string]int{}
+	s := grpc.NewServer(grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		mu.Lock()
+		for _, v := range md.Get(":authority") {
+			seen[v]++
+		}
+		mu.Unlock()
+		return nil
+	}))
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+	return l, func() map[string]int {
+		mu.Lock()
+		defer mu.Unlock()
+		copied := map[string]int{}
+		for k, v := range seen {
+			copied[k] = v
+		}
+		return copied
+	}
+}
+
+func TestEndpointAuthority(t *testing.T) {
+	l, seen := authorityServer(t)
+	addr := l.Addr().String()
+
+	pool, err := NewEndpointPool(context.Background(), []Endpoint{
+		{Addr: addr, Authority: "a.example.org"},
+		{Addr: addr, Authority: "b.example.org"},
+	}, WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	for i := 0; i < 4; i++ {
+		pool.Conns()[i%2].ClientConn().Invoke(context.Background(), "/test.Service/Call", nil, nil)
+	}
+	if got := seen(); len(got) != 2 || got["a.example.org"] != 2 || got["b.example.org"] != 2 {
+		t.Errorf("server saw authorities %v; want 2 calls each to a.example.org and b.example.org", got)
+	}
+}
+
+func TestAuthorityFunc(t *testing.T) {
+	l, seen := authorityServer(t)
+
+	pool, err := NewEndpointPool(context.Background(), []Endpoint{{Addr: l.Addr().String()}, {Addr: l.Addr().String(), Authority: "explicit"}},
+		WithAuthorityFunc(func(e Endpoint) string {
+			return "vhost-" + strings.SplitN(e.Addr, ":", 2)[0]
+		}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	if got := pool.Conns()[0].Endpoint().Authority; got != "vhost-127.0.0.1" {
+		t.Errorf("derived authority got %q; want vhost-127.0.0.1", got)
+	}
+	for _, c := range pool.Conns() {
+		c.ClientConn().Invoke(context.Background(), "/test.Service/Call", nil, nil)
+	}
+	if got := seen(); got["vhost-127.0.0.1"] != 1 || got["explicit"] != 1 {
+		t.Errorf("server saw authorities %v; want one call each to vhost-127.0.0.1 and explicit", got)
+	}
+}
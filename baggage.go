@@ -0,0 +1,54 @@
+package grpcpool
+
+import (
+	"context"
+	"strconv"
+)
+
+// Baggage members set by WithBaggage.
+const (
+	BaggagePool = "grpcpool.pool"
+	BaggageConn = "grpcpool.conn"
+)
+
+// BaggageFunc returns ctx with members added to its OpenTelemetry baggage. members must not be modified.
+//
+// With go.opentelemetry.io/otel/baggage it is
+//
+//	func(ctx context.Context, members map[string]string) context.Context {
+//		b := baggage.FromContext(ctx)
+//		for k, v := range members {
+//			if m, err := baggage.NewMember(k, v); err == nil {
+//				b, _ = b.SetMember(m)
+//			}
+//		}
+//		return baggage.ContextWithBaggage(ctx, b)
+//	}
+type BaggageFunc func(ctx context.Context, members map[string]string) context.Context
+
+// WithBaggage adds the baggage members BaggagePool=name and BaggageConn=<conn index> to the context of every
+// call and stream through f, so downstream services and collectors propagating the baggage can aggregate by
+// originating pool and connection.
+//
+// The baggage is only sent if the application propagates it, e.g. with the otelgrpc client interceptors.
+func WithBaggage(name string, f BaggageFunc) Option {
+	return func(o *options) {
+		o.baggageName = name
+		o.baggage = f
+	}
+}
+
+// setBaggage sets the baggage members of c, see WithBaggage.
+func (o *options) setBaggage(c *PoolConn) {
+	if o.baggage != nil {
+		c.baggage = map[string]string{BaggagePool: o.baggageName, BaggageConn: strconv.Itoa(c.index)}
+	}
+}
+
+// withBaggage returns ctx with the baggage members of c, if any.
+func (o *options) withBaggage(ctx context.Context, c *PoolConn) context.Context {
+	if c.baggage == nil {
+		return ctx
+	}
+	return o.baggage(ctx, c.baggage)
+}
@@ -0,0 +1,42 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type baggageKey struct{}
+
+func TestBaggage(t *testing.T) {
+	_, l := mockServer(t)
+	var got map[string]string
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(2),
+		WithBaggage("billing", func(ctx context.Context, members map[string]string) context.Context {
+			return context.WithValue(ctx, baggageKey{}, members)
+		}),
+		WithDialOptions(
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+				got, _ = ctx.Value(baggageKey{}).(map[string]string)
+				return nil
+			}),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	for _, conn := range []string{"1", "0"} {
+		if err := pool.Invoke(context.Background(), "/test.Test/Call", nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if got[BaggagePool] != "billing" || got[BaggageConn] != conn {
+			t.Errorf("call baggage got %v; want %s=billing %s=%s", got, BaggagePool, BaggageConn, conn)
+		}
+	}
+}
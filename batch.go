@@ -0,0 +1,61 @@
+package grpcpool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+)
+
+// DefaultBatchWorkers is the number of concurrent calls of InvokeBatch unless WithBatchWorkers is given.
+const DefaultBatchWorkers = 16
+
+// Call is a unary call of a batch, see Pool.InvokeBatch.
+type Call struct {
+	Method string
+	Args   interface{}
+	Reply  interface{}
+	Opts   []grpc.CallOption
+}
+
+// WithBatchWorkers sets the maximum number of concurrent calls of InvokeBatch.
+func WithBatchWorkers(n int) Option {
+	return func(o *options) {
+		o.batchWorkers = n
+	}
+}
+
+// InvokeBatch makes calls concurrently across the pool, at most WithBatchWorkers at a time, and returns their
+// errors by index, nil for the calls that succeeded. Replies are written to the calls' Reply.
+//
+// Calls are made by a fixed set of workers rather than a goroutine per call, so fan-outs of thousands of calls
+// don't pay for spawning as many goroutines. Calls not started when ctx is done fail with its error.
+func (p *Pool) InvokeBatch(ctx context.Context, calls []Call) []error {
+	errs := make([]error, len(calls))
+	workers := p.opts.batchWorkers
+	if workers <= 0 {
+		workers = DefaultBatchWorkers
+	}
+	if workers > len(calls) {
+		workers = len(calls)
+	}
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := next.Add(1) - 1; i < int64(len(calls)); i = next.Add(1) - 1 {
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				c := &calls[i]
+				errs[i] = p.Invoke(ctx, c.Method, c.Args, c.Reply, c.Opts...)
+			}
+		}()
+	}
+	wg.Wait()
+	return errs
+}
@@ -0,0 +1,88 @@
+package grpcpool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestInvokeBatch(t *testing.T) {
+	var inflight, peak atomic.Int64
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithSize(2),
+		WithBatchWorkers(3),
+		WithDialOptions(
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+				n := inflight.Add(1)
+				defer inflight.Add(-1)
+				for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+				}
+				time.Sleep(time.Millisecond)
+				if method == "/test.Test/Fail" {
+					return status.Error(codes.NotFound, "nope")
+				}
+				*reply.(*int) = *req.(*int) * 2
+				return nil
+			}),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	calls := make([]Call, 10)
+	args, replies := make([]int, len(calls)), make([]int, len(calls))
+	for i := range calls {
+		args[i] = i
+		calls[i] = Call{Method: "/test.Test/Call", Args: &args[i], Reply: &replies[i]}
+	}
+	calls[4].Method = "/test.Test/Fail"
+
+	errs := pool.InvokeBatch(context.Background(), calls)
+	for i, err := range errs {
+		if i == 4 {
+			if status.Code(err) != codes.NotFound {
+				t.Errorf("call #4 got %v; want NotFound", err)
+			}
+			continue
+		}
+		if err != nil || replies[i] != 2*i {
+			t.Errorf("call #%d got %d, %v; want %d", i, replies[i], err, 2*i)
+		}
+	}
+	if got := peak.Load(); got > 3 {
+		t.Errorf("peak concurrent calls got %d; want at most 3 workers", got)
+	}
+	if calls := pool.GroupStats("").Calls; calls != 10 {
+		t.Errorf("pool calls got %d; want 10", calls)
+	}
+}
+
+func TestInvokeBatchCanceled(t *testing.T) {
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithUnaryInterceptor(nopInvoker)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i, err := range pool.InvokeBatch(ctx, make([]Call, 3)) {
+		if err != context.Canceled {
+			t.Errorf("call #%d with a canceled context got %v; want context.Canceled", i, err)
+		}
+	}
+	if errs := pool.InvokeBatch(context.Background(), nil); len(errs) != 0 {
+		t.Errorf("empty batch got %v", errs)
+	}
+}
@@ -0,0 +1,78 @@
+package grpcpool
+
+import (
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+// BulkGroup is the group of the connections reserved for bulk calls, see WithBulkConns.
+const BulkGroup = "bulk"
+
+// WithBulkConns reserves n connections of the pool for bulk calls: calls to methods matching one of methods and
+// calls made with the Bulk CallOption.
+//
+// Large requests and responses then don't cause head-of-line blocking and flow-control stalls on the connections
+// serving interactive traffic. A method pattern is either a full method name, e.g. "/pkg.Service/Export",
+// or a prefix followed by "*", e.g. "/pkg.Service/Export*".
+func WithBulkConns(n int, methods ...string) Option {
+	return func(o *options) {
+		o.bulk = newMethodMatcher(methods)
+		o.addGroup(groupSpec{name: BulkGroup, conns: n})
+	}
+}
+
+// bulkCallOption marks a call as bulk.
+type bulkCallOption struct {
+	grpc.EmptyCallOption
+}
+
+// Bulk returns a CallOption routing the call to the pool's bulk connections, see WithBulkConns.
+//
+// It has no effect on pools without bulk connections.
+func Bulk() grpc.CallOption {
+	return bulkCallOption{}
+}
+
+// isBulk reports whether the call described by info is a bulk call.
+func (o *options) isBulk(info PickInfo) bool {
+	if o.bulk == nil {
+		return false
+	}
+	for _, opt := range info.CallOptions {
+		if _, ok := opt.(bulkCallOption); ok {
+			return true
+		}
+	}
+	return o.bulk.match(info.Method)
+}
+
+// methodMatcher matches full method names against exact names and "prefix*" patterns.
+type methodMatcher struct {
+	exact    map[string]bool
+	prefixes []string
+}
+
+func newMethodMatcher(patterns []string) *methodMatcher {
+	m := &methodMatcher{exact: map[string]bool{}}
+	for _, p := range patterns {
+		if strings.HasSuffix(p, "*") {
+			m.prefixes = append(m.prefixes, strings.TrimSuffix(p, "*"))
+		} else {
+			m.exact[p] = true
+		}
+	}
+	return m
+}
+
+func (m *methodMatcher) match(method string) bool {
+	if m.exact[method] {
+		return true
+	}
+	for _, p := range m.prefixes {
+		if strings.HasPrefix(method, p) {
+			return true
+		}
+	}
+	return false
+}
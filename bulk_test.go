@@ -0,0 +1,63 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestMethodMatcher(t *testing.T) {
+	m := newMethodMatcher([]string{"/pkg.Service/Export*", "/pkg.Service/Dump"})
+	for method, want := range map[string]bool{
+		"/pkg.Service/Export":     true,
+		"/pkg.Service/ExportAll":  true,
+		"/pkg.Service/Dump":       true,
+		"/pkg.Service/DumpAll":    false,
+		"/pkg.Service/Get":        false,
+		"/other.Service/ExportIt": false,
+	} {
+		if got := m.match(method); got != want {
+			t.Errorf("match(%q) got %v; want %v", method, got, want)
+		}
+	}
+}
+
+func TestBulkConns(t *testing.T) {
+	l := healthTestServer(t, "", healthpb.HealthCheckResponse_SERVING)
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(3),
+		WithBulkConns(1, "/grpc.health.v1.Health/Watch"),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	client := healthpb.NewHealthClient(pool)
+	for i := 0; i < 4; i++ {
+		if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}, Bulk()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if _, err := client.Watch(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := pool.GroupStats(BulkGroup); got.Calls != 3 || got.InFlight != 1 {
+		t.Errorf("pool.GroupStats(BulkGroup) got %+v; want 3 calls and 1 in flight", got)
+	}
+	if got := pool.GroupStats(""); got.Calls != 4 || got.InFlight != 0 {
+		t.Errorf(`pool.GroupStats("") got %+v; want 4 calls and none in flight`, got)
+	}
+}
@@ -0,0 +1,81 @@
+package grpcpool
+
+import (
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+// maxMergedArity is the largest number of CallOptions of a unary call that is merged with the default CallOptions
+// into a reused slice. Calls with more options allocate the merged slice.
+const maxMergedArity = 4
+
+// WithCallOptions sets CallOptions applied to every call and stream before the CallOptions of the call, so they
+// also apply to the pool's own options, e.g. CallPriority or Bulk.
+//
+// Unlike grpc.WithDefaultCallOptions, the merged options are precomputed: calls without options use the defaults
+// as they are, and calls with up to four options reuse merged slices with the defaults already in place, so the
+// defaults don't add an allocation to every call.
+func WithCallOptions(opts ...grpc.CallOption) Option {
+	return func(o *options) {
+		o.callOpts = append(o.callOpts, opts...)
+	}
+}
+
+// callDefaults are the default CallOptions of a pool.
+type callDefaults struct {
+	opts    []grpc.CallOption
+	byArity [maxMergedArity]sync.Pool // of *[]grpc.CallOption, opts followed by room for i+1 call options
+}
+
+func newCallDefaults(opts []grpc.CallOption) *callDefaults {
+	if len(opts) == 0 {
+		return nil
+	}
+	d := &callDefaults{opts: opts[:len(opts):len(opts)]}
+	for i := range d.byArity {
+		n := len(opts) + i + 1
+		d.byArity[i].New = func() interface{} {
+			merged := make([]grpc.CallOption, n)
+			copy(merged, opts)
+			return &merged
+		}
+	}
+	return d
+}
+
+// defaults returns the default CallOptions of d, which may be nil.
+func (d *callDefaults) defaults() []grpc.CallOption {
+	if d == nil {
+		return nil
+	}
+	return d.opts
+}
+
+// merge returns the defaults followed by opts for a unary call. If merge returns a non-nil buffer, the merged
+// options are only valid until the buffer is given back with release.
+func (d *callDefaults) merge(opts []grpc.CallOption) ([]grpc.CallOption, *[]grpc.CallOption) {
+	switch {
+	case d == nil:
+		return opts, nil
+	case len(opts) == 0:
+		return d.opts, nil
+	case len(opts) > maxMergedArity:
+		return append(d.opts, opts...), nil
+	}
+	buf := d.byArity[len(opts)-1].Get().(*[]grpc.CallOption)
+	copy((*buf)[len(d.opts):], opts)
+	return *buf, buf
+}
+
+// release gives back a buffer returned by merge.
+func (d *callDefaults) release(buf *[]grpc.CallOption) {
+	if buf == nil {
+		return
+	}
+	merged := *buf
+	for i := len(d.opts); i < len(merged); i++ {
+		merged[i] = nil // don't retain the options of the call
+	}
+	d.byArity[len(merged)-len(d.opts)-1].Put(buf)
+}
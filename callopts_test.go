@@ -0,0 +1,100 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// markOption is a CallOption recording the order options were passed in.
+type markOption struct {
+	grpc.EmptyCallOption
+	name string
+}
+
+func marks(opts []grpc.CallOption) []string {
+	var names []string
+	for _, opt := range opts {
+		if m, ok := opt.(markOption); ok {
+			names = append(names, m.name)
+		}
+	}
+	return names
+}
+
+func TestCallOptions(t *testing.T) {
+	var unary, stream []string
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithSize(2),
+		WithCallOptions(markOption{name: "a"}, markOption{name: "b"}),
+		WithDialOptions(
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithUnaryInterceptor(func(_ context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+				unary = marks(opts)
+				return nil
+			}),
+			grpc.WithStreamInterceptor(func(_ context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, _ grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+				stream = marks(opts)
+				return nil, context.Canceled
+			}),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	for _, n := range []int{0, 1, maxMergedArity, maxMergedArity + 1} {
+		opts := make([]grpc.CallOption, n)
+		want := "ab"
+		for i := range opts {
+			opts[i] = markOption{name: string(rune('c' + i))}
+			want += string(rune('c' + i))
+		}
+		for i := 0; i < 3; i++ { // reused merged slices must not leak options between calls
+			pool.Invoke(context.Background(), "/test.Test/Call", nil, nil, opts...)
+			if got := join(unary); got != want {
+				t.Errorf("Invoke with %d options got %q; want %q", n, got, want)
+			}
+			pool.NewStream(context.Background(), &grpc.StreamDesc{}, "/test.Test/Stream", opts...)
+			if got := join(stream); got != want {
+				t.Errorf("NewStream with %d options got %q; want %q", n, got, want)
+			}
+		}
+	}
+}
+
+func TestCallOptionsAllocs(t *testing.T) {
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithSize(2),
+		WithCallOptions(markOption{name: "a"}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithUnaryInterceptor(nopInvoker)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	one := []grpc.CallOption{markOption{name: "b"}}
+	for _, opts := range [][]grpc.CallOption{nil, one} {
+		if got := testing.AllocsPerRun(100, func() { pool.Invoke(context.Background(), "/test.Test/Call", nil, nil, opts...) }); got != 0 {
+			t.Errorf("Invoke with %d options merging default call options got %v allocs; want 0", len(opts), got)
+		}
+	}
+}
+
+func TestCallOptionsPriority(t *testing.T) {
+	if got := callPriority(newOptions([]Option{WithCallOptions(CallPriority(PriorityHigh))}).callDefaults.defaults()); got != PriorityHigh {
+		t.Errorf("default CallPriority got %v; want PriorityHigh", got)
+	}
+}
+
+func join(names []string) string {
+	s := ""
+	for _, n := range names {
+		s += n
+	}
+	return s
+}
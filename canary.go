@@ -0,0 +1,58 @@
+package grpcpool
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+)
+
+// CanaryGroup is the group of the connections dialed with CanaryConfig.DialOptions.
+const CanaryGroup = "canary"
+
+// CanaryConfig configures canary connections, see WithCanary.
+type CanaryConfig struct {
+	// Conns is the number of connections dialed as canaries. They are reserved at the end of the pool.
+	Conns int
+
+	// TrafficPercent is the percentage of calls routed to the canary connections.
+	TrafficPercent float64
+
+	// DialOptions are appended to the pool's dial options for canary connections,
+	// e.g. to try a new TLS stack, interceptor or compression.
+	DialOptions []grpc.DialOption
+}
+
+type canary struct {
+	CanaryConfig
+
+	weight uint64 // canary share in parts per splitScale
+	idx    uint64 // access via sync/atomic
+}
+
+// WithCanary dials cfg.Conns connections of the pool with additional dial options and routes cfg.TrafficPercent
+// percent of calls to them, to de-risk client-side changes.
+//
+// Canary connections are in CanaryGroup; their calls and errors are tracked separately, see GroupStats.
+func WithCanary(cfg CanaryConfig) Option {
+	return func(o *options) {
+		o.canary = &canary{
+			CanaryConfig: cfg,
+			weight:       uint64(cfg.TrafficPercent*splitScale/100 + 0.5),
+		}
+		o.addGroup(groupSpec{name: CanaryGroup, conns: cfg.Conns, dialOpts: cfg.DialOptions})
+	}
+}
+
+// pick reports whether the next call is routed to the canary connections.
+func (c *canary) pick() bool {
+	i := atomic.AddUint64(&c.idx, 1)
+	return (i*c.weight)/splitScale != ((i-1)*c.weight)/splitScale
+}
+
+func (c *canary) validate() error {
+	if c.TrafficPercent < 0 || c.TrafficPercent > 100 {
+		return errors.New("grpcpool: canary traffic percent must be between 0 and 100")
+	}
+	return nil
+}
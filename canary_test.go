@@ -0,0 +1,78 @@
+package grpcpool
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestCanary(t *testing.T) {
+	var canaryCalls int32
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if ua := md.Get("user-agent"); len(ua) > 0 && strings.HasPrefix(ua[0], "canary") {
+			atomic.AddInt32(&canaryCalls, 1)
+		}
+		return handler(ctx, req)
+	}))
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	go s.Serve(l)
+	defer s.Stop()
+
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(4),
+		WithCanary(CanaryConfig{
+			Conns:          1,
+			TrafficPercent: 25,
+			DialOptions:    []grpc.DialOption{grpc.WithUserAgent("canary")},
+		}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	if got := pool.Conns()[3].Group(); got != CanaryGroup {
+		t.Errorf("last conn group got %q; want %q", got, CanaryGroup)
+	}
+
+	client := healthpb.NewHealthClient(pool)
+	for i := 0; i < 100; i++ {
+		if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := atomic.LoadInt32(&canaryCalls); got != 25 {
+		t.Errorf("server got %d canary calls; want 25", got)
+	}
+	if got := pool.GroupStats(CanaryGroup); got.Conns != 1 || got.Calls != 25 || got.Errors != 0 {
+		t.Errorf("pool.GroupStats(CanaryGroup) got %+v; want 1 conn with 25 calls", got)
+	}
+	if got := pool.GroupStats(""); got.Conns != 3 || got.Calls != 75 {
+		t.Errorf(`pool.GroupStats("") got %+v; want 3 conns with 75 calls`, got)
+	}
+}
+
+func TestCanaryInvalid(t *testing.T) {
+	_, err := NewPool(context.Background(), "localhost:1",
+		WithCanary(CanaryConfig{Conns: 1, TrafficPercent: 10}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err == nil {
+		t.Fatal("NewPool with every conn as canary succeeded")
+	}
+}
@@ -0,0 +1,148 @@
+package grpcpool
+
+import (
+	"context"
+	"crypto/x509"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/stats"
+)
+
+// certExpiry configures certificate expiry monitoring, see WithCertExpiryMonitor.
+type certExpiry struct {
+	margin   time.Duration
+	interval time.Duration
+}
+
+// WithCertExpiryMonitor tracks the expiry of the certificates negotiated on every connection, and re-dials
+// connections whose certificates expire within margin, checking every interval.
+//
+// The expiry of a connection is the earliest expiry of the server certificate of its current transport and,
+// with WithCertificateRotation or WithSPIFFE, of the client certificate presented. A re-dialed connection gets a
+// new handshake, and so the server's current certificate; if it still expires within margin, the connection is
+// not re-dialed again. The old connection is closed once its calls and streams finish. Connections shared through
+// a ConnCache aren't re-dialed. See PoolConn.CertExpiry and Pool.TimeToCertExpiry.
+func WithCertExpiryMonitor(margin, interval time.Duration) Option {
+	return func(o *options) {
+		o.certExpiry = &certExpiry{margin: margin, interval: interval}
+	}
+}
+
+// CertExpiry returns the expiry of the certificates negotiated on c, see WithCertExpiryMonitor. It returns
+// false until c completed a TLS handshake.
+func (c *PoolConn) CertExpiry() (time.Time, bool) {
+	n := c.certExpiry.Load()
+	if n == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, n), true
+}
+
+// TimeToCertExpiry returns the time until the earliest certificate expiry of the pool's connections,
+// see WithCertExpiryMonitor. It returns false if no connection completed a TLS handshake.
+func (p *Pool) TimeToCertExpiry() (time.Duration, bool) {
+	var earliest time.Time
+	for _, c := range p.set.Load().conns {
+		if exp, ok := c.CertExpiry(); ok && (earliest.IsZero() || exp.Before(earliest)) {
+			earliest = exp
+		}
+	}
+	if earliest.IsZero() {
+		return 0, false
+	}
+	return earliest.Sub(p.opts.clock.Now()), true
+}
+
+// certExpiryDialOptions returns the dial options recording the certificate expiry of c, if monitored.
+func (o *options) certExpiryDialOptions(c *PoolConn) []grpc.DialOption {
+	if o.certExpiry == nil {
+		return nil
+	}
+	return []grpc.DialOption{grpc.WithStatsHandler(&certWatcher{conn: c, certs: o.certs})}
+}
+
+// certWatcher is a stats.Handler recording the certificate expiry of the transports of a connection.
+type certWatcher struct {
+	conn  *PoolConn
+	certs *certRotation // the client certificates presented, nil if unknown
+}
+
+func (w *certWatcher) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (w *certWatcher) HandleRPC(context.Context, stats.RPCStats) {}
+
+func (w *certWatcher) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (w *certWatcher) HandleConn(ctx context.Context, s stats.ConnStats) {
+	if _, ok := s.(*stats.ConnBegin); !ok {
+		return
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.PeerCertificates) == 0 {
+		return
+	}
+	expiry := info.State.PeerCertificates[0].NotAfter
+	if w.certs != nil {
+		if cert := w.certs.current.Load(); cert != nil && len(cert.Certificate) > 0 {
+			leaf := cert.Leaf
+			if leaf == nil {
+				leaf, _ = x509.ParseCertificate(cert.Certificate[0])
+			}
+			if leaf != nil && leaf.NotAfter.Before(expiry) {
+				expiry = leaf.NotAfter
+			}
+		}
+	}
+	w.conn.certExpiry.Store(expiry.UnixNano())
+}
+
+// startCertExpiryMonitor starts re-dialing connections with expiring certificates until stop is called.
+func (p *Pool) startCertExpiryMonitor() (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ticker := p.opts.clock.NewTicker(p.opts.certExpiry.interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+				p.checkCertExpiry(ctx)
+			}
+		}
+	}()
+	return cancel
+}
+
+// checkCertExpiry re-dials the connections of p whose certificates expire within the margin.
+func (p *Pool) checkCertExpiry(ctx context.Context) {
+	cfg := p.opts.certExpiry
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	deadline := p.opts.clock.Now().Add(cfg.margin)
+	var expiring []int
+	for i, c := range p.set.Load().conns {
+		exp := c.certExpiry.Load()
+		if exp == 0 || c.cache != nil || !time.Unix(0, exp).Before(deadline) || exp == c.replacedExpiry {
+			continue
+		}
+		expiring = append(expiring, i)
+	}
+	if len(expiring) > 0 {
+		ctx, cancel := context.WithTimeout(ctx, cfg.interval)
+		defer cancel()
+		p.redial(ctx, expiring, true)
+	}
+}
@@ -0,0 +1,98 @@
+package grpcpool
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestCertExpiryMonitor(t *testing.T) {
+	ca := newTestCA(t)
+	l, _ := mtlsServer(t, ca)
+
+	clientCert := ca.issue(t, 1, time.Now().Add(30*time.Minute))
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(2),
+		WithCertificateRotation(&tls.Config{RootCAs: ca.pool, ServerName: "localhost"}, func() (*tls.Certificate, error) {
+			return &clientCert, nil
+		}, time.Hour),
+		WithCertExpiryMonitor(2*time.Hour, time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pool.WaitForReady(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range pool.Conns() {
+		exp, ok := c.CertExpiry()
+		if !ok {
+			t.Fatalf("conn %d has no certificate expiry", i)
+		}
+		if d := time.Until(exp); d > 30*time.Minute || d < 29*time.Minute {
+			t.Errorf("conn %d certificate expires in %v; want the client certificate's 30m", i, d)
+		}
+	}
+	if d, ok := pool.TimeToCertExpiry(); !ok || d > 30*time.Minute || d < 29*time.Minute {
+		t.Errorf("TimeToCertExpiry() got %v, %v; want 30m", d, ok)
+	}
+
+	old := pool.Conns()
+	pool.checkCertExpiry(ctx)
+	redialed := pool.Conns()
+	for i, c := range redialed {
+		if c == old[i] {
+			t.Fatalf("conn %d not re-dialed within the expiry margin", i)
+		}
+		if _, ok := c.CertExpiry(); !ok {
+			t.Fatalf("re-dialed conn %d has no certificate expiry", i)
+		}
+	}
+	for i, c := range old {
+		if state := c.cc.GetState(); state != connectivity.Shutdown {
+			t.Errorf("old conn %d state %v; want SHUTDOWN", i, state)
+		}
+	}
+
+	// The certificates didn't change, so another re-dial wouldn't help.
+	pool.checkCertExpiry(ctx)
+	for i, c := range pool.Conns() {
+		if c != redialed[i] {
+			t.Fatalf("conn %d re-dialed again with an unchanged expiry", i)
+		}
+	}
+}
+
+func TestCertExpiryInsecure(t *testing.T) {
+	_, l := mockServer(t)
+
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithCertExpiryMonitor(time.Hour, time.Hour),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pool.WaitForReady(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := pool.Conns()[0].CertExpiry(); ok {
+		t.Error("insecure conn has a certificate expiry")
+	}
+	if _, ok := pool.TimeToCertExpiry(); ok {
+		t.Error("TimeToCertExpiry() ok for an insecure pool")
+	}
+}
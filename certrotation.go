@@ -0,0 +1,145 @@
+package grpcpool
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// CertificateSource returns the current client certificate, see WithCertificateRotation.
+type CertificateSource func() (*tls.Certificate, error)
+
+// CertificateFiles returns a CertificateSource loading a PEM certificate and key from files, e.g. mounted from a
+// Kubernetes secret or written by a cert-manager sidecar. The files are only reloaded when their modification
+// time changes.
+func CertificateFiles(certFile, keyFile string) CertificateSource {
+	var mu sync.Mutex
+	var cert *tls.Certificate
+	var certMod, keyMod time.Time
+	return func() (*tls.Certificate, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		cm, err := modTime(certFile)
+		if err != nil {
+			return nil, err
+		}
+		km, err := modTime(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		if cert != nil && cm.Equal(certMod) && km.Equal(keyMod) {
+			return cert, nil
+		}
+		c, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("grpcpool: loading client certificate: %w", err)
+		}
+		cert, certMod, keyMod = &c, cm, km
+		return cert, nil
+	}
+}
+
+func modTime(name string) (time.Time, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("grpcpool: loading client certificate: %w", err)
+	}
+	return fi.ModTime(), nil
+}
+
+// certRotation presents the current certificate of a CertificateSource and recycles the pool when it changes.
+type certRotation struct {
+	src      CertificateSource
+	interval time.Duration
+	current  atomic.Pointer[tls.Certificate] // presented in new handshakes
+	rolled   *tls.Certificate                // the connections were dialed with, owned by watchCertificates
+}
+
+// WithCertificateRotation dials with TLS using cfg, presenting the client certificate from src, and rolls the
+// pool's connections when the certificate changes.
+//
+// src is checked every interval. Once it returns a new certificate, new handshakes present it and the pool is
+// recycled, see Recycle, so long-lived connections don't outlive the certificate they were established with.
+// Connections still draining after interval are closed. NewPool fails if src fails initially; later failures
+// keep the current certificate. cfg.Certificates and cfg.GetClientCertificate are ignored.
+func WithCertificateRotation(cfg *tls.Config, src CertificateSource, interval time.Duration) Option {
+	return func(o *options) {
+		r := &certRotation{src: src, interval: interval}
+		cfg = cfg.Clone()
+		cfg.Certificates = nil
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.current.Load(), nil
+		}
+		o.certs = r
+		o.dialOpts = append(o.dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+	}
+}
+
+// load loads the initial certificate.
+func (r *certRotation) load() error {
+	cert, err := r.src()
+	if err != nil {
+		return err
+	}
+	if cert == nil {
+		return errors.New("grpcpool: certificate source returned no certificate")
+	}
+	r.current.Store(cert)
+	r.rolled = cert
+	return nil
+}
+
+// startCertificateRotation starts watching the certificate source of p until stop is called.
+func (p *Pool) startCertificateRotation() (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ticker := p.opts.clock.NewTicker(p.opts.certs.interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+				p.checkCertificate(ctx)
+			}
+		}
+	}()
+	return cancel
+}
+
+// checkCertificate recycles p if its certificate source returns a certificate other than the one its
+// connections were dialed with.
+func (p *Pool) checkCertificate(ctx context.Context) {
+	r := p.opts.certs
+	cert, err := r.src()
+	if err != nil || cert == nil {
+		return
+	}
+	r.current.Store(cert)
+	if sameCertificate(cert, r.rolled) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.interval)
+	defer cancel()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if swapped, _ := p.replace(ctx, p.set.Load().endpoints()); swapped {
+		r.rolled = cert // otherwise retried on the next check
+	}
+}
+
+func sameCertificate(a, b *tls.Certificate) bool {
+	if len(a.Certificate) == 0 || len(b.Certificate) == 0 {
+		return a == b
+	}
+	return bytes.Equal(a.Certificate[0], b.Certificate[0])
+}
@@ -0,0 +1,202 @@
+package grpcpool
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// testCA is a certificate authority issuing test certificates.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+// issue returns a certificate with serial for localhost and the URI SANs uris.
+func (ca *testCA) issue(t *testing.T, serial int64, notAfter time.Time, uris ...string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	for _, uri := range uris {
+		u, err := url.Parse(uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl.URIs = append(tmpl.URIs, u)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// writePEM writes cert and its key to files in dir.
+func writePEM(t *testing.T, dir string, cert tls.Certificate) (certFile, keyFile string) {
+	t.Helper()
+	keyDER, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+// testServerID is the SPIFFE ID of the server started by mtlsServer.
+const testServerID = "spiffe://example.org/server"
+
+// mtlsServer starts a server requiring client certificates issued by ca, recording the serials it sees.
+func mtlsServer(t *testing.T, ca *testCA) (net.Listener, func() map[int64]bool) {
+	t.Helper()
+	var mu sync.Mutex
+	serials := map[int64]bool{}
+	creds := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{ca.issue(t, 100, time.Now().Add(time.Hour), testServerID)},
+		ClientCAs:    ca.pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})
+	s := grpc.NewServer(grpc.Creds(creds), grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+		p, _ := peer.FromContext(stream.Context())
+		info := p.AuthInfo.(credentials.TLSInfo)
+		mu.Lock()
+		serials[info.State.PeerCertificates[0].SerialNumber.Int64()] = true
+		mu.Unlock()
+		return nil
+	}))
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+	return l, func() map[int64]bool {
+		mu.Lock()
+		defer mu.Unlock()
+		seen := serials
+		serials = map[int64]bool{}
+		return seen
+	}
+}
+
+func TestCertificateRotation(t *testing.T) {
+	ca := newTestCA(t)
+	l, seen := mtlsServer(t, ca)
+
+	dir := t.TempDir()
+	writePEM(t, dir, ca.issue(t, 1, time.Now().Add(time.Hour)))
+	src := CertificateFiles(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"))
+
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(2),
+		WithCertificateRotation(&tls.Config{RootCAs: ca.pool, ServerName: "localhost"}, src, time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pool.WarmStreams(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := seen(); len(got) != 1 || !got[1] {
+		t.Fatalf("server saw client certificates %v; want serial 1", got)
+	}
+
+	old := pool.Conns()
+	pool.checkCertificate(ctx)
+	if pool.Conns()[0] != old[0] {
+		t.Fatal("pool recycled without a new certificate")
+	}
+
+	certFile, _ := writePEM(t, dir, ca.issue(t, 2, time.Now().Add(time.Hour)))
+	future := time.Now().Add(time.Minute) // the mtime may not change within the file system's resolution
+	os.Chtimes(certFile, future, future)
+	pool.checkCertificate(ctx)
+	for i, c := range pool.Conns() {
+		if c == old[i] {
+			t.Fatalf("conn %d not recycled after the certificate rotated", i)
+		}
+	}
+	if err := pool.WarmStreams(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := seen(); len(got) != 1 || !got[2] {
+		t.Fatalf("server saw client certificates %v after rotation; want serial 2", got)
+	}
+}
+
+func TestCertificateRotationLoadError(t *testing.T) {
+	dir := t.TempDir()
+	_, err := NewPool(context.Background(), "localhost:1",
+		WithCertificateRotation(&tls.Config{}, CertificateFiles(filepath.Join(dir, "missing.crt"), filepath.Join(dir, "missing.key")), time.Hour),
+	)
+	if err == nil {
+		t.Fatal("NewPool() with missing certificate files got no error")
+	}
+}
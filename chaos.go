@@ -0,0 +1,64 @@
+package grpcpool
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ChaosConfig configures the faults injected by WithChaos.
+type ChaosConfig struct {
+	// ErrorRate is the fraction, from 0 to 1, of calls and streams failed without reaching the backend.
+	ErrorRate float64
+
+	// ErrorCode is the code of injected failures. The default is codes.Unavailable.
+	ErrorCode codes.Code
+
+	// LatencyJitter is the maximum latency added to calls and stream creation, uniformly distributed.
+	LatencyJitter time.Duration
+
+	// PerConnOverrides replaces the faults of the connections with the given indexes, e.g. to make a single
+	// backend misbehave. Their own PerConnOverrides are ignored.
+	PerConnOverrides map[int]ChaosConfig
+}
+
+// WithChaos injects artificial failures and latency into calls and streams, to test the retry, failover and
+// outlier detection behavior of applications against the real pool. Faults are injected after a connection is
+// picked, so they count as the connection's errors.
+//
+// It must not be used in production.
+func WithChaos(cfg ChaosConfig) Option {
+	return func(o *options) {
+		o.chaos = &cfg
+	}
+}
+
+// inject injects the faults configured for c before a call or stream on it.
+func (cfg *ChaosConfig) inject(ctx context.Context, c *PoolConn, clock Clock) error {
+	if cfg == nil {
+		return nil
+	}
+	if override, ok := cfg.PerConnOverrides[c.index]; ok {
+		cfg = &override
+	}
+	if cfg.LatencyJitter > 0 {
+		t := clock.NewTimer(time.Duration(rand.Int63n(int64(cfg.LatencyJitter))))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return status.FromContextError(ctx.Err()).Err()
+		case <-t.C():
+		}
+	}
+	if cfg.ErrorRate > 0 && rand.Float64() < cfg.ErrorRate {
+		code := cfg.ErrorCode
+		if code == codes.OK {
+			code = codes.Unavailable
+		}
+		return status.Errorf(code, "grpcpool: chaos fault injected on conn %d", c.index)
+	}
+	return nil
+}
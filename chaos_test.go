@@ -0,0 +1,63 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestChaos(t *testing.T) {
+	_, l := mockServer(t)
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(2),
+		WithChaos(ChaosConfig{
+			PerConnOverrides: map[int]ChaosConfig{1: {ErrorRate: 1, ErrorCode: codes.ResourceExhausted}},
+		}),
+		WithDialOptions(
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithUnaryInterceptor(func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, grpc.UnaryInvoker, ...grpc.CallOption) error {
+				return nil
+			}),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	// Round-robin alternates between the faulty conn #1 and the healthy conn #0.
+	for i := 0; i < 4; i++ {
+		err := pool.Invoke(context.Background(), "/test.Test/Call", nil, nil)
+		if want := [2]codes.Code{codes.ResourceExhausted, codes.OK}[i%2]; status.Code(err) != want {
+			t.Errorf("call #%d got %v; want %v", i, err, want)
+		}
+	}
+	if got := pool.Conns()[1].errors.Load(); got != 2 {
+		t.Errorf("faulty conn errors got %d; want 2", got)
+	}
+	if _, err := pool.NewStream(context.Background(), &grpc.StreamDesc{}, "/test.Test/Stream"); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("stream on the faulty conn got %v; want ResourceExhausted", err)
+	}
+}
+
+func TestChaosLatency(t *testing.T) {
+	cfg := &ChaosConfig{LatencyJitter: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := cfg.inject(ctx, &PoolConn{}, SystemClock()); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("inject with an hour of jitter got %v; want DeadlineExceeded", err)
+	}
+
+	cfg = &ChaosConfig{ErrorRate: 1}
+	if err := cfg.inject(context.Background(), &PoolConn{}, SystemClock()); status.Code(err) != codes.Unavailable {
+		t.Errorf("inject with ErrorRate 1 got %v; want Unavailable", err)
+	}
+	if err := (*ChaosConfig)(nil).inject(context.Background(), &PoolConn{}, SystemClock()); err != nil {
+		t.Errorf("inject without chaos got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package grpcpool
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// manualClock is a Clock whose Now only moves with advance.
+type manualClock struct {
+	systemClock
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// timerClock is a Clock whose timers fire when the test sends on the channels it receives from timers.
+type timerClock struct {
+	systemClock
+	timers chan chan time.Time
+}
+
+func (c *timerClock) NewTimer(time.Duration) Timer {
+	ch := make(chan time.Time, 1)
+	c.timers <- ch
+	return chanTimer(ch)
+}
+
+type chanTimer chan time.Time
+
+func (t chanTimer) C() <-chan time.Time { return t }
+func (t chanTimer) Stop() bool          { return true }
+
+// tickerClock is a manualClock whose tickers tick when the test sends on ticks.
+type tickerClock struct {
+	manualClock
+	ticks chan time.Time
+}
+
+func (c *tickerClock) NewTicker(time.Duration) Ticker {
+	return chanTicker(c.ticks)
+}
+
+type chanTicker chan time.Time
+
+func (t chanTicker) C() <-chan time.Time { return t }
+func (t chanTicker) Stop()               {}
+
+func TestClockTenantIdleTimeout(t *testing.T) {
+	_, l := mockServer(t)
+	clock := &manualClock{now: time.Unix(0, 0)}
+	pool, err := NewTenantPool(l.Addr().String(), TenantConfig{
+		Tenant:      func(ctx context.Context) (string, bool) { return ctx.Value(tenantKey{}).(string), true },
+		IdleTimeout: time.Minute,
+	}, WithClock(clock), WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	call := func(tenant string) {
+		ctx := context.WithValue(context.Background(), tenantKey{}, tenant)
+		pool.Invoke(ctx, "/test.Test/Call", nil, nil)
+	}
+	call("a")
+	clock.advance(59 * time.Second)
+	call("b")
+	if got := pool.Tenants(); len(got) != 2 {
+		t.Fatalf("pool.Tenants() before the idle timeout got %v; want [b a]", got)
+	}
+	clock.advance(time.Second)
+	call("b")
+	if got := pool.Tenants(); len(got) != 1 || got[0] != "b" {
+		t.Errorf("pool.Tenants() after the idle timeout of a got %v; want [b]", got)
+	}
+}
@@ -0,0 +1,27 @@
+package grpcpool
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// WithCompression compresses the requests of every call and stream of the pool with the compressor registered
+// under name, e.g. "gzip" once google.golang.org/grpc/encoding/gzip is imported. It is a default CallOption, see
+// WithCallOptions, so a grpc.UseCompressor given to a call overrides it, and grpc.UseCompressor("identity")
+// disables compression for that call. NewPool fails if no compressor is registered under name.
+func WithCompression(name string) Option {
+	return func(o *options) {
+		o.compression = name
+		o.callOpts = append(o.callOpts, grpc.UseCompressor(name))
+	}
+}
+
+// checkCompression returns an error if the compressor of the pool isn't registered.
+func (o *options) checkCompression() error {
+	if o.compression == "" || encoding.GetCompressor(o.compression) != nil {
+		return nil
+	}
+	return fmt.Errorf("grpcpool: compressor %q not registered", o.compression)
+}
@@ -0,0 +1,233 @@
+package grpcpool
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+)
+
+// Config is a go-coldbrew style pool configuration.
+//
+// Its envconfig tags follow the go-coldbrew conventions, so it can be embedded in a service config and loaded from
+// the environment, e.g. with github.com/kelseyhightower/envconfig. Its json and yaml tags let it live in config
+// files, see LoadConfig; durations are written as strings, e.g. "5s".
+type Config struct {
+	// Target is the target the pool dials.
+	Target string `envconfig:"TARGET" json:"target,omitempty" yaml:"target,omitempty"`
+
+	// Targets are the endpoints the pool dials instead of Target, see NewEndpointPool.
+	Targets []string `envconfig:"TARGETS" json:"targets,omitempty" yaml:"targets,omitempty"`
+
+	// Size is the number of connections in the pool.
+	Size uint `envconfig:"SIZE" default:"4" json:"size,omitempty" yaml:"size,omitempty"`
+
+	// DialTimeout is how long NewFromConfig waits for every connection to become ready. Zero doesn't wait.
+	DialTimeout time.Duration `envconfig:"DIAL_TIMEOUT" default:"5s" json:"dial_timeout,omitempty" yaml:"dial_timeout,omitempty"`
+
+	// CallTimeout is the deadline of unary calls made without one. Zero means no deadline.
+	CallTimeout time.Duration `envconfig:"CALL_TIMEOUT" json:"call_timeout,omitempty" yaml:"call_timeout,omitempty"`
+
+	// KeepaliveTime is the interval of keepalive pings on idle connections. Zero uses DefaultKeepalive.
+	KeepaliveTime time.Duration `envconfig:"KEEPALIVE_TIME" json:"keepalive_time,omitempty" yaml:"keepalive_time,omitempty"`
+
+	// KeepaliveTimeout is how long to wait for a keepalive ping ack before closing the connection.
+	KeepaliveTimeout time.Duration `envconfig:"KEEPALIVE_TIMEOUT" default:"20s" json:"keepalive_timeout,omitempty" yaml:"keepalive_timeout,omitempty"`
+
+	// KeepalivePermitWithoutStream sends keepalive pings even without active streams.
+	KeepalivePermitWithoutStream bool `envconfig:"KEEPALIVE_PERMIT_WITHOUT_STREAM" json:"keepalive_permit_without_stream,omitempty" yaml:"keepalive_permit_without_stream,omitempty"`
+
+	// Picker is the name of the picker, see PickerByName.
+	Picker string `envconfig:"PICKER" default:"round_robin" json:"picker,omitempty" yaml:"picker,omitempty"`
+
+	// TLS dials with TLS instead of plaintext.
+	TLS bool `envconfig:"TLS" json:"tls,omitempty" yaml:"tls,omitempty"`
+
+	// TLSServerName overrides the server name used to verify the server certificate.
+	TLSServerName string `envconfig:"TLS_SERVER_NAME" json:"tls_server_name,omitempty" yaml:"tls_server_name,omitempty"`
+
+	// TLSCAFile is a PEM file with the roots used to verify the server certificate. Empty uses the system roots.
+	TLSCAFile string `envconfig:"TLS_CA_FILE" json:"tls_ca_file,omitempty" yaml:"tls_ca_file,omitempty"`
+
+	// TLSInsecureSkipVerify disables verification of the server certificate.
+	TLSInsecureSkipVerify bool `envconfig:"TLS_INSECURE_SKIP_VERIFY" json:"tls_insecure_skip_verify,omitempty" yaml:"tls_insecure_skip_verify,omitempty"`
+
+	// ConcurrencyLimit limits the in-flight calls and open streams of the pool, with up to ConcurrencyQueue calls
+	// waiting for a slot, see WithConcurrencyLimit. Zero means no limit.
+	ConcurrencyLimit int `envconfig:"CONCURRENCY_LIMIT" json:"concurrency_limit,omitempty" yaml:"concurrency_limit,omitempty"`
+	ConcurrencyQueue int `envconfig:"CONCURRENCY_QUEUE" json:"concurrency_queue,omitempty" yaml:"concurrency_queue,omitempty"`
+
+	// MaxSendMsgSize and MaxRecvMsgSize are the largest messages sent and received, see WithMaxMessageSize. Zero
+	// keeps the gRPC defaults.
+	MaxSendMsgSize int `envconfig:"MAX_SEND_MSG_SIZE" json:"max_send_msg_size,omitempty" yaml:"max_send_msg_size,omitempty"`
+	MaxRecvMsgSize int `envconfig:"MAX_RECV_MSG_SIZE" json:"max_recv_msg_size,omitempty" yaml:"max_recv_msg_size,omitempty"`
+
+	// Compression is the name of the compressor of requests, see WithCompression. Empty doesn't compress.
+	Compression string `envconfig:"COMPRESSION" json:"compression,omitempty" yaml:"compression,omitempty"`
+
+	// WaitForReady makes calls wait for their connection to be ready instead of failing fast, see WithWaitForReady.
+	WaitForReady bool `envconfig:"WAIT_FOR_READY" json:"wait_for_ready,omitempty" yaml:"wait_for_ready,omitempty"`
+
+	// ServiceConfig is the gRPC service config JSON of the connections, see WithServiceConfig. Empty uses none.
+	ServiceConfig string `envconfig:"SERVICE_CONFIG" json:"service_config,omitempty" yaml:"service_config,omitempty"`
+}
+
+// Options returns the pool Options for cfg. Options for the targets and the dial timeout aren't included.
+func (cfg Config) Options() ([]Option, error) {
+	opts := []Option{WithSize(cfg.Size)}
+
+	creds := insecure.NewCredentials()
+	if cfg.TLS {
+		tlsConfig := &tls.Config{
+			ServerName:         cfg.TLSServerName,
+			InsecureSkipVerify: cfg.TLSInsecureSkipVerify,
+		}
+		if cfg.TLSCAFile != "" {
+			pem, err := os.ReadFile(cfg.TLSCAFile)
+			if err != nil {
+				return nil, fmt.Errorf("grpcpool: reading TLS CA file: %w", err)
+			}
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("grpcpool: no certificates in TLS CA file %s", cfg.TLSCAFile)
+			}
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	opts = append(opts, WithDialOptions(grpc.WithTransportCredentials(creds)))
+
+	if cfg.KeepaliveTime > 0 {
+		opts = append(opts, WithKeepalive(keepalive.ClientParameters{
+			Time:                cfg.KeepaliveTime,
+			Timeout:             cfg.KeepaliveTimeout,
+			PermitWithoutStream: cfg.KeepalivePermitWithoutStream,
+		}))
+	}
+	if cfg.CallTimeout > 0 {
+		opts = append(opts, WithCallTimeout(cfg.CallTimeout))
+	}
+	if cfg.Picker != "" {
+		picker, err := PickerByName(cfg.Picker)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithPicker(picker))
+	}
+	if cfg.ConcurrencyLimit > 0 {
+		opts = append(opts, WithConcurrencyLimit(cfg.ConcurrencyLimit, cfg.ConcurrencyQueue))
+	}
+	if cfg.MaxSendMsgSize > 0 || cfg.MaxRecvMsgSize > 0 {
+		opts = append(opts, WithMaxMessageSize(cfg.MaxSendMsgSize, cfg.MaxRecvMsgSize))
+	}
+	if cfg.Compression != "" {
+		opts = append(opts, WithCompression(cfg.Compression))
+	}
+	if cfg.WaitForReady {
+		opts = append(opts, WithWaitForReady(true))
+	}
+	if cfg.ServiceConfig != "" {
+		opts = append(opts, WithServiceConfig(cfg.ServiceConfig))
+	}
+	return opts, nil
+}
+
+// NewFromConfig creates a new Pool from cfg. opts are applied after the Options derived from cfg.
+//
+// If cfg.DialTimeout is set, it waits up to that long for every connection to become ready.
+func NewFromConfig(ctx context.Context, cfg Config, opts ...Option) (*Pool, error) {
+	endpoints := cfg.endpoints()
+	if len(endpoints) == 0 {
+		return nil, errors.New("grpcpool: config has no target")
+	}
+	cfgOpts, err := cfg.Options()
+	if err != nil {
+		return nil, err
+	}
+	p, err := NewEndpointPool(ctx, endpoints, append(cfgOpts, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.DialTimeout > 0 {
+		ctx, cancel := context.WithTimeout(ctx, cfg.DialTimeout)
+		defer cancel()
+		if err := p.WaitForReady(ctx); err != nil {
+			p.Close()
+			return nil, fmt.Errorf("grpcpool: waiting for %s: %w", cfg.targetName(), err)
+		}
+	}
+	return p, nil
+}
+
+// endpoints returns the endpoints of cfg, Targets or else Target.
+func (cfg Config) endpoints() []Endpoint {
+	targets := cfg.Targets
+	if len(targets) == 0 && cfg.Target != "" {
+		targets = []string{cfg.Target}
+	}
+	endpoints := make([]Endpoint, len(targets))
+	for i, t := range targets {
+		endpoints[i] = Endpoint{Addr: t}
+	}
+	return endpoints
+}
+
+// targetName returns the targets of cfg for errors.
+func (cfg Config) targetName() string {
+	if len(cfg.Targets) > 0 {
+		return strings.Join(cfg.Targets, ",")
+	}
+	return cfg.Target
+}
+
+// WaitForReady blocks until every connection in the pool is ready or ctx is done.
+func (p *Pool) WaitForReady(ctx context.Context) error {
+	return p.set.Load().waitReady(ctx)
+}
+
+// WithCallTimeout sets the deadline of unary calls made without one.
+func WithCallTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.callTimeout = d
+	}
+}
+
+// WithMethodTimeouts caps unary calls to the methods in timeouts, keyed by full method name, e.g.
+// "/billing.v1.Billing/Charge", with their own deadline: calls made without a deadline or with a later one get
+// the deadline of their method. Calls to other methods use the call timeout, see WithCallTimeout.
+func WithMethodTimeouts(timeouts map[string]time.Duration) Option {
+	return func(o *options) {
+		if o.methodTimeouts == nil {
+			o.methodTimeouts = make(map[string]time.Duration, len(timeouts))
+		}
+		for method, d := range timeouts {
+			o.methodTimeouts[method] = d
+		}
+	}
+}
+
+// callContext returns ctx with the timeout of method applied if ctx has no deadline or a later one, or else the
+// pool's call timeout applied if ctx has no deadline.
+func (o *options) callContext(ctx context.Context, method string) (context.Context, context.CancelFunc) {
+	if d, ok := o.methodTimeouts[method]; ok && d > 0 {
+		if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > d {
+			return context.WithTimeout(ctx, d)
+		}
+		return ctx, nil
+	}
+	if o.callTimeout <= 0 {
+		return ctx, nil
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, nil
+	}
+	return context.WithTimeout(ctx, o.callTimeout)
+}
@@ -0,0 +1,104 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/connectivity"
+)
+
+func TestNewFromConfig(t *testing.T) {
+	_, l := mockServer(t)
+	pool, err := NewFromConfig(context.Background(), Config{
+		Target:        l.Addr().String(),
+		Size:          2,
+		DialTimeout:   5 * time.Second,
+		KeepaliveTime: time.Minute,
+		Picker:        "round_robin",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	if pool.Num() != 2 {
+		t.Errorf("pool.Num() got %d; want 2", pool.Num())
+	}
+	for i, c := range pool.Conns() {
+		if state := c.State(); state != connectivity.Ready {
+			t.Errorf("conn #%d state got %v; want READY", i, state)
+		}
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	for name, cfg := range map[string]Config{
+		"no target":      {},
+		"unknown picker": {Target: "localhost:1", Picker: "nope"},
+		"missing CA":     {Target: "localhost:1", TLS: true, TLSCAFile: "testdata/missing.pem"},
+	} {
+		if pool, err := NewFromConfig(context.Background(), cfg); err == nil {
+			pool.Close()
+			t.Errorf("%s: NewFromConfig succeeded", name)
+		}
+	}
+}
+
+func TestCallTimeout(t *testing.T) {
+	o := newOptions([]Option{WithCallTimeout(time.Second)})
+
+	ctx, cancel := o.callContext(context.Background(), "/test.Test/Call")
+	if cancel == nil {
+		t.Fatal("callContext applied no timeout to a context without deadline")
+	}
+	defer cancel()
+	if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > time.Second {
+		t.Errorf("callContext deadline got %v; want within a second", deadline)
+	}
+
+	parent, cancelParent := context.WithTimeout(context.Background(), time.Hour)
+	defer cancelParent()
+	if _, cancel := o.callContext(parent, "/test.Test/Call"); cancel != nil {
+		t.Error("callContext overrode an existing deadline")
+	}
+}
+
+func TestMethodTimeouts(t *testing.T) {
+	o := newOptions([]Option{
+		WithCallTimeout(time.Hour),
+		WithMethodTimeouts(map[string]time.Duration{"/test.Test/Fast": time.Second}),
+	})
+	deadline := func(parent time.Duration, method string) time.Duration {
+		ctx := context.Background()
+		if parent > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, parent)
+			defer cancel()
+		}
+		ctx, cancel := o.callContext(ctx, method)
+		if cancel != nil {
+			defer cancel()
+		}
+		d, ok := ctx.Deadline()
+		if !ok {
+			return 0
+		}
+		return time.Until(d).Round(time.Second)
+	}
+	for _, tc := range []struct {
+		parent time.Duration
+		method string
+		want   time.Duration
+	}{
+		{0, "/test.Test/Fast", time.Second},
+		{time.Minute, "/test.Test/Fast", time.Second},
+		{100 * time.Millisecond, "/test.Test/Fast", 0},
+		{0, "/test.Test/Slow", time.Hour},
+		{time.Minute, "/test.Test/Slow", time.Minute},
+	} {
+		if got := deadline(tc.parent, tc.method); got != tc.want {
+			t.Errorf("deadline of %s with parent timeout %v got %v; want %v", tc.method, tc.parent, got, tc.want)
+		}
+	}
+}
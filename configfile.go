@@ -0,0 +1,142 @@
+package grpcpool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfig returns the Config with the defaults of its envconfig tags, which LoadConfig fills in for the
+// fields missing from config files.
+func DefaultConfig() Config {
+	return Config{
+		Size:             4,
+		DialTimeout:      5 * time.Second,
+		KeepaliveTimeout: 20 * time.Second,
+		Picker:           "round_robin",
+	}
+}
+
+// LoadConfig reads a Config from the JSON or YAML file at path, by its extension: .json for JSON, .yaml or .yml
+// for YAML. Fields missing from the file keep the values of DefaultConfig, and unknown fields are errors, so typos
+// don't go unnoticed.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("grpcpool: reading config: %w", err)
+	}
+	var cfg Config
+	switch ext := filepath.Ext(path); ext {
+	case ".json":
+		cfg, err = ParseConfigJSON(data)
+	case ".yaml", ".yml":
+		cfg, err = ParseConfigYAML(data)
+	default:
+		return Config{}, fmt.Errorf("grpcpool: config file %s is neither JSON nor YAML", path)
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("grpcpool: config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ParseConfigJSON parses a Config from JSON, see LoadConfig.
+func ParseConfigJSON(data []byte) (Config, error) {
+	cfg := DefaultConfig()
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg.jsonForm()); err != nil { // not Config.UnmarshalJSON, which allows unknown fields
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// ParseConfigYAML parses a Config from YAML, see LoadConfig.
+func ParseConfigYAML(data []byte) (Config, error) {
+	cfg := DefaultConfig()
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// NewFromConfigFile creates a new Pool from the config file at path, see LoadConfig and NewFromConfig.
+func NewFromConfigFile(ctx context.Context, path string, opts ...Option) (*Pool, error) {
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewFromConfig(ctx, cfg, opts...)
+}
+
+// configJSON is the JSON form of a Config, whose durations, written as strings, shadow those of the embedded
+// Config fields.
+type configJSON struct {
+	*configFields
+	DialTimeout      *jsonDuration `json:"dial_timeout,omitempty"`
+	CallTimeout      *jsonDuration `json:"call_timeout,omitempty"`
+	KeepaliveTime    *jsonDuration `json:"keepalive_time,omitempty"`
+	KeepaliveTimeout *jsonDuration `json:"keepalive_timeout,omitempty"`
+}
+
+type configFields Config // without the JSON methods of Config
+
+// jsonForm returns the JSON form of cfg, reading into and writing from cfg.
+func (cfg *Config) jsonForm() *configJSON {
+	return &configJSON{
+		configFields:     (*configFields)(cfg),
+		DialTimeout:      (*jsonDuration)(&cfg.DialTimeout),
+		CallTimeout:      (*jsonDuration)(&cfg.CallTimeout),
+		KeepaliveTime:    (*jsonDuration)(&cfg.KeepaliveTime),
+		KeepaliveTimeout: (*jsonDuration)(&cfg.KeepaliveTimeout),
+	}
+}
+
+// MarshalJSON writes the durations of cfg as strings, e.g. "5s".
+func (cfg Config) MarshalJSON() ([]byte, error) {
+	j := cfg.jsonForm()
+	// Zero durations are omitted like the other fields.
+	for _, d := range []**jsonDuration{&j.DialTimeout, &j.CallTimeout, &j.KeepaliveTime, &j.KeepaliveTimeout} {
+		if **d == 0 {
+			*d = nil
+		}
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON reads durations as strings, e.g. "5s", or as numbers of nanoseconds.
+func (cfg *Config) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, cfg.jsonForm())
+}
+
+type jsonDuration time.Duration
+
+func (d jsonDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *jsonDuration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var n int64
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("duration %s is neither a string nor a number", data)
+		}
+		*d = jsonDuration(n)
+		return nil
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = jsonDuration(v)
+	return nil
+}
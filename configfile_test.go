@@ -0,0 +1,101 @@
+package grpcpool
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	want := DefaultConfig()
+	want.Targets = []string{"a:443", "b:443"}
+	want.Size = 8
+	want.CallTimeout = 2 * time.Second
+	want.TLS = true
+	want.ConcurrencyLimit = 100
+	want.MaxRecvMsgSize = 16 << 20
+
+	for name, data := range map[string]string{
+		"pool.json": `{"targets": ["a:443", "b:443"], "size": 8, "call_timeout": "2s", "tls": true,
+			"concurrency_limit": 100, "max_recv_msg_size": 16777216}`,
+		"pool.yaml": "targets: [a:443, b:443]\nsize: 8\ncall_timeout: 2s\ntls: true\nconcurrency_limit: 100\nmax_recv_msg_size: 16777216\n",
+		"pool.yml":  "targets:\n  - a:443\n  - b:443\nsize: 8\ncall_timeout: 2s\ntls: true\nconcurrency_limit: 100\nmax_recv_msg_size: 16777216\n",
+	} {
+		got, err := LoadConfig(writeConfig(t, name, data))
+		if err != nil {
+			t.Errorf("LoadConfig(%s) got %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("LoadConfig(%s) got %+v; want %+v", name, got, want)
+		}
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	for name, data := range map[string]string{
+		"unknown.json":  `{"sise": 8}`,
+		"unknown.yaml":  "sise: 8\n",
+		"duration.json": `{"call_timeout": "2 seconds"}`,
+		"pool.toml":     "size = 8\n",
+	} {
+		if _, err := LoadConfig(writeConfig(t, name, data)); err == nil {
+			t.Errorf("LoadConfig(%s) succeeded", name)
+		}
+	}
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadConfig of a missing file succeeded")
+	}
+}
+
+func TestConfigJSON(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Target = "localhost:8080"
+	cfg.KeepaliveTime = time.Minute
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"target":"localhost:8080","size":4,"picker":"round_robin","dial_timeout":"5s","keepalive_time":"1m0s","keepalive_timeout":"20s"}`
+	if string(data) != want {
+		t.Errorf("json.Marshal(Config) got %s; want %s", data, want)
+	}
+
+	var got Config
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, cfg) {
+		t.Errorf("json.Unmarshal of the marshaled Config got %+v; want %+v", got, cfg)
+	}
+	if err := json.Unmarshal([]byte(`{"dial_timeout": 1000000000}`), &got); err != nil || got.DialTimeout != time.Second {
+		t.Errorf("json.Unmarshal of a duration in nanoseconds got %v, %v; want 1s", got.DialTimeout, err)
+	}
+}
+
+func TestNewFromConfigFile(t *testing.T) {
+	_, l1 := mockServer(t)
+	_, l2 := mockServer(t)
+	path := writeConfig(t, "pool.yaml", "targets: ["+l1.Addr().String()+", "+l2.Addr().String()+"]\nsize: 2\n")
+	pool, err := NewFromConfigFile(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	if a, b := pool.Conns()[0].Endpoint().Addr, pool.Conns()[1].Endpoint().Addr; a != l1.Addr().String() || b != l2.Addr().String() {
+		t.Errorf("pool dialed %s and %s; want both targets", a, b)
+	}
+}
@@ -0,0 +1,112 @@
+package grpcpool
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+// ConnCache shares grpc.ClientConns between pools dialing the same target with the same options.
+//
+// Shared connections are reference counted and only closed when every pool using them is closed.
+type ConnCache struct {
+	mu    sync.Mutex
+	conns map[connCacheKey]*cachedConn
+}
+
+type connCacheKey struct {
+	key       string // identifies the dial options, see WithConnCache
+	addr      string
+	authority string
+	group     string
+	n         int // position among the pool's conns to addr in group
+}
+
+type cachedConn struct {
+	cc   *grpc.ClientConn
+	refs int
+}
+
+// DefaultConnCache is the process-wide ConnCache used by WithSharedConns.
+var DefaultConnCache = NewConnCache()
+
+// NewConnCache creates an empty ConnCache.
+func NewConnCache() *ConnCache {
+	return &ConnCache{conns: map[connCacheKey]*cachedConn{}}
+}
+
+// WithConnCache makes the pool obtain its connections from cache.
+//
+// Pools with the same key share the connections they dial to the same endpoint, so key must identify the dial
+// options: pools passing the same key with different dial options get whichever connections were dialed first.
+// This prevents connection explosion in services that construct a pool per subsystem.
+func WithConnCache(cache *ConnCache, key string) Option {
+	return func(o *options) {
+		o.connCache = cache
+		o.connCacheKey = key
+	}
+}
+
+// WithSharedConns is WithConnCache(DefaultConnCache, key).
+func WithSharedConns(key string) Option {
+	return WithConnCache(DefaultConnCache, key)
+}
+
+// Len returns the number of connections in the cache.
+func (c *ConnCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.conns)
+}
+
+func (c *ConnCache) acquire(ctx context.Context, key connCacheKey, opts []grpc.DialOption) (*grpc.ClientConn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.conns[key]; ok {
+		cached.refs++
+		return cached.cc, nil
+	}
+	cc, err := grpc.DialContext(ctx, key.addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.conns[key] = &cachedConn{cc: cc, refs: 1}
+	return cc, nil
+}
+
+func (c *ConnCache) release(key connCacheKey) error {
+	c.mu.Lock()
+	cached, ok := c.conns[key]
+	if !ok {
+		c.mu.Unlock()
+		return nil
+	}
+	cached.refs--
+	if cached.refs > 0 {
+		c.mu.Unlock()
+		return nil
+	}
+	delete(c.conns, key)
+	c.mu.Unlock()
+	return cached.cc.Close()
+}
+
+// dial dials the connection, or acquires it if it is shared through a ConnCache.
+func (c *PoolConn) dial(ctx context.Context, opts []grpc.DialOption) error {
+	var err error
+	if c.cache != nil {
+		c.cc, err = c.cache.acquire(ctx, c.cacheKey, opts)
+	} else {
+		c.cc, err = grpc.DialContext(ctx, c.endpoint.Addr, opts...)
+	}
+	return err
+}
+
+// close closes the connection, or releases it if it is shared through a ConnCache.
+func (c *PoolConn) close() error {
+	if c.cache != nil {
+		return c.cache.release(c.cacheKey)
+	}
+	return c.cc.Close()
+}
@@ -0,0 +1,75 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestConnCache(t *testing.T) {
+	_, l := mockServer(t)
+	cache := NewConnCache()
+	opts := []Option{
+		WithConnCache(cache, "billing"),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	}
+
+	a, err := NewPool(context.Background(), l.Addr().String(), append(opts, WithSize(2))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewPool(context.Background(), l.Addr().String(), append(opts, WithSize(3))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cache.Len() != 3 {
+		t.Errorf("cache.Len() got %d; want 3", cache.Len())
+	}
+	for i := 0; i < 2; i++ {
+		if a.Conns()[i].ClientConn() != b.Conns()[i].ClientConn() {
+			t.Errorf("conn #%d not shared between pools", i)
+		}
+	}
+
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if state := b.Conns()[0].State(); state == connectivity.Shutdown {
+		t.Error("shared conn closed while still in use by a pool")
+	}
+
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if state := b.Conns()[0].State(); state != connectivity.Shutdown {
+		t.Errorf("shared conn state after closing both pools got %v; want SHUTDOWN", state)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("cache.Len() got %d; want 0", cache.Len())
+	}
+}
+
+func TestConnCacheKeys(t *testing.T) {
+	_, l := mockServer(t)
+	cache := NewConnCache()
+	dialOpt := WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials()))
+
+	a, err := NewPool(context.Background(), l.Addr().String(), WithConnCache(cache, "a"), dialOpt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	b, err := NewPool(context.Background(), l.Addr().String(), WithConnCache(cache, "b"), dialOpt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	if a.Conn() == b.Conn() {
+		t.Error("pools with different cache keys share a conn")
+	}
+}
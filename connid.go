@@ -0,0 +1,35 @@
+package grpcpool
+
+import (
+	"context"
+	"os"
+	"strconv"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// ConnIDHeader is the metadata header set by WithConnIDHeader.
+const ConnIDHeader = "x-grpcpool-conn-id"
+
+// WithConnIDHeader sets the ConnIDHeader on every call and stream to "<name>/<conn index>/<process id>", e.g.
+// "billing/3/4711", so server-side logs can be correlated with the client connection a call was made on.
+func WithConnIDHeader(name string) Option {
+	return func(o *options) {
+		o.connIDName = name
+	}
+}
+
+// setConnID sets the conn ID of c, see WithConnIDHeader.
+func (o *options) setConnID(c *PoolConn) {
+	if o.connIDName != "" {
+		c.connID = o.connIDName + "/" + strconv.Itoa(c.index) + "/" + strconv.Itoa(os.Getpid())
+	}
+}
+
+// withConnID returns ctx with the conn ID header of c, if any.
+func (o *options) withConnID(ctx context.Context, c *PoolConn) context.Context {
+	if c.connID == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, ConnIDHeader, c.connID)
+}
@@ -0,0 +1,53 @@
+package grpcpool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestConnIDHeader(t *testing.T) {
+	_, l := mockServer(t)
+	var got []string
+	record := func(ctx context.Context) {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		got = md.Get(ConnIDHeader)
+	}
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(2),
+		WithConnIDHeader("billing"),
+		WithDialOptions(
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+				record(ctx)
+				return nil
+			}),
+			grpc.WithStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+				record(ctx)
+				return nil, nil
+			}),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	if err := pool.Invoke(context.Background(), "/test.Test/Call", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf("billing/1/%d", os.Getpid()); len(got) != 1 || got[0] != want {
+		t.Errorf("call %s got %v; want [%s]", ConnIDHeader, got, want)
+	}
+	if _, err := pool.NewStream(context.Background(), &grpc.StreamDesc{}, "/test.Test/Stream"); err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf("billing/0/%d", os.Getpid()); len(got) != 1 || got[0] != want {
+		t.Errorf("stream %s got %v; want [%s]", ConnIDHeader, got, want)
+	}
+}
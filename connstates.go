@@ -0,0 +1,49 @@
+package grpcpool
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/connectivity"
+)
+
+// ListConnStates returns the connectivity state of every connection of the pool, in the order of their index. It
+// only reads the states, so health checks and dashboards can call it as often as they like.
+func (p *Pool) ListConnStates() []connectivity.State {
+	conns := p.set.Load().conns
+	states := make([]connectivity.State, len(conns))
+	for i, c := range conns {
+		states[i] = c.cc.GetState()
+	}
+	return states
+}
+
+// ListConnTransitions returns when every connection of the pool entered its current state, in the order of their
+// index, see PoolConn.StateSince.
+func (p *Pool) ListConnTransitions() []time.Time {
+	conns := p.set.Load().conns
+	since := make([]time.Time, len(conns))
+	for i, c := range conns {
+		since[i] = c.StateSince()
+	}
+	return since
+}
+
+// StateSince returns when the connection entered its current state, or when it was dialed if it never changed.
+// States entered and left between two observations of the connection aren't seen.
+func (c *PoolConn) StateSince() time.Time {
+	return time.Unix(0, c.stateSince.Load())
+}
+
+// watchState records when c changes state until it shuts down.
+func (p *Pool) watchState(c *PoolConn) {
+	clock := p.opts.clock
+	state := c.cc.GetState()
+	c.stateSince.Store(clock.Now().UnixNano())
+	go func() {
+		for state != connectivity.Shutdown && c.cc.WaitForStateChange(context.Background(), state) {
+			state = c.cc.GetState()
+			c.stateSince.Store(clock.Now().UnixNano())
+		}
+	}()
+}
@@ -0,0 +1,43 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestListConnStates(t *testing.T) {
+	s, l := mockServer(t)
+	start := time.Now()
+	pool, err := NewEndpointPool(context.Background(), []Endpoint{{Addr: l.Addr().String()}, {Addr: "localhost:1"}},
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	waitForState(t, pool.Conns()[0].ClientConn(), connectivity.Ready)
+	waitForState(t, pool.Conns()[1].ClientConn(), connectivity.TransientFailure)
+	if got := pool.ListConnStates(); len(got) != 2 || got[0] != connectivity.Ready || got[1] != connectivity.TransientFailure {
+		t.Errorf("ListConnStates() got %v; want [READY TRANSIENT_FAILURE]", got)
+	}
+	ready := pool.ListConnTransitions()
+	for i, since := range ready {
+		if since.Before(start) || since.After(time.Now()) {
+			t.Errorf("conn #%d entered its state at %v; want since the dial at %v", i, since, start)
+		}
+	}
+
+	s.Stop()
+	deadline := time.Now().Add(5 * time.Second)
+	for pool.ListConnStates()[0] == connectivity.Ready || !pool.ListConnTransitions()[0].After(ready[0]) {
+		if time.Now().After(deadline) {
+			t.Fatal("conn #0 didn't change state after the server stopped")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
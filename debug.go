@@ -0,0 +1,136 @@
+package grpcpool
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// DebugStateVersion is the version of the DebugState format. It is incremented whenever fields are renamed or
+// change meaning, so golden files of an older format are recognizable.
+const DebugStateVersion = 1
+
+// DebugState is a snapshot of the state of a Pool for debugging and golden tests.
+//
+// Its JSON form is stable: fields are in declaration order and lists are ordered by connection index, group
+// name or label, so snapshots of the same state serialize to the same bytes.
+type DebugState struct {
+	Version  int               `json:"version"`
+	Sessions int64             `json:"sessions"`
+	Conns    []ConnDebugState  `json:"conns"`
+	Groups   []GroupDebugState `json:"groups,omitempty"`
+	Labels   []LabelDebugState `json:"labels,omitempty"`
+}
+
+// ConnDebugState is the state of a connection in a DebugState.
+type ConnDebugState struct {
+	Index    int    `json:"index"`
+	Addr     string `json:"addr"`
+	Peer     string `json:"peer,omitempty"`
+	Zone     string `json:"zone,omitempty"`
+	Priority int    `json:"priority,omitempty"`
+	Group    string `json:"group,omitempty"`
+	State    string `json:"state"`
+	Calls    int64  `json:"calls"`
+	Errors   int64  `json:"errors"`
+	InFlight int64  `json:"inflight"`
+	Streams  int64  `json:"streams,omitempty"`
+	Sending  int64  `json:"sending,omitempty"`
+}
+
+// GroupDebugState is the state of a connection group in a DebugState. The default group has an empty name.
+type GroupDebugState struct {
+	Name  string `json:"name"`
+	Conns []int  `json:"conns"`
+}
+
+// LabelDebugState is the state of a call label in a DebugState, see ContextWithCallLabel.
+type LabelDebugState struct {
+	Label  string `json:"label"`
+	Calls  int64  `json:"calls"`
+	Errors int64  `json:"errors"`
+}
+
+// DebugState returns a snapshot of the state of the pool.
+func (p *Pool) DebugState() DebugState {
+	s := p.set.Load()
+	state := DebugState{
+		Version:  DebugStateVersion,
+		Sessions: p.sessions.Load(),
+		Conns:    make([]ConnDebugState, len(s.conns)),
+	}
+	for i, c := range s.conns {
+		state.Conns[i] = ConnDebugState{
+			Index:    c.index,
+			Addr:     c.endpoint.Addr,
+			Peer:     c.PeerAddr(),
+			Zone:     c.endpoint.Zone,
+			Priority: c.endpoint.Priority,
+			Group:    c.group,
+			State:    c.State().String(),
+			Calls:    c.calls.Load(),
+			Errors:   c.errors.Load(),
+			InFlight: c.inflight.Load(),
+			Streams:  c.streams.Load(),
+			Sending:  c.sending.Load(),
+		}
+	}
+	for name, g := range s.groups {
+		gs := GroupDebugState{Name: name, Conns: make([]int, len(g.conns))}
+		for i, c := range g.conns {
+			gs.Conns[i] = c.index
+		}
+		sort.Ints(gs.Conns)
+		state.Groups = append(state.Groups, gs)
+	}
+	sort.Slice(state.Groups, func(i, j int) bool { return state.Groups[i].Name < state.Groups[j].Name })
+	for _, label := range p.Labels() {
+		stats := p.LabelStats(label)
+		state.Labels = append(state.Labels, LabelDebugState{Label: label, Calls: stats.Calls, Errors: stats.Errors})
+	}
+	sort.Slice(state.Labels, func(i, j int) bool { return state.Labels[i].Label < state.Labels[j].Label })
+	return state
+}
+
+// TenantDebugState is a snapshot of the state of a TenantPool, see DebugState.
+type TenantDebugState struct {
+	Version int               `json:"version"`
+	Tenants []TenantPoolState `json:"tenants"`
+}
+
+// TenantPoolState is the state of a tenant's sub-pool in a TenantDebugState.
+type TenantPoolState struct {
+	Tenant   string     `json:"tenant"`
+	InFlight int        `json:"inflight"`
+	Pool     DebugState `json:"pool"`
+}
+
+// DebugState returns a snapshot of the state of the pool. Tenants are ordered by name.
+func (p *TenantPool) DebugState() TenantDebugState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	state := TenantDebugState{Version: DebugStateVersion, Tenants: []TenantPoolState{}}
+	for e := p.lru.Front(); e != nil; e = e.Next() {
+		tp := e.Value.(*tenantPool)
+		state.Tenants = append(state.Tenants, TenantPoolState{Tenant: tp.tenant, InFlight: tp.inflight, Pool: tp.pool.DebugState()})
+	}
+	sort.Slice(state.Tenants, func(i, j int) bool { return state.Tenants[i].Tenant < state.Tenants[j].Tenant })
+	return state
+}
+
+// MarshalGolden returns the indented JSON form of s, ending in a newline as expected of golden files.
+func (s DebugState) MarshalGolden() ([]byte, error) {
+	return marshalGolden(s)
+}
+
+// MarshalGolden returns the indented JSON form of s, see DebugState.MarshalGolden.
+func (s TenantDebugState) MarshalGolden() ([]byte, error) {
+	return marshalGolden(s)
+}
+
+func marshalGolden(v interface{}) ([]byte, error) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
@@ -0,0 +1,58 @@
+package grpcpool
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestDebugState(t *testing.T) {
+	pool, err := NewEndpointPool(context.Background(),
+		[]Endpoint{{Addr: "localhost:1", Zone: "a"}, {Addr: "localhost:2", Zone: "b"}},
+		WithBulkConns(1),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	state := pool.DebugState()
+	if state.Version != DebugStateVersion || len(state.Conns) != 2 || state.Conns[1].Zone != "b" || state.Conns[1].Group != BulkGroup {
+		t.Errorf("pool.DebugState() got %+v", state)
+	}
+	if len(state.Groups) != 2 || state.Groups[0].Name != "" || state.Groups[1].Name != BulkGroup {
+		t.Errorf("pool.DebugState() groups got %+v; want the default and bulk groups in order", state.Groups)
+	}
+	a, _ := state.MarshalGolden()
+	b, _ := pool.DebugState().MarshalGolden()
+	if !bytes.Equal(a, b) || !bytes.HasSuffix(a, []byte("}\n")) {
+		t.Errorf("MarshalGolden of the same state differs:\n%s\n%s", a, b)
+	}
+}
+
+func TestTenantDebugState(t *testing.T) {
+	_, l := mockServer(t)
+	pool, err := NewTenantPool(l.Addr().String(), TenantConfig{
+		Tenant:     func(ctx context.Context) (string, bool) { return ctx.Value(tenantKey{}).(string), true },
+		MaxTenants: 2,
+	}, WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	for _, tenant := range []string{"c", "a", "b"} {
+		pool.Invoke(context.WithValue(context.Background(), tenantKey{}, tenant), "/test.Test/Call", nil, nil)
+	}
+	state := pool.DebugState()
+	if len(state.Tenants) != 2 || state.Tenants[0].Tenant != "a" || state.Tenants[1].Tenant != "b" {
+		t.Errorf("pool.DebugState() after evicting c got %+v; want tenants a, b", state.Tenants)
+	}
+	if calls := state.Tenants[0].Pool.Conns[0].Calls; calls != 1 {
+		t.Errorf("tenant a calls got %d; want 1", calls)
+	}
+}
@@ -0,0 +1,53 @@
+package grpcpool
+
+import (
+	"context"
+	"time"
+)
+
+// cleanupTimeout is how long the cleanup of ProvidePool waits for in-flight calls and streams before closing the
+// pool.
+const cleanupTimeout = 30 * time.Second
+
+// ProvidePool is a google/wire provider creating a Pool from cfg, see NewFromConfig.
+// The returned cleanup shuts the pool down as StopHook does, giving its calls and streams 30 seconds to finish:
+//
+//	wire.Build(loadConfig, grpcpool.ProvidePool, grpcpool.ProvideConnPool, newService)
+func ProvidePool(ctx context.Context, cfg Config) (*Pool, func(), error) {
+	p, err := NewFromConfig(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+		defer cancel()
+		StopHook(p)(ctx)
+	}
+	return p, cleanup, nil
+}
+
+// ProvideConnPool binds a Pool as the ConnPool, and so grpc.ClientConnInterface, of a DI graph.
+func ProvideConnPool(p *Pool) ConnPool {
+	return p
+}
+
+// StartHook returns an uber/fx OnStart hook waiting for every connection of p to be ready, so the application
+// doesn't start taking traffic before its dependency is reachable. Startup fails if the start context is done first.
+//
+//	fx.Provide(func(lc fx.Lifecycle, cfg grpcpool.Config) (*grpcpool.Pool, error) {
+//		p, err := grpcpool.NewFromConfig(context.Background(), cfg)
+//		if err != nil {
+//			return nil, err
+//		}
+//		lc.Append(fx.Hook{OnStart: grpcpool.StartHook(p), OnStop: grpcpool.StopHook(p)})
+//		return p, nil
+//	})
+func StartHook(p *Pool) func(context.Context) error {
+	return p.WaitForReady
+}
+
+// StopHook returns an uber/fx OnStop hook shutting p down gracefully, see Shutdown and StartHook. New calls are
+// refused, and in-flight calls and streams get until the stop context is done to finish.
+func StopHook(p *Pool) func(context.Context) error {
+	return p.Shutdown
+}
@@ -0,0 +1,118 @@
+package grpcpool
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestProvidePool(t *testing.T) {
+	_, l := mockServer(t)
+	p, cleanup, err := ProvidePool(context.Background(), Config{Target: l.Addr().String(), Size: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ProvideConnPool(p).Num() != 2 {
+		t.Errorf("ProvideConnPool(p).Num() got %d; want 2", ProvideConnPool(p).Num())
+	}
+	cleanup()
+	if state := p.Conns()[0].State(); state != connectivity.Shutdown {
+		t.Errorf("conn state after cleanup got %v; want SHUTDOWN", state)
+	}
+
+	if _, _, err := ProvidePool(context.Background(), Config{}); err == nil {
+		t.Error("ProvidePool without target succeeded")
+	}
+}
+
+func TestLifecycleHooks(t *testing.T) {
+	_, l := mockServer(t)
+	p, err := NewFromConfig(context.Background(), Config{Target: l.Addr().String(), Size: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := StartHook(p)(ctx); err != nil {
+		t.Fatalf("StartHook(p) got %v", err)
+	}
+	for i, c := range p.Conns() {
+		if state := c.State(); state != connectivity.Ready {
+			t.Errorf("conn #%d state after start got %v; want READY", i, state)
+		}
+	}
+	if err := StopHook(p)(ctx); err != nil {
+		t.Fatalf("StopHook(p) got %v", err)
+	}
+	if p.Healthy() {
+		t.Error("p.Healthy() after stop got true")
+	}
+}
+
+func TestStopHookDrains(t *testing.T) {
+	l := echoStreamServer(t)
+	p, err := NewFromConfig(context.Background(), Config{Target: l.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := p.NewStream(context.Background(), &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}, "/test.Echo/Echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stopped := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		stopped <- StopHook(p)(ctx)
+	}()
+	select {
+	case err := <-stopped:
+		t.Fatalf("StopHook(p) returned %v before the open stream ended", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	cs.CloseSend()
+	if err := cs.RecvMsg(&emptypb.Empty{}); err != io.EOF {
+		t.Fatalf("RecvMsg() got %v; want io.EOF", err)
+	}
+	if err := <-stopped; err != nil {
+		t.Errorf("StopHook(p) got %v", err)
+	}
+}
+
+func TestProvidePoolCleanupDrains(t *testing.T) {
+	l := echoStreamServer(t)
+	p, cleanup, err := ProvidePool(context.Background(), Config{Target: l.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := p.NewStream(context.Background(), &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}, "/test.Echo/Echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cleaned := make(chan struct{})
+	go func() {
+		cleanup()
+		close(cleaned)
+	}()
+	select {
+	case <-cleaned:
+		t.Fatal("cleanup returned before the open stream ended")
+	case <-time.After(50 * time.Millisecond):
+	}
+	cs.CloseSend()
+	if err := cs.RecvMsg(&emptypb.Empty{}); err != io.EOF {
+		t.Fatalf("RecvMsg() got %v; want io.EOF", err)
+	}
+	<-cleaned
+	if state := p.Conns()[0].State(); state != connectivity.Shutdown {
+		t.Errorf("conn state after cleanup got %v; want SHUTDOWN", state)
+	}
+}
@@ -0,0 +1,87 @@
+package grpcpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+
+	"google.golang.org/grpc"
+)
+
+// WithContextDialer sets the dialer of the transports of the connections, as grpc.WithContextDialer does, e.g. to
+// dial a bufconn listener. Unlike a dialer given with grpc.WithContextDialer, it is wrapped by WithDialMetrics, so
+// the dials through it are timed.
+//
+// WithSourcePorts, WithSocketControl and WithEgressProxies configure the pool's own dialer and can't be combined
+// with it; NewPool fails if they are.
+func WithContextDialer(f func(context.Context, string) (net.Conn, error)) Option {
+	return func(o *options) {
+		o.dialer = f
+	}
+}
+
+// checkDialer returns an error if the dialer of WithContextDialer is combined with options configuring the pool's
+// own dialer.
+func (o *options) checkDialer() error {
+	if o.dialer != nil && (o.sourcePorts != nil || o.socketControl != nil || o.egress != nil) {
+		return errors.New("grpcpool: WithContextDialer can't be combined with WithSourcePorts, WithSocketControl or WithEgressProxies")
+	}
+	return nil
+}
+
+// connDialOptions returns the dial options of c: the pool's dialer, the pool's options, the credentials and
+// authority of its endpoint, the options of its group and the per-connection ones, in that order. The dialer is
+// first so a dialer given with grpc.WithContextDialer takes precedence instead of being replaced.
+func (o *options) connDialOptions(c *PoolConn) ([]grpc.DialOption, error) {
+	var perConn []grpc.DialOption
+	if o.credsFunc != nil {
+		creds, err := o.credsFunc(c.endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("grpcpool: credentials for %s: %w", c.endpoint.Addr, err)
+		}
+		perConn = append(perConn, grpc.WithTransportCredentials(creds))
+	}
+	if c.endpoint.Authority != "" {
+		perConn = append(perConn, grpc.WithAuthority(c.endpoint.Authority))
+	}
+	perConn = append(perConn, o.groupDialOptions(c.group)...)
+	perConn = append(perConn, c.trafficDialOptions()...)
+	perConn = append(perConn, o.certExpiryDialOptions(c)...)
+	perConn = append(perConn, o.auditDialOptions(c)...)
+	perConn = append(perConn, o.dialMetricsDialOptions(c)...)
+	perConn = append(perConn, o.peerAddrDialOptions(c)...)
+	if dial := o.contextDialer(c); dial != nil {
+		opts := make([]grpc.DialOption, 0, 1+len(o.dialOpts)+len(perConn))
+		opts = append(opts, grpc.WithContextDialer(dial))
+		return append(append(opts, o.dialOpts...), perConn...), nil
+	}
+	if len(perConn) == 0 {
+		return o.dialOpts, nil
+	}
+	return append(o.dialOpts[:len(o.dialOpts):len(o.dialOpts)], perConn...), nil
+}
+
+// contextDialer returns the dialer of the transport of c, or nil for the gRPC default.
+func (o *options) contextDialer(c *PoolConn) func(context.Context, string) (net.Conn, error) {
+	if o.dialer != nil {
+		return o.timeDials(c, o.dialer)
+	}
+	local := o.localAddr(c)
+	if local == nil && o.egress == nil && o.socketControl == nil && c.dials == nil {
+		return nil
+	}
+	d := &net.Dialer{LocalAddr: local}
+	if o.socketControl != nil {
+		d.Control = func(network, address string, conn syscall.RawConn) error {
+			return o.socketControl(c, network, address, conn)
+		}
+	}
+	if o.egress != nil {
+		return o.timeDials(c, o.egress.dialer(c, d, o.clock))
+	}
+	return o.timeDials(c, func(ctx context.Context, addr string) (net.Conn, error) {
+		return d.DialContext(ctx, "tcp", addr)
+	})
+}
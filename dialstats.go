@@ -0,0 +1,212 @@
+package grpcpool
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/stats"
+)
+
+// DialStats are the dial counters of a connection, see WithDialMetrics.
+type DialStats struct {
+	// Dials is the number of transports established, and FailedDials the number of dials that failed to connect.
+	Dials       int64
+	FailedDials int64
+
+	// DialTime is the total time of the dials, from connecting to the transport being established or the dial
+	// failing, and LastDialTime the time of the last one.
+	DialTime     time.Duration
+	LastDialTime time.Duration
+
+	// Reconnects is the number of times the connection became ready again after TRANSIENT_FAILURE, and
+	// ReconnectTime the total time it spent in TRANSIENT_FAILURE until then.
+	Reconnects    int64
+	ReconnectTime time.Duration
+}
+
+func (s *DialStats) add(o DialStats) {
+	s.Dials += o.Dials
+	s.FailedDials += o.FailedDials
+	s.DialTime += o.DialTime
+	s.Reconnects += o.Reconnects
+	s.ReconnectTime += o.ReconnectTime
+}
+
+// DialEvent is a dial or reconnect of a connection, see WithDialMetrics.
+type DialEvent struct {
+	// Duration is how long the dial took, including the TLS handshake of transports established, or for
+	// reconnects how long the connection was in TRANSIENT_FAILURE.
+	Duration time.Duration
+
+	// Failed reports whether the dial failed to connect.
+	Failed bool
+
+	// Reconnect reports whether the event is a reconnect: the connection became ready again after
+	// TRANSIENT_FAILURE.
+	Reconnect bool
+}
+
+// WithDialMetrics times the dials of the connections, and how often and how long they cycle through
+// TRANSIENT_FAILURE to READY, so a backend slow to accept connections can be told apart from slow calls. The
+// counters are read with PoolConn.DialStats and Pool.DialStats, and f, which may be nil, is called with every
+// event, e.g. to observe Prometheus histograms. f is called from the dials and a goroutine per connection and must
+// not block.
+//
+// Dials are timed by wrapping the dialer of the connections: the default one, or the one given with
+// WithContextDialer. A dialer given with grpc.WithContextDialer takes precedence over the wrapping one, so its dials
+// aren't timed; give it with WithContextDialer instead.
+func WithDialMetrics(f func(*PoolConn, DialEvent)) Option {
+	return func(o *options) {
+		o.dialMetrics = &dialMetrics{f: f}
+	}
+}
+
+type dialMetrics struct {
+	f func(*PoolConn, DialEvent)
+}
+
+// connDials are the dial counters of a connection, see DialStats.
+type connDials struct {
+	started atomic.Int64 // unix nanoseconds of the dial of the transport being established, zero if none
+
+	dials, failed         atomic.Int64
+	dialTime, last        atomic.Int64 // nanoseconds
+	reconnects, reconTime atomic.Int64 // reconTime in nanoseconds
+}
+
+// setDials starts counting the dials of c if enabled.
+func (o *options) setDials(c *PoolConn) {
+	if o.dialMetrics != nil {
+		c.dials = &connDials{}
+	}
+}
+
+// DialStats returns the dial counters of the connection, zero without WithDialMetrics.
+func (c *PoolConn) DialStats() DialStats {
+	d := c.dials
+	if d == nil {
+		return DialStats{}
+	}
+	return DialStats{
+		Dials:         d.dials.Load(),
+		FailedDials:   d.failed.Load(),
+		DialTime:      time.Duration(d.dialTime.Load()),
+		LastDialTime:  time.Duration(d.last.Load()),
+		Reconnects:    d.reconnects.Load(),
+		ReconnectTime: time.Duration(d.reconTime.Load()),
+	}
+}
+
+// DialStats returns the sum of the dial counters of the current connections of the pool. LastDialTime is zero.
+func (p *Pool) DialStats() DialStats {
+	var stats DialStats
+	for _, c := range p.set.Load().conns {
+		stats.add(c.DialStats())
+	}
+	return stats
+}
+
+// record counts e on c.
+func (m *dialMetrics) record(c *PoolConn, e DialEvent) {
+	d := c.dials
+	switch {
+	case e.Reconnect:
+		d.reconnects.Add(1)
+		d.reconTime.Add(int64(e.Duration))
+	case e.Failed:
+		d.failed.Add(1)
+	default:
+		d.dials.Add(1)
+	}
+	if !e.Reconnect {
+		d.dialTime.Add(int64(e.Duration))
+		d.last.Store(int64(e.Duration))
+	}
+	if m.f != nil {
+		m.f(c, e)
+	}
+}
+
+// timeDials returns dial timing the dials of c, or dial itself if they aren't timed.
+func (o *options) timeDials(c *PoolConn, dial func(context.Context, string) (net.Conn, error)) func(context.Context, string) (net.Conn, error) {
+	if c.dials == nil {
+		return dial
+	}
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		start := o.clock.Now()
+		conn, err := dial(ctx, addr)
+		if err != nil {
+			o.dialMetrics.record(c, DialEvent{Duration: o.clock.Now().Sub(start), Failed: true})
+			return nil, err
+		}
+		c.dials.started.Store(start.UnixNano())
+		return conn, nil
+	}
+}
+
+// dialMetricsDialOptions returns the dial options timing the transports of c, if timed.
+func (o *options) dialMetricsDialOptions(c *PoolConn) []grpc.DialOption {
+	if c.dials == nil {
+		return nil
+	}
+	return []grpc.DialOption{grpc.WithStatsHandler(dialWatcher{conn: c, opts: o})}
+}
+
+// dialWatcher is a stats.Handler timing the transports of a connection from their dial to being established.
+type dialWatcher struct {
+	conn *PoolConn
+	opts *options
+}
+
+func (w dialWatcher) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (w dialWatcher) HandleRPC(context.Context, stats.RPCStats) {}
+
+func (w dialWatcher) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (w dialWatcher) HandleConn(_ context.Context, s stats.ConnStats) {
+	if _, ok := s.(*stats.ConnBegin); !ok {
+		return
+	}
+	if start := w.conn.dials.started.Swap(0); start != 0 {
+		d := w.opts.clock.Now().Sub(time.Unix(0, start))
+		w.opts.dialMetrics.record(w.conn, DialEvent{Duration: d})
+	}
+}
+
+// watchDials counts the reconnects of c until it shuts down, if its dials are timed.
+func (p *Pool) watchDials(c *PoolConn) {
+	if c.dials == nil {
+		return
+	}
+	clock := p.opts.clock
+	var failing time.Time // when the connection entered TRANSIENT_FAILURE, zero if it isn't in it
+	state := c.cc.GetState()
+	if state == connectivity.TransientFailure {
+		failing = clock.Now()
+	}
+	go func() {
+		for state != connectivity.Shutdown && c.cc.WaitForStateChange(context.Background(), state) {
+			state = c.cc.GetState()
+			switch {
+			case state == connectivity.TransientFailure:
+				if failing.IsZero() {
+					failing = clock.Now()
+				}
+			case state == connectivity.Ready && !failing.IsZero():
+				p.opts.dialMetrics.record(c, DialEvent{Duration: clock.Now().Sub(failing), Reconnect: true})
+				failing = time.Time{}
+			case state != connectivity.Connecting:
+				failing = time.Time{}
+			}
+		}
+	}()
+}
@@ -0,0 +1,126 @@
+package grpcpool
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestDialMetrics(t *testing.T) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close() // refuse the first dials
+
+	var (
+		mu     sync.Mutex
+		events []DialEvent
+	)
+	pool, err := NewPool(context.Background(), addr,
+		WithSize(1),
+		WithDialMetrics(func(_ *PoolConn, e DialEvent) {
+			mu.Lock()
+			events = append(events, e)
+			mu.Unlock()
+		}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	cc := pool.Conns()[0].ClientConn()
+	waitForState(t, cc, connectivity.TransientFailure)
+
+	if l, err = net.Listen("tcp", addr); err != nil {
+		t.Skipf("listening on %s again: %v", addr, err)
+	}
+	s := grpc.NewServer()
+	go s.Serve(l)
+	defer s.Stop()
+	waitForState(t, cc, connectivity.Ready)
+
+	// The reconnect is counted before it is delivered to f.
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		delivered := len(events) > 0 && events[len(events)-1].Reconnect
+		mu.Unlock()
+		if delivered {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	stats := pool.DialStats()
+	if stats.Dials != 1 || stats.FailedDials == 0 || stats.Reconnects != 1 {
+		t.Fatalf("DialStats got %+v; want 1 dial after failed ones and 1 reconnect", stats)
+	}
+	if stats.DialTime <= 0 || stats.ReconnectTime <= 0 {
+		t.Errorf("DialStats got %+v; want dial and reconnect times", stats)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if n, want := int64(len(events)), stats.Dials+stats.FailedDials+stats.Reconnects; n != want {
+		t.Errorf("got %d dial events; want %d", n, want)
+	}
+	if last := events[len(events)-1]; !last.Reconnect || last.Duration != stats.ReconnectTime {
+		t.Errorf("last dial event got %+v; want a successful reconnect", last)
+	}
+}
+
+func TestDialMetricsDisabled(t *testing.T) {
+	_, l := mockServer(t)
+	pool, err := NewPool(context.Background(), l.Addr().String(), WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	waitForState(t, pool.Conn(), connectivity.Ready)
+	if stats := pool.DialStats(); stats != (DialStats{}) {
+		t.Errorf("DialStats without WithDialMetrics got %+v; want zero", stats)
+	}
+}
+
+func TestDialMetricsCustomDialer(t *testing.T) {
+	l := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	go s.Serve(l)
+	defer s.Stop()
+	dial := func(ctx context.Context, _ string) (net.Conn, error) {
+		return l.DialContext(ctx)
+	}
+	creds := WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials()))
+
+	// A dialer given to the pool is timed.
+	pool, err := NewPool(context.Background(), "passthrough:///bufnet", WithDialMetrics(nil), WithContextDialer(dial), creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	waitForState(t, pool.Conns()[0].ClientConn(), connectivity.Ready)
+	if stats := pool.DialStats(); stats.Dials != 1 {
+		t.Errorf("DialStats with WithContextDialer got %+v; want 1 dial", stats)
+	}
+
+	// A dialer given as a dial option isn't replaced.
+	pool, err = NewPool(context.Background(), "passthrough:///bufnet", WithDialMetrics(nil),
+		WithDialOptions(grpc.WithContextDialer(dial), grpc.WithTransportCredentials(insecure.NewCredentials())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	waitForState(t, pool.Conns()[0].ClientConn(), connectivity.Ready)
+
+	if _, err := NewPool(context.Background(), "passthrough:///bufnet", WithContextDialer(dial), WithSourcePorts(20000, 4), creds); err == nil {
+		t.Error("NewPool() with WithContextDialer and WithSourcePorts succeeded")
+	}
+}
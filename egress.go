@@ -0,0 +1,205 @@
+package grpcpool
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// egressCooldown is how long an egress proxy that failed a dial is skipped by connections preferring another.
+const egressCooldown = 30 * time.Second
+
+type egress struct {
+	urls    []string
+	proxies []*egressProxy
+}
+
+type egressProxy struct {
+	url      *url.URL
+	dials    atomic.Int64
+	failures atomic.Int64
+	failedAt atomic.Int64 // unix nanoseconds of the last failed dial since the last successful one, 0 if none
+}
+
+// WithEgressProxies dials the pool's connections through the egress proxies at proxyURLs, "http://host:port"
+// for HTTP CONNECT proxies and "socks5://host:port" for SOCKS5 proxies, with optional user info for
+// authentication.
+//
+// Connection i prefers proxy i modulo the number of proxies, so connections are spread across them. A proxy
+// failing a dial is skipped by the other connections for a while, and connections preferring it dial through the
+// next healthy proxy instead, so one bad egress box doesn't take out the pool. See Pool.ProxyStats.
+// Only TCP endpoints are supported.
+//
+// The proxies are dialed by the pool's own dialer: a dialer given with grpc.WithContextDialer takes precedence over
+// it, and WithContextDialer can't be combined with WithEgressProxies.
+func WithEgressProxies(proxyURLs ...string) Option {
+	return func(o *options) {
+		o.egress = &egress{urls: proxyURLs}
+	}
+}
+
+// validate parses the proxy URLs.
+func (e *egress) validate() error {
+	if len(e.urls) == 0 {
+		return errors.New("grpcpool: no egress proxies")
+	}
+	e.proxies = make([]*egressProxy, len(e.urls))
+	for i, raw := range e.urls {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return fmt.Errorf("grpcpool: invalid egress proxy: %w", err)
+		}
+		if u.Scheme != "http" && u.Scheme != "socks5" {
+			return fmt.Errorf("grpcpool: egress proxy %s: scheme must be http or socks5", raw)
+		}
+		e.proxies[i] = &egressProxy{url: u}
+	}
+	return nil
+}
+
+// ProxyStats are the dial counters of an egress proxy, see WithEgressProxies.
+type ProxyStats struct {
+	// URL is the URL of the proxy, without user info.
+	URL string
+
+	// Dials and Failures are the number of dials through the proxy and those that failed.
+	Dials, Failures int64
+
+	// Healthy is false while the proxy is skipped after a failed dial.
+	Healthy bool
+}
+
+// ProxyStats returns the dial counters of the pool's egress proxies, in the order they were given.
+func (p *Pool) ProxyStats() []ProxyStats {
+	if p.opts.egress == nil {
+		return nil
+	}
+	now := p.opts.clock.Now()
+	stats := make([]ProxyStats, len(p.opts.egress.proxies))
+	for i, px := range p.opts.egress.proxies {
+		u := *px.url
+		u.User = nil
+		stats[i] = ProxyStats{
+			URL:      u.String(),
+			Dials:    px.dials.Load(),
+			Failures: px.failures.Load(),
+			Healthy:  px.healthy(now),
+		}
+	}
+	return stats
+}
+
+func (px *egressProxy) healthy(now time.Time) bool {
+	failedAt := px.failedAt.Load()
+	return failedAt == 0 || now.Sub(time.Unix(0, failedAt)) >= egressCooldown
+}
+
+// dialer returns the dialer of c, connecting to proxies with d.
+func (e *egress) dialer(c *PoolConn, d *net.Dialer, clock Clock) func(context.Context, string) (net.Conn, error) {
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		// Try the healthy proxies from the preferred one on, then the unhealthy ones.
+		now := clock.Now()
+		order := make([]*egressProxy, 0, len(e.proxies))
+		var unhealthy []*egressProxy
+		for i := range e.proxies {
+			px := e.proxies[(c.index+i)%len(e.proxies)]
+			if px.healthy(now) {
+				order = append(order, px)
+			} else {
+				unhealthy = append(unhealthy, px)
+			}
+		}
+		var err error
+		for _, px := range append(order, unhealthy...) {
+			var conn net.Conn
+			px.dials.Add(1)
+			if conn, err = px.dial(ctx, d, addr); err == nil {
+				px.failedAt.Store(0)
+				return conn, nil
+			}
+			px.failures.Add(1)
+			px.failedAt.Store(clock.Now().UnixNano())
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		return nil, err
+	}
+}
+
+// dial connects to addr through px.
+func (px *egressProxy) dial(ctx context.Context, d *net.Dialer, addr string) (net.Conn, error) {
+	if px.url.Scheme == "socks5" {
+		var auth *proxy.Auth
+		if u := px.url.User; u != nil {
+			pass, _ := u.Password()
+			auth = &proxy.Auth{User: u.Username(), Password: pass}
+		}
+		socks, err := proxy.SOCKS5("tcp", px.url.Host, auth, d)
+		if err != nil {
+			return nil, err
+		}
+		return socks.(proxy.ContextDialer).DialContext(ctx, "tcp", addr)
+	}
+	return dialConnect(ctx, d, px.url, addr)
+}
+
+// dialConnect connects to addr through the HTTP CONNECT proxy at proxyURL.
+func dialConnect(ctx context.Context, d *net.Dialer, proxyURL *url.URL, addr string) (net.Conn, error) {
+	conn, err := d.DialContext(ctx, "tcp", proxyURL.Host)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+	if u := proxyURL.User; u != nil {
+		pass, _ := u.Password()
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(u.Username()+":"+pass)))
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("grpcpool: writing CONNECT to %s: %w", proxyURL.Host, err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("grpcpool: reading CONNECT response from %s: %w", proxyURL.Host, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("grpcpool: CONNECT through %s: %s", proxyURL.Host, resp.Status)
+	}
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn is a net.Conn whose reads start with data already buffered.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
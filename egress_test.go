@@ -0,0 +1,176 @@
+package grpcpool
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// pipe copies between a and b until either is closed.
+func pipe(a, b net.Conn) {
+	go func() {
+		io.Copy(a, b)
+		a.Close()
+	}()
+	io.Copy(b, a)
+	b.Close()
+}
+
+// connectProxy starts an HTTP CONNECT proxy counting its tunnels.
+func connectProxy(t *testing.T, tunnels *atomic.Int64) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "CONNECT only", http.StatusMethodNotAllowed)
+			return
+		}
+		backend, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			backend.Close()
+			return
+		}
+		conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		tunnels.Add(1)
+		pipe(conn, backend)
+	})}
+	go s.Serve(l)
+	t.Cleanup(func() { s.Close() })
+	return "http://" + l.Addr().String()
+}
+
+// socks5Proxy starts a SOCKS5 proxy without authentication counting its tunnels.
+func socks5Proxy(t *testing.T, tunnels *atomic.Int64) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				// Greeting: version, methods; reply no authentication.
+				buf := make([]byte, 262)
+				if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+					conn.Close()
+					return
+				}
+				io.ReadFull(conn, buf[:buf[1]])
+				conn.Write([]byte{5, 0})
+				// Request: version, CONNECT, reserved, IPv4 address and port.
+				if _, err := io.ReadFull(conn, buf[:4]); err != nil || buf[3] != 1 {
+					conn.Close()
+					return
+				}
+				io.ReadFull(conn, buf[:6])
+				addr := net.JoinHostPort(net.IP(buf[:4]).String(), strconv.Itoa(int(binary.BigEndian.Uint16(buf[4:6]))))
+				backend, err := net.Dial("tcp", addr)
+				if err != nil {
+					conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+					conn.Close()
+					return
+				}
+				conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+				tunnels.Add(1)
+				pipe(conn, backend)
+			}()
+		}
+	}()
+	return "socks5://" + l.Addr().String()
+}
+
+func TestEgressProxies(t *testing.T) {
+	_, l := mockServer(t)
+	var connectTunnels, socksTunnels atomic.Int64
+
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(4),
+		WithEgressProxies(connectProxy(t, &connectTunnels), socks5Proxy(t, &socksTunnels)),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pool.WarmStreams(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if c, s := connectTunnels.Load(), socksTunnels.Load(); c != 2 || s != 2 {
+		t.Errorf("tunnels got %d CONNECT and %d SOCKS5; want 2 each", c, s)
+	}
+	for _, st := range pool.ProxyStats() {
+		if st.Dials != 2 || st.Failures != 0 || !st.Healthy {
+			t.Errorf("proxy stats got %+v; want 2 healthy dials", st)
+		}
+	}
+}
+
+func TestEgressProxyFailover(t *testing.T) {
+	_, l := mockServer(t)
+	var tunnels atomic.Int64
+	bad, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	badURL := "http://" + bad.Addr().String()
+	bad.Close()
+
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(2),
+		WithEgressProxies(badURL, connectProxy(t, &tunnels)),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pool.WarmStreams(ctx, 1); err != nil {
+		t.Fatalf("WarmStreams() with one bad proxy got %v", err)
+	}
+	if n := tunnels.Load(); n != 2 {
+		t.Errorf("tunnels through the good proxy got %d; want 2", n)
+	}
+	stats := pool.ProxyStats()
+	if stats[0].Healthy || stats[0].Failures == 0 {
+		t.Errorf("bad proxy stats got %+v; want unhealthy with failures", stats[0])
+	}
+	if !stats[1].Healthy {
+		t.Errorf("good proxy stats got %+v; want healthy", stats[1])
+	}
+}
+
+func TestEgressProxiesInvalid(t *testing.T) {
+	for _, u := range []string{"ftp://proxy:21", "http://[::1"} {
+		if _, err := NewPool(context.Background(), "localhost:1", WithEgressProxies(u)); err == nil {
+			t.Errorf("NewPool() with egress proxy %q got no error", u)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package grpcpool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix is the prefix of the environment variables read by ConfigFromEnv.
+const EnvPrefix = "GRPCPOOL_"
+
+// ConfigFromEnv returns cfg with the fields set by environment variables for the pool name, so operators can tune
+// pools per environment without code changes. The variables are EnvPrefix, name and the envconfig tag of the field,
+// with name upper-cased and other characters than letters and digits replaced by underscores, e.g.
+// GRPCPOOL_PAYMENTS_SIZE, GRPCPOOL_PAYMENTS_TARGET and GRPCPOOL_PAYMENTS_CALL_TIMEOUT for the pool "payments".
+// Lists, e.g. GRPCPOOL_PAYMENTS_TARGETS, are comma-separated. Fields without a variable keep their value in cfg.
+func ConfigFromEnv(name string, cfg Config) (Config, error) {
+	prefix := EnvPrefix + envName(name) + "_"
+	v := reflect.ValueOf(&cfg).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		tag := v.Type().Field(i).Tag.Get("envconfig")
+		if tag == "" {
+			continue
+		}
+		key := prefix + tag
+		s, ok := os.LookupEnv(key)
+		if !ok {
+			continue
+		}
+		if err := setEnvField(v.Field(i), s); err != nil {
+			return Config{}, fmt.Errorf("grpcpool: %s: %w", key, err)
+		}
+	}
+	return cfg, nil
+}
+
+// NewNamedPool creates a new Pool named name from cfg with the environment variables of name applied, see
+// ConfigFromEnv and NewFromConfig.
+func NewNamedPool(ctx context.Context, name string, cfg Config, opts ...Option) (*Pool, error) {
+	cfg, err := ConfigFromEnv(name, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewFromConfig(ctx, cfg, opts...)
+}
+
+// envName returns name as part of an environment variable.
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setEnvField sets the Config field f to the value of its environment variable.
+func setEnvField(f reflect.Value, s string) error {
+	if f.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		f.SetInt(int64(d))
+		return nil
+	}
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		f.SetInt(int64(n))
+	case reflect.Uint:
+		n, err := strconv.ParseUint(s, 10, 0)
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+	case reflect.Slice: // []string
+		var list listValue
+		list.Set(s)
+		f.Set(reflect.ValueOf([]string(list)))
+	default:
+		return fmt.Errorf("unsupported field type %s", f.Type())
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package grpcpool
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("GRPCPOOL_PAYMENTS_API_SIZE", "12")
+	t.Setenv("GRPCPOOL_PAYMENTS_API_TARGETS", "a:443, b:443")
+	t.Setenv("GRPCPOOL_PAYMENTS_API_CALL_TIMEOUT", "250ms")
+	t.Setenv("GRPCPOOL_PAYMENTS_API_TLS", "true")
+	t.Setenv("GRPCPOOL_PAYMENTS_API_CONCURRENCY_LIMIT", "64")
+	t.Setenv("GRPCPOOL_OTHER_SIZE", "1")
+
+	got, err := ConfigFromEnv("payments-api", DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DefaultConfig()
+	want.Size = 12
+	want.Targets = []string{"a:443", "b:443"}
+	want.CallTimeout = 250 * time.Millisecond
+	want.TLS = true
+	want.ConcurrencyLimit = 64
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ConfigFromEnv() got %+v; want %+v", got, want)
+	}
+}
+
+func TestConfigFromEnvErrors(t *testing.T) {
+	for key, value := range map[string]string{
+		"GRPCPOOL_BAD_SIZE":         "-1",
+		"GRPCPOOL_BAD_DIAL_TIMEOUT": "5",
+		"GRPCPOOL_BAD_TLS":          "maybe",
+	} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			if _, err := ConfigFromEnv("bad", Config{}); err == nil {
+				t.Errorf("ConfigFromEnv() with %s=%s succeeded", key, value)
+			}
+		})
+	}
+}
+
+func TestNewNamedPool(t *testing.T) {
+	_, l := mockServer(t)
+	t.Setenv("GRPCPOOL_BILLING_TARGET", l.Addr().String())
+	t.Setenv("GRPCPOOL_BILLING_SIZE", "3")
+	pool, err := NewNamedPool(context.Background(), "billing", Config{Target: "localhost:1", Size: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	if pool.Num() != 3 || pool.Conns()[0].Endpoint().Addr != l.Addr().String() {
+		t.Errorf("pool has %d conns to %s; want 3 to %s", pool.Num(), pool.Conns()[0].Endpoint().Addr, l.Addr())
+	}
+}
@@ -0,0 +1,89 @@
+package grpcpool
+
+import (
+	"context"
+	"flag"
+	"strings"
+)
+
+// PoolFlags are the command-line flags configuring a pool, see FlagSet.
+type PoolFlags struct {
+	*flag.FlagSet
+	cfg Config
+}
+
+// FlagSet returns a flag set with the flags configuring the pool name, defaulting to DefaultConfig, so CLI tools
+// and batch jobs get consistent pool configuration:
+//
+//	-<name>-target, -<name>-targets, -<name>-pool-size, -<name>-dial-timeout, -<name>-call-timeout,
+//	-<name>-keepalive-time, -<name>-keepalive-timeout, -<name>-keepalive-permit-without-stream, -<name>-picker,
+//	-<name>-tls, -<name>-tls-server-name, -<name>-tls-ca-file, -<name>-tls-insecure-skip-verify,
+//	-<name>-concurrency-limit, -<name>-concurrency-queue, -<name>-max-send-msg-size, -<name>-max-recv-msg-size,
+//	-<name>-compression, -<name>-wait-for-ready and -<name>-service-config
+//
+// The flags mirror the fields of Config. Parse the flag set itself, or add its flags to another one, usually
+// flag.CommandLine, with AddTo, then create the pool with NewPool.
+func FlagSet(name string) *PoolFlags {
+	f := &PoolFlags{FlagSet: flag.NewFlagSet(name, flag.ContinueOnError), cfg: DefaultConfig()}
+	p, c := name+"-", &f.cfg
+	f.StringVar(&c.Target, p+"target", c.Target, "target the "+name+" pool dials")
+	f.Var((*listValue)(&c.Targets), p+"targets", "comma-separated endpoints the "+name+" pool dials instead of -"+p+"target")
+	f.UintVar(&c.Size, p+"pool-size", c.Size, "number of connections of the "+name+" pool")
+	f.DurationVar(&c.DialTimeout, p+"dial-timeout", c.DialTimeout, "how long to wait for the connections to be ready, 0 doesn't wait")
+	f.DurationVar(&c.CallTimeout, p+"call-timeout", c.CallTimeout, "deadline of unary calls made without one, 0 means none")
+	f.DurationVar(&c.KeepaliveTime, p+"keepalive-time", c.KeepaliveTime, "interval of keepalive pings, 0 uses the default")
+	f.DurationVar(&c.KeepaliveTimeout, p+"keepalive-timeout", c.KeepaliveTimeout, "how long to wait for keepalive ping acks")
+	f.BoolVar(&c.KeepalivePermitWithoutStream, p+"keepalive-permit-without-stream", c.KeepalivePermitWithoutStream, "send keepalive pings without active streams")
+	f.StringVar(&c.Picker, p+"picker", c.Picker, "name of the picker choosing connections")
+	f.BoolVar(&c.TLS, p+"tls", c.TLS, "dial with TLS")
+	f.StringVar(&c.TLSServerName, p+"tls-server-name", c.TLSServerName, "server name verified in the server certificate")
+	f.StringVar(&c.TLSCAFile, p+"tls-ca-file", c.TLSCAFile, "PEM file with the roots verifying the server certificate")
+	f.BoolVar(&c.TLSInsecureSkipVerify, p+"tls-insecure-skip-verify", c.TLSInsecureSkipVerify, "don't verify the server certificate")
+	f.IntVar(&c.ConcurrencyLimit, p+"concurrency-limit", c.ConcurrencyLimit, "limit of in-flight calls and open streams, 0 means none")
+	f.IntVar(&c.ConcurrencyQueue, p+"concurrency-queue", c.ConcurrencyQueue, "calls waiting for a slot under -"+p+"concurrency-limit")
+	f.IntVar(&c.MaxSendMsgSize, p+"max-send-msg-size", c.MaxSendMsgSize, "largest message sent in bytes, 0 keeps the gRPC default")
+	f.IntVar(&c.MaxRecvMsgSize, p+"max-recv-msg-size", c.MaxRecvMsgSize, "largest message received in bytes, 0 keeps the gRPC default")
+	f.StringVar(&c.Compression, p+"compression", c.Compression, "compressor of requests, e.g. gzip")
+	f.BoolVar(&c.WaitForReady, p+"wait-for-ready", c.WaitForReady, "make calls wait for their connection instead of failing fast")
+	f.StringVar(&c.ServiceConfig, p+"service-config", c.ServiceConfig, "gRPC service config JSON of the connections")
+	return f
+}
+
+// AddTo adds the flags of f to fs, e.g. flag.CommandLine.
+func (f *PoolFlags) AddTo(fs *flag.FlagSet) {
+	f.VisitAll(func(fl *flag.Flag) {
+		fs.Var(fl.Value, fl.Name, fl.Usage)
+	})
+}
+
+// Config returns the Config set by the flags.
+func (f *PoolFlags) Config() Config {
+	cfg := f.cfg
+	cfg.Targets = append([]string(nil), cfg.Targets...)
+	return cfg
+}
+
+// NewPool creates a new Pool from the flags, see NewFromConfig.
+func (f *PoolFlags) NewPool(ctx context.Context, opts ...Option) (*Pool, error) {
+	return NewFromConfig(ctx, f.Config(), opts...)
+}
+
+// listValue is a flag.Value of a comma-separated list.
+type listValue []string
+
+func (l *listValue) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *listValue) Set(s string) error {
+	*l = nil
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			*l = append(*l, e)
+		}
+	}
+	return nil
+}
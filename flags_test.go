@@ -0,0 +1,59 @@
+package grpcpool
+
+import (
+	"context"
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFlagSet(t *testing.T) {
+	f := FlagSet("orders")
+	err := f.Parse([]string{
+		"-orders-targets", "a:443,b:443",
+		"-orders-pool-size", "6",
+		"-orders-call-timeout", "3s",
+		"-orders-tls",
+		"-orders-compression", "gzip",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DefaultConfig()
+	want.Targets = []string{"a:443", "b:443"}
+	want.Size = 6
+	want.CallTimeout = 3 * time.Second
+	want.TLS = true
+	want.Compression = "gzip"
+	if got := f.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() got %+v; want %+v", got, want)
+	}
+	if got := FlagSet("other").Config(); !reflect.DeepEqual(got, DefaultConfig()) {
+		t.Errorf("Config() without flags got %+v; want DefaultConfig()", got)
+	}
+}
+
+func TestFlagSetAddTo(t *testing.T) {
+	_, l := mockServer(t)
+	fs := flag.NewFlagSet("tool", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	verbose := fs.Bool("v", false, "verbose")
+	f := FlagSet("users")
+	f.AddTo(fs)
+	if err := fs.Parse([]string{"-v", "-users-target", l.Addr().String(), "-users-pool-size", "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if !*verbose {
+		t.Error("flag of the tool not parsed")
+	}
+	pool, err := f.NewPool(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	if pool.Num() != 2 {
+		t.Errorf("pool.Num() got %d; want 2", pool.Num())
+	}
+}
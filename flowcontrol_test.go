@@ -0,0 +1,62 @@
+package grpcpool
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestFlowControlDialOptions(t *testing.T) {
+	for name, tc := range map[string]struct {
+		opts []Option
+		want int
+	}{
+		"default":         {nil, 0},
+		"high throughput": {[]Option{WithFlowControl(HighThroughputFlowControl)}, 4},
+		"windows only":    {[]Option{WithFlowControl(FlowControl{InitialWindowSize: 1 << 20})}, 1},
+	} {
+		o := newOptions(tc.opts)
+		if got := len(o.flowControlDialOptions()); got != tc.want {
+			t.Errorf("%s: got %d flow-control dial options; want %d", name, got, tc.want)
+		}
+		if got := len(o.dialOpts); got != tc.want+1 { // after the keepalive
+			t.Errorf("%s: got %d dial options; want %d", name, got, tc.want+1)
+		}
+	}
+}
+
+func TestFlowControlTransfer(t *testing.T) {
+	l := echoStreamServer(t)
+	for name, fc := range map[string]FlowControl{"high throughput": HighThroughputFlowControl, "bulk": BulkTransferFlowControl} {
+		pool, err := NewPool(context.Background(), l.Addr().String(),
+			WithFlowControl(fc),
+			WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cs, err := pool.NewStream(context.Background(), &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}, "/test.Echo/Upload")
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunk := bytes.Repeat([]byte{1}, 2<<20)
+		for i := 0; i < 4; i++ {
+			if err := cs.SendMsg(wrapperspb.Bytes(chunk)); err != nil {
+				t.Fatalf("%s: SendMsg() got %v", name, err)
+			}
+			got := &wrapperspb.BytesValue{}
+			if err := cs.RecvMsg(got); err != nil {
+				t.Fatalf("%s: RecvMsg() got %v", name, err)
+			}
+			if !bytes.Equal(got.Value, chunk) {
+				t.Fatalf("%s: echoed chunk of %d bytes; want %d", name, len(got.Value), len(chunk))
+			}
+		}
+		cs.CloseSend()
+		pool.Close()
+	}
+}
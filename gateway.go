@@ -0,0 +1,39 @@
+package grpcpool
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc"
+)
+
+// RegisterGateway registers grpc-gateway handlers on mux that call the backend through pool.
+//
+// newClient is the generated client constructor and register the generated RegisterXHandlerClient function, e.g.
+//
+//	grpcpool.RegisterGateway(ctx, mux, pool, pb.NewBillingClient, pb.RegisterBillingHandlerClient)
+//
+// so REST frontends share the pooled transport instead of dialing their own *grpc.ClientConn.
+func RegisterGateway[M, C any](ctx context.Context, mux M, pool ConnPool, newClient func(grpc.ClientConnInterface) C, register func(context.Context, M, C) error) error {
+	return register(ctx, mux, newClient(pool))
+}
+
+// GatewayHandler wraps a grpc-gateway mux, or any http.Handler calling through pool, with health-aware behavior
+// and per-route pool metrics.
+//
+// Requests fail with 503 Service Unavailable while pool has no healthy connection, instead of waiting for the
+// backend calls to fail. The calls made for a request are attributed to the label returned by route, which should
+// be the route template, e.g. "/v1/users/{id}", see Pool.LabelStats. With a nil route calls aren't labeled: request
+// paths aren't used as labels, as paths with IDs would make a label per resource.
+func GatewayHandler(pool *Pool, route func(*http.Request) string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !pool.Healthy() {
+			http.Error(w, "backend unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if route != nil {
+			r = r.WithContext(ContextWithCallLabel(r.Context(), route(r)))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
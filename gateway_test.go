@@ -0,0 +1,86 @@
+package grpcpool
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// registerHealthGateway stands in for a generated RegisterHealthHandlerClient.
+func registerHealthGateway(ctx context.Context, mux *http.ServeMux, client healthpb.HealthClient) error {
+	mux.HandleFunc("/v1/health", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := client.Check(r.Context(), &healthpb.HealthCheckRequest{}); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+		}
+	})
+	return nil
+}
+
+func TestGateway(t *testing.T) {
+	l := healthTestServer(t, "", healthpb.HealthCheckResponse_SERVING)
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	mux := http.NewServeMux()
+	if err := RegisterGateway(context.Background(), mux, pool, healthpb.NewHealthClient, registerHealthGateway); err != nil {
+		t.Fatal(err)
+	}
+	route := func(*http.Request) string { return "/v1/{check}" }
+	for _, h := range []http.Handler{GatewayHandler(pool, route, mux), GatewayHandler(pool, nil, mux)} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/health", nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("GET /v1/health got %d; want 200", rec.Code)
+		}
+	}
+	if got := pool.LabelStats("/v1/{check}"); got.Calls != 1 || got.Errors != 0 {
+		t.Errorf("pool.LabelStats(/v1/{check}) got %+v; want 1 call", got)
+	}
+	if labels := pool.Labels(); len(labels) != 1 {
+		t.Errorf("pool.Labels() got %q; want only the route label", labels)
+	}
+}
+
+func TestGatewayUnhealthy(t *testing.T) {
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	waitForState(t, pool.Conns()[0].ClientConn(), connectivity.TransientFailure)
+
+	called := false
+	h := GatewayHandler(pool, nil, http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called = true }))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/health", nil))
+	if rec.Code != http.StatusServiceUnavailable || called {
+		t.Errorf("request with an unhealthy pool got %d, handler called %v; want 503 without calling it", rec.Code, called)
+	}
+}
+
+func TestCallLabelsCap(t *testing.T) {
+	p := &Pool{}
+	for i := 0; i < MaxCallLabels+10; i++ {
+		p.labels.start(ContextWithCallLabel(context.Background(), "/v1/users/"+strconv.Itoa(i))).finish(nil)
+	}
+	if n := len(p.Labels()); n != MaxCallLabels+1 {
+		t.Errorf("got %d labels; want %d and %s", n, MaxCallLabels, OtherCallLabel)
+	}
+	if got := p.LabelStats(OtherCallLabel); got.Calls != 10 {
+		t.Errorf("LabelStats(%s) got %+v; want the 10 calls beyond the cap", OtherCallLabel, got)
+	}
+}
@@ -0,0 +1,85 @@
+package grpcpool
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// goAwayDrainTimeout bounds how long a connection replaced after a GOAWAY is drained before it is closed.
+const goAwayDrainTimeout = time.Minute
+
+type goAway struct {
+	ctx      context.Context // done once the pool is closed
+	replaced atomic.Int64
+}
+
+// WithGoAwayReplacement replaces connections whose transport the server closes, with a GOAWAY or by dropping it,
+// as soon as it happens instead of once calls start failing or waiting on the reconnect.
+//
+// The replacement is dialed and swapped in right away, so no new calls are picked onto the old connection, and the
+// old connection is closed once its calls and streams finish. Connections shared through a ConnCache aren't
+// replaced. It disables the idle timeout of the connections (see grpc.WithIdleTimeout), so a transport that closes
+// always means the server or the network closed it.
+func WithGoAwayReplacement() Option {
+	return func(o *options) {
+		o.goAway = &goAway{}
+		o.dialOpts = append(o.dialOpts, grpc.WithIdleTimeout(0))
+	}
+}
+
+// GoAwayReplacements returns the number of connections replaced after their transport closed, see
+// WithGoAwayReplacement.
+func (p *Pool) GoAwayReplacements() int64 {
+	if p.opts.goAway == nil {
+		return 0
+	}
+	return p.opts.goAway.replaced.Load()
+}
+
+// startGoAway enables the replacement of connections until stop is called.
+func (p *Pool) startGoAway() (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.opts.goAway.ctx = ctx
+	return cancel
+}
+
+// watchGoAway replaces c once its transport closes, if enabled.
+func (p *Pool) watchGoAway(c *PoolConn) {
+	if p.opts.goAway == nil || c.cache != nil {
+		return
+	}
+	go func() {
+		prev := c.cc.GetState()
+		for prev != connectivity.Shutdown && c.cc.WaitForStateChange(context.Background(), prev) {
+			state := c.cc.GetState()
+			if prev == connectivity.Ready && state == connectivity.Idle {
+				p.replaceGoAway(c)
+				return
+			}
+			prev = state
+		}
+	}()
+}
+
+// replaceGoAway replaces c if it is still in the pool.
+func (p *Pool) replaceGoAway(c *PoolConn) {
+	g := p.opts.goAway
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if g.ctx.Err() != nil {
+		return
+	}
+	s := p.set.Load()
+	if c.index >= len(s.conns) || s.conns[c.index] != c {
+		return
+	}
+	ctx, cancel := context.WithTimeout(g.ctx, goAwayDrainTimeout)
+	defer cancel()
+	if err := p.redial(ctx, []int{c.index}, false); err == nil || p.set.Load() != s {
+		g.replaced.Add(1)
+	}
+}
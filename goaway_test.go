@@ -0,0 +1,56 @@
+package grpcpool
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+)
+
+func TestGoAwayReplacement(t *testing.T) {
+	// The server sends a GOAWAY once a connection is 200ms old.
+	s := grpc.NewServer(grpc.KeepaliveParams(keepalive.ServerParameters{MaxConnectionAge: 200 * time.Millisecond}))
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(l)
+	defer s.Stop()
+
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(2),
+		WithGoAwayReplacement(),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pool.WaitForReady(ctx); err != nil {
+		t.Fatal(err)
+	}
+	old := pool.Conns()
+	for i, c := range old {
+		for pool.Conns()[i] == c {
+			if ctx.Err() != nil {
+				t.Fatalf("conn %d not replaced after a GOAWAY", i)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		waitForState(t, c.ClientConn(), connectivity.Shutdown)
+		if pool.Conns()[i].Index() != i {
+			t.Errorf("replacement of conn %d has index %d", i, pool.Conns()[i].Index())
+		}
+	}
+	if got := pool.GoAwayReplacements(); got < 2 {
+		t.Errorf("GoAwayReplacements() got %d; want at least 2", got)
+	}
+}
@@ -0,0 +1,54 @@
+package grpcpool
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// GoogleConnPool has the shape of the ConnPool interface of google.golang.org/api/transport/grpc used by
+// Google Cloud clients.
+//
+// Every ConnPool satisfies it, so pools of this package, with their own dialing logic, can be dropped into
+// integration points expecting a Google ConnPool.
+type GoogleConnPool interface {
+	Conn() *grpc.ClientConn
+	Num() int
+	Close() error
+	grpc.ClientConnInterface
+}
+
+var _ GoogleConnPool = ConnPool(nil)
+
+// ForGoogleClient adapts p for a Google Cloud client.
+//
+// Google clients close their connection pool when the client is closed. As p is usually shared, closing the
+// returned GoogleConnPool doesn't close p; it has to be closed by its owner.
+func ForGoogleClient(p ConnPool) GoogleConnPool {
+	return googleConnPool{p: p}
+}
+
+type googleConnPool struct {
+	p ConnPool
+}
+
+func (g googleConnPool) Conn() *grpc.ClientConn {
+	return g.p.Conn()
+}
+
+func (g googleConnPool) Num() int {
+	return g.p.Num()
+}
+
+// Close is a no-op, the adapted pool is closed by its owner.
+func (g googleConnPool) Close() error {
+	return nil
+}
+
+func (g googleConnPool) Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error {
+	return g.p.Invoke(ctx, method, args, reply, opts...)
+}
+
+func (g googleConnPool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return g.p.NewStream(ctx, desc, method, opts...)
+}
@@ -0,0 +1,29 @@
+package grpcpool
+
+import (
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+func TestForGoogleClient(t *testing.T) {
+	_, l := mockServer(t)
+	conn, err := grpc.Dial(l.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := New([]*grpc.ClientConn{conn})
+	defer pool.Close()
+
+	g := ForGoogleClient(pool)
+	if g.Num() != 1 || g.Conn() != conn {
+		t.Errorf("adapter got Num %d, Conn %v; want 1, %v", g.Num(), g.Conn(), conn)
+	}
+	if err := g.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if conn.GetState() == connectivity.Shutdown {
+		t.Error("closing the adapter closed the shared pool")
+	}
+}
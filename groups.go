@@ -0,0 +1,100 @@
+package grpcpool
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc"
+)
+
+// groupSpec reserves connections of a pool for a group.
+type groupSpec struct {
+	name     string
+	conns    int
+	dialOpts []grpc.DialOption // appended to the pool's dial options
+}
+
+// addGroup adds spec to the groups of the pool, replacing a group with the same name.
+func (o *options) addGroup(spec groupSpec) {
+	for i, g := range o.groups {
+		if g.name == spec.name {
+			o.groups[i] = spec
+			return
+		}
+	}
+	o.groups = append(o.groups, spec)
+}
+
+// validateGroups checks that the groups leave at least one connection of num for calls routed to no group.
+func (o *options) validateGroups(num int) error {
+	total := 0
+	for _, g := range o.groups {
+		if g.conns <= 0 {
+			return fmt.Errorf("grpcpool: group %q must have at least one conn", g.name)
+		}
+		total += g.conns
+	}
+	if total >= num {
+		return fmt.Errorf("grpcpool: groups have %d conns; the pool needs more than that, got %d", total, num)
+	}
+	return nil
+}
+
+// groupOf returns the group of the connection at index i of num.
+//
+// Groups are laid out at the end of the pool in the order they were added.
+func (o *options) groupOf(i, num int) string {
+	end := num
+	for j := len(o.groups) - 1; j >= 0; j-- {
+		g := o.groups[j]
+		if i >= end-g.conns {
+			return g.name
+		}
+		end -= g.conns
+	}
+	return ""
+}
+
+// groupDialOptions returns the dial options specific to the connections in group.
+func (o *options) groupDialOptions(group string) []grpc.DialOption {
+	for _, g := range o.groups {
+		if g.name == group {
+			return g.dialOpts
+		}
+	}
+	return nil
+}
+
+// CallStats are the call counters of a group of connections.
+type CallStats struct {
+	// Conns is the number of connections.
+	Conns int
+
+	// Calls is the number of finished calls and streams.
+	Calls int64
+
+	// Errors is the number of finished calls and streams that returned an error.
+	Errors int64
+
+	// InFlight is the number of in-flight calls and open streams.
+	InFlight int64
+
+	// Streams is the load of the open streams.
+	Streams StreamLoad
+}
+
+// GroupStats returns the call counters of the connections in group, e.g. CanaryGroup.
+//
+// The empty group are the connections not in any group.
+func (p *Pool) GroupStats(group string) CallStats {
+	var stats CallStats
+	if g, ok := p.set.Load().groups[group]; ok {
+		for _, c := range g.conns {
+			stats.Conns++
+			stats.Calls += c.calls.Load()
+			stats.Errors += c.errors.Load()
+			stats.InFlight += c.inflight.Load()
+			stats.Streams = stats.Streams.add(c.StreamLoad())
+		}
+	}
+	return stats
+}
@@ -0,0 +1,112 @@
+package grpcpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// DefaultStreamsPerConn is the HTTP/2 MaxConcurrentStreams assumed by WithStreamGrowth unless configured.
+// It is the limit of common proxies, e.g. Envoy and nginx, in front of gRPC servers.
+const DefaultStreamsPerConn = 100
+
+// StreamGrowthConfig configures stream-limit-aware growth of the pool, see WithStreamGrowth.
+type StreamGrowthConfig struct {
+	// StreamsPerConn is the server's HTTP/2 MaxConcurrentStreams. Zero uses DefaultStreamsPerConn.
+	StreamsPerConn int
+
+	// MaxConns is the number of connections the pool grows to at most.
+	MaxConns int
+}
+
+type growth struct {
+	StreamGrowthConfig
+	growing atomic.Bool // a connection is being added
+}
+
+// WithStreamGrowth adds connections to the pool, up to cfg.MaxConns, when its connections run out of streams.
+//
+// A connection can't carry more concurrent calls and streams than the server's MaxConcurrentStreams; calls beyond
+// that queue in the transport until a stream finishes. Once the in-flight calls and streams of the ungrouped
+// connections reach cfg.StreamsPerConn on average, the pool dials another connection to the endpoint with the
+// fewest connections, rather than relying on WithSize being guessed right for peak load.
+//
+// Num of a growing pool increases over time. Added connections are never in a group.
+func WithStreamGrowth(cfg StreamGrowthConfig) Option {
+	return func(o *options) {
+		if cfg.StreamsPerConn <= 0 {
+			cfg.StreamsPerConn = DefaultStreamsPerConn
+		}
+		o.growth = &growth{StreamGrowthConfig: cfg}
+	}
+}
+
+// checkGrowth adds a connection in the background if c, which just started a call, is out of streams.
+func (p *Pool) checkGrowth(c *PoolConn) {
+	g := p.opts.growth
+	if g == nil || c.inflight.Load() < int64(g.StreamsPerConn) || !g.growing.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer g.growing.Store(false)
+		p.grow()
+	}()
+}
+
+// grow adds a connection to the pool if its ungrouped connections are saturated.
+func (p *Pool) grow() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	old := p.set.Load()
+	g := p.opts.growth
+	if len(old.conns) >= g.MaxConns {
+		return
+	}
+	ungrouped, ok := old.groups[""]
+	if !ok {
+		return
+	}
+	var inflight int64
+	for _, c := range ungrouped.conns {
+		inflight += c.inflight.Load()
+	}
+	if inflight < int64(g.StreamsPerConn*len(ungrouped.conns)) {
+		return
+	}
+
+	if p.addConn(context.Background(), old) == nil {
+		p.saveSize()
+	}
+}
+
+// addConn adds an ungrouped connection to old, the current set of p, to the endpoint with the fewest ungrouped
+// connections, dialed with ctx. p.mu must be held.
+func (p *Pool) addConn(ctx context.Context, old *connSet) error {
+	if p.closed.Load() {
+		return errPoolClosed
+	}
+	ungrouped, ok := old.groups[""]
+	if !ok {
+		return errors.New("grpcpool: no ungrouped connection to add a connection next to")
+	}
+	perEndpoint := map[string]int{}
+	for _, c := range ungrouped.conns {
+		perEndpoint[c.endpoint.Addr]++
+	}
+	e := ungrouped.conns[0].endpoint
+	for _, c := range ungrouped.conns {
+		if perEndpoint[c.endpoint.Addr] < perEndpoint[e.Addr] {
+			e = c.endpoint
+		}
+	}
+	c := &PoolConn{endpoint: e, index: len(old.conns)}
+	if err := p.newConn(ctx, c); err != nil {
+		return err
+	}
+	c.cc.Connect()
+
+	conns := make([]*PoolConn, len(old.conns), len(old.conns)+1)
+	copy(conns, old.conns)
+	return p.storeGrown(old, p.newConnSet(append(conns, c)))
+}
@@ -0,0 +1,120 @@
+package grpcpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestStreamGrowth(t *testing.T) {
+	release := make(chan struct{})
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithStreamGrowth(StreamGrowthConfig{StreamsPerConn: 2, MaxConns: 3}),
+		WithDialOptions(
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithUnaryInterceptor(func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, grpc.UnaryInvoker, ...grpc.CallOption) error {
+				<-release
+				return nil
+			}),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	var wg sync.WaitGroup
+	start := func(n int) {
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				pool.Invoke(context.Background(), "/test.Test/Call", nil, nil)
+			}()
+		}
+	}
+	waitForNum := func(want int) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for pool.Num() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("pool.Num() got %d; want %d", pool.Num(), want)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	start(1)
+	time.Sleep(10 * time.Millisecond)
+	if n := pool.Num(); n != 1 {
+		t.Fatalf("pool grew to %d conns with streams left", n)
+	}
+	start(1)
+	waitForNum(2)
+	start(2)
+	waitForNum(3)
+	start(10)
+	time.Sleep(10 * time.Millisecond)
+	if n := pool.Num(); n != 3 {
+		t.Fatalf("pool grew to %d conns; want at most MaxConns 3", n)
+	}
+
+	if got := pool.set.Load().activeCalls(); got != 14 {
+		t.Errorf("active calls over the grown sets got %d; want 14", got)
+	}
+	close(release)
+	wg.Wait()
+	if got := pool.set.Load().activeCalls(); got != 0 {
+		t.Errorf("active calls after the calls finished got %d; want 0", got)
+	}
+	if pool.set.Load().prev.Load() != nil {
+		t.Error("drained sets the pool was grown from are still linked")
+	}
+}
+
+func TestGrowthAfterClose(t *testing.T) {
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithSize(2),
+		WithStreamGrowth(StreamGrowthConfig{StreamsPerConn: 1, MaxConns: 4}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := pool.set.Load()
+	pool.Close()
+
+	pool.mu.Lock()
+	err = pool.addConn(context.Background(), old)
+	pool.mu.Unlock()
+	if !errors.Is(err, errPoolClosed) {
+		t.Errorf("addConn() after Close got %v; want errPoolClosed", err)
+	}
+	if n := pool.Num(); n != 2 {
+		t.Errorf("pool.Num() after growing a closed pool got %d; want 2", n)
+	}
+
+	// A connection dialed while Close waited for the set is closed instead of stored.
+	c := &PoolConn{endpoint: old.conns[0].endpoint, index: len(old.conns)}
+	if err := pool.newConn(context.Background(), c); err != nil {
+		t.Fatal(err)
+	}
+	pool.mu.Lock()
+	err = pool.storeGrown(old, pool.newConnSet(append(old.conns[:len(old.conns):len(old.conns)], c)))
+	pool.mu.Unlock()
+	if !errors.Is(err, errPoolClosed) {
+		t.Errorf("storeGrown() after Close got %v; want errPoolClosed", err)
+	}
+	if pool.set.Load() != old {
+		t.Error("storeGrown() after Close replaced the set")
+	}
+	if state := c.State(); state != connectivity.Shutdown {
+		t.Errorf("state of the conn dialed after Close got %v; want SHUTDOWN", state)
+	}
+}
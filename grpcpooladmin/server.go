@@ -0,0 +1,148 @@
+// grpcpooladmin is an admin gRPC service inspecting and adjusting the pools of a service live, e.g. during
+// incidents. Register it on the internal server of the service, not on the one taking outside traffic:
+//
+//	admin := grpcpooladmin.NewServer(map[string]*grpcpool.Pool{"billing": billing})
+//	grpcpooladmin.RegisterPoolAdminServer(internal, admin)
+//
+// The service is defined in admin.proto.
+package grpcpooladmin
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/go-coldbrew/grpcpool"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements PoolAdminServer over a set of named pools.
+type Server struct {
+	UnimplementedPoolAdminServer
+
+	mu    sync.RWMutex
+	pools map[string]*grpcpool.Pool
+}
+
+// NewServer returns a Server administering pools by name.
+func NewServer(pools map[string]*grpcpool.Pool) *Server {
+	s := &Server{pools: make(map[string]*grpcpool.Pool, len(pools))}
+	for name, p := range pools {
+		s.pools[name] = p
+	}
+	return s
+}
+
+// Add adds or replaces the pool administered under name.
+func (s *Server) Add(name string, p *grpcpool.Pool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pools[name] = p
+}
+
+// Remove stops administering the pool under name.
+func (s *Server) Remove(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pools, name)
+}
+
+func (s *Server) pool(name string) (*grpcpool.Pool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.pools[name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "grpcpooladmin: no pool %q", name)
+	}
+	return p, nil
+}
+
+func (s *Server) ListPools(context.Context, *ListPoolsRequest) (*ListPoolsResponse, error) {
+	s.mu.RLock()
+	resp := &ListPoolsResponse{Pools: make([]*PoolSummary, 0, len(s.pools))}
+	for name, p := range s.pools {
+		summary := &PoolSummary{Name: name, Conns: int32(p.Num()), Healthy: p.Healthy()}
+		for _, c := range p.Conns() {
+			summary.InFlight += int64(c.InFlight())
+		}
+		resp.Pools = append(resp.Pools, summary)
+	}
+	s.mu.RUnlock()
+	sort.Slice(resp.Pools, func(i, j int) bool { return resp.Pools[i].Name < resp.Pools[j].Name })
+	return resp, nil
+}
+
+func (s *Server) GetStats(_ context.Context, req *GetStatsRequest) (*PoolStats, error) {
+	p, err := s.pool(req.Pool)
+	if err != nil {
+		return nil, err
+	}
+	state := p.DebugState()
+	stats := &PoolStats{Name: req.Pool, Sessions: state.Sessions, Conns: make([]*ConnStats, len(state.Conns))}
+	for i, c := range state.Conns {
+		stats.Conns[i] = &ConnStats{
+			Index:    int32(c.Index),
+			Addr:     c.Addr,
+			Group:    c.Group,
+			State:    c.State,
+			Calls:    c.Calls,
+			Errors:   c.Errors,
+			InFlight: c.InFlight,
+			Streams:  c.Streams,
+			Peer:     c.Peer,
+		}
+	}
+	return stats, nil
+}
+
+func (s *Server) Resize(ctx context.Context, req *ResizeRequest) (*ResizeResponse, error) {
+	p, err := s.pool(req.Pool)
+	if err != nil {
+		return nil, err
+	}
+	if req.Size < 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "grpcpooladmin: size %d is less than 1", req.Size)
+	}
+	if err := p.Resize(ctx, int(req.Size)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &ResizeResponse{Size: int32(p.Num())}, nil
+}
+
+func (s *Server) Drain(ctx context.Context, req *DrainRequest) (*DrainResponse, error) {
+	p, err := s.pool(req.Pool)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.Recycle(ctx); err != nil {
+		return nil, toStatus(err)
+	}
+	return &DrainResponse{}, nil
+}
+
+func (s *Server) ReplaceConn(ctx context.Context, req *ReplaceConnRequest) (*ReplaceConnResponse, error) {
+	p, err := s.pool(req.Pool)
+	if err != nil {
+		return nil, err
+	}
+	if req.Conn < 0 || int(req.Conn) >= p.Num() {
+		return nil, status.Errorf(codes.InvalidArgument, "grpcpooladmin: no connection %d in pool %q of %d", req.Conn, req.Pool, p.Num())
+	}
+	if err := p.ReplaceConn(ctx, int(req.Conn)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &ReplaceConnResponse{}, nil
+}
+
+// toStatus returns err of a pool operation as a status error.
+func toStatus(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.Unavailable, err.Error())
+}
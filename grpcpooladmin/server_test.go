@@ -0,0 +1,136 @@
+package grpcpooladmin
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-coldbrew/grpcpool"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func serve(t *testing.T, register func(*grpc.Server)) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	register(s)
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+	return l.Addr().String()
+}
+
+func newTestAdmin(t *testing.T) (PoolAdminClient, *grpcpool.Pool) {
+	t.Helper()
+	backend := serve(t, func(s *grpc.Server) { healthpb.RegisterHealthServer(s, health.NewServer()) })
+	pool, err := grpcpool.NewPool(context.Background(), backend,
+		grpcpool.WithSize(2),
+		grpcpool.WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pool.Close() })
+
+	admin := NewServer(map[string]*grpcpool.Pool{"billing": pool})
+	addr := serve(t, func(s *grpc.Server) { RegisterPoolAdminServer(s, admin) })
+	cc, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cc.Close() })
+	return NewPoolAdminClient(cc), pool
+}
+
+func TestServer(t *testing.T) {
+	client, pool := newTestAdmin(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := healthpb.NewHealthClient(pool).Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := client.ListPools(ctx, &ListPoolsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Pools) != 1 || list.Pools[0].Name != "billing" || list.Pools[0].Conns != 2 || !list.Pools[0].Healthy {
+		t.Errorf("ListPools() got %v; want the healthy billing pool of 2 conns", list.Pools)
+	}
+
+	stats, err := client.GetStats(ctx, &GetStatsRequest{Pool: "billing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls int64
+	for _, c := range stats.Conns {
+		calls += c.Calls
+	}
+	if len(stats.Conns) != 2 || calls != 1 {
+		t.Errorf("GetStats() got %d conns with %d calls; want 2 with 1", len(stats.Conns), calls)
+	}
+
+	resized, err := client.Resize(ctx, &ResizeRequest{Pool: "billing", Size: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resized.Size != 3 || pool.Num() != 3 {
+		t.Errorf("Resize() got size %d, pool.Num() %d; want 3", resized.Size, pool.Num())
+	}
+
+	old := pool.Conns()
+	if _, err := client.ReplaceConn(ctx, &ReplaceConnRequest{Pool: "billing", Conn: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if conns := pool.Conns(); conns[1] == old[1] || conns[0] != old[0] {
+		t.Error("ReplaceConn() didn't replace exactly conn 1")
+	}
+
+	old = pool.Conns()
+	if _, err := client.Drain(ctx, &DrainRequest{Pool: "billing"}); err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range pool.Conns() {
+		if c == old[i] {
+			t.Errorf("Drain() kept conn %d", i)
+		}
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	client, _ := newTestAdmin(t)
+	ctx := context.Background()
+	for name, tc := range map[string]struct {
+		call func() error
+		want codes.Code
+	}{
+		"unknown pool": {func() error {
+			_, err := client.GetStats(ctx, &GetStatsRequest{Pool: "nope"})
+			return err
+		}, codes.NotFound},
+		"zero size": {func() error {
+			_, err := client.Resize(ctx, &ResizeRequest{Pool: "billing"})
+			return err
+		}, codes.InvalidArgument},
+		"size above the maximum": {func() error {
+			_, err := client.Resize(ctx, &ResizeRequest{Pool: "billing", Size: grpcpool.DefaultMaxResize + 1})
+			return err
+		}, codes.InvalidArgument},
+		"missing conn": {func() error {
+			_, err := client.ReplaceConn(ctx, &ReplaceConnRequest{Pool: "billing", Conn: 7})
+			return err
+		}, codes.InvalidArgument},
+	} {
+		if got := status.Code(tc.call()); got != tc.want {
+			t.Errorf("%s: got %v; want %v", name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// grpcpoolreflect invokes methods of a gRPC server dynamically by name with JSON input and output, resolving
+// their descriptors through the server reflection service. It is a pooled grpcurl for admin tooling built on top
+// of an existing grpcpool.Pool.
+//
+//	c := grpcpoolreflect.NewClient(pool)
+//	out, err := c.Invoke(ctx, "grpc.health.v1.Health/Check", []byte(`{"service": "billing"}`))
+package grpcpoolreflect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Client invokes methods dynamically through a pool, or any grpc.ClientConnInterface.
+//
+// Method descriptors are fetched through server reflection on first use and cached. Client is safe for
+// concurrent use.
+type Client struct {
+	cc grpc.ClientConnInterface
+
+	mu      sync.Mutex
+	methods map[string]protoreflect.MethodDescriptor // by full method name
+}
+
+// NewClient returns a Client calling through cc.
+func NewClient(cc grpc.ClientConnInterface) *Client {
+	return &Client{cc: cc, methods: map[string]protoreflect.MethodDescriptor{}}
+}
+
+// ListServices returns the names of the services of the server.
+func (c *Client) ListServices(ctx context.Context) ([]string, error) {
+	resp, err := c.reflect(ctx, &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, s := range resp.GetListServicesResponse().GetService() {
+		names = append(names, s.GetName())
+	}
+	return names, nil
+}
+
+// Describe returns the descriptor of method, given as "package.Service/Method" with or without leading slash.
+func (c *Client) Describe(ctx context.Context, method string) (protoreflect.MethodDescriptor, error) {
+	method = strings.TrimPrefix(method, "/")
+	c.mu.Lock()
+	md, ok := c.methods[method]
+	c.mu.Unlock()
+	if ok {
+		return md, nil
+	}
+
+	service, name, ok := strings.Cut(method, "/")
+	if !ok {
+		return nil, fmt.Errorf("grpcpoolreflect: method %q isn't of the form package.Service/Method", method)
+	}
+	files, err := c.resolve(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	d, err := files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("grpcpoolreflect: service %s: %w", service, err)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("grpcpoolreflect: %s isn't a service", service)
+	}
+	md = sd.Methods().ByName(protoreflect.Name(name))
+	if md == nil {
+		return nil, fmt.Errorf("grpcpoolreflect: service %s has no method %s", service, name)
+	}
+	c.mu.Lock()
+	c.methods[method] = md
+	c.mu.Unlock()
+	return md, nil
+}
+
+// Invoke calls the unary method, given as for Describe, with the request in protojson format and returns the
+// response in protojson format.
+func (c *Client) Invoke(ctx context.Context, method string, input []byte, opts ...grpc.CallOption) ([]byte, error) {
+	md, err := c.Describe(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+	if md.IsStreamingClient() || md.IsStreamingServer() {
+		return nil, fmt.Errorf("grpcpoolreflect: %s is a streaming method", method)
+	}
+	req := dynamicpb.NewMessage(md.Input())
+	if len(input) > 0 {
+		if err := protojson.Unmarshal(input, req); err != nil {
+			return nil, fmt.Errorf("grpcpoolreflect: parsing request: %w", err)
+		}
+	}
+	resp := dynamicpb.NewMessage(md.Output())
+	fullMethod := "/" + string(md.Parent().FullName()) + "/" + string(md.Name())
+	if err := c.cc.Invoke(ctx, fullMethod, req, resp, opts...); err != nil {
+		return nil, err
+	}
+	return protojson.Marshal(resp)
+}
+
+// resolve returns the files defining symbol and their dependencies.
+func (c *Client) resolve(ctx context.Context, symbol string) (*protoregistry.Files, error) {
+	// Canceling ends the stream, which otherwise stays open on the pool after CloseSend.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := reflectionpb.NewServerReflectionClient(c.cc).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fds := map[string]*descriptorpb.FileDescriptorProto{}
+	add := func(resp *reflectionpb.ServerReflectionResponse) error {
+		if e := resp.GetErrorResponse(); e != nil {
+			return fmt.Errorf("grpcpoolreflect: reflection: %s", e.GetErrorMessage())
+		}
+		for _, b := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fd := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(b, fd); err != nil {
+				return fmt.Errorf("grpcpoolreflect: reflection: %w", err)
+			}
+			fds[fd.GetName()] = fd
+		}
+		return nil
+	}
+	req := &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+	}
+	for req != nil {
+		if err := stream.Send(req); err != nil {
+			return nil, err
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		if err := add(resp); err != nil {
+			return nil, err
+		}
+		req = nil
+		// Servers usually send the dependencies along, fetch the ones they didn't.
+		for _, fd := range fds {
+			for _, dep := range fd.GetDependency() {
+				if _, ok := fds[dep]; !ok {
+					req = &reflectionpb.ServerReflectionRequest{
+						MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+					}
+				}
+			}
+		}
+	}
+	if len(fds) == 0 {
+		return nil, errors.New("grpcpoolreflect: reflection returned no files")
+	}
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, fd := range fds {
+		set.File = append(set.File, fd)
+	}
+	return protodesc.NewFiles(set)
+}
+
+// reflect makes a single server reflection request.
+func (c *Client) reflect(ctx context.Context, req *reflectionpb.ServerReflectionRequest) (*reflectionpb.ServerReflectionResponse, error) {
+	// Canceling ends the stream, which otherwise stays open on the pool after CloseSend.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := reflectionpb.NewServerReflectionClient(c.cc).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(req); err != nil {
+		return nil, err
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if e := resp.GetErrorResponse(); e != nil {
+		return nil, fmt.Errorf("grpcpoolreflect: reflection: %s", e.GetErrorMessage())
+	}
+	return resp, nil
+}
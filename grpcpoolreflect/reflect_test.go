@@ -0,0 +1,139 @@
+package grpcpoolreflect
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-coldbrew/grpcpool"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+	return NewClient(newTestPool(t))
+}
+
+func newTestPool(t *testing.T) *grpcpool.Pool {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	hs := health.NewServer()
+	hs.SetServingStatus("billing", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(s, hs)
+	reflection.Register(s)
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+
+	pool, err := grpcpool.NewPool(context.Background(), l.Addr().String(),
+		grpcpool.WithSize(2),
+		grpcpool.WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pool.Close() })
+	return pool
+}
+
+func TestInvoke(t *testing.T) {
+	c := newTestClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for method, want := range map[string]string{
+		"grpc.health.v1.Health/Check":  "SERVING",
+		"/grpc.health.v1.Health/Check": "SERVING",
+	} {
+		out, err := c.Invoke(ctx, method, nil)
+		if err != nil {
+			t.Fatalf("Invoke(%s) got %v", method, err)
+		}
+		var resp struct{ Status string }
+		if err := json.Unmarshal(out, &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Status != want {
+			t.Errorf("Invoke(%s) status got %q; want %q", method, resp.Status, want)
+		}
+	}
+
+	out, err := c.Invoke(ctx, "grpc.health.v1.Health/Check", []byte(`{"service": "billing"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "NOT_SERVING") {
+		t.Errorf("Invoke(Check billing) got %s; want NOT_SERVING", out)
+	}
+}
+
+func TestInvokeErrors(t *testing.T) {
+	c := newTestClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for name, tc := range map[string]struct{ method, input string }{
+		"malformed method": {"grpc.health.v1.Health", ""},
+		"unknown service":  {"nope.Nope/Call", ""},
+		"unknown method":   {"grpc.health.v1.Health/Nope", ""},
+		"streaming method": {"grpc.health.v1.Health/Watch", ""},
+		"bad input":        {"grpc.health.v1.Health/Check", `{"nope": 1}`},
+	} {
+		if _, err := c.Invoke(ctx, tc.method, []byte(tc.input)); err == nil {
+			t.Errorf("%s: Invoke(%s) succeeded", name, tc.method)
+		}
+	}
+}
+
+func TestListServices(t *testing.T) {
+	c := newTestClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	got, err := c.ListServices(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+	want := []string{"grpc.health.v1.Health", "grpc.reflection.v1.ServerReflection", "grpc.reflection.v1alpha.ServerReflection"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("ListServices() got %v; want %v", got, want)
+	}
+}
+
+func TestStreamsEnd(t *testing.T) {
+	pool := newTestPool(t)
+	c := NewClient(pool)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := c.Invoke(ctx, "grpc.health.v1.Health/Check", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListServices(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(time.Second); pool.ActiveStreams() != 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if n := pool.ActiveStreams(); n != 0 {
+		t.Errorf("ActiveStreams() after reflection calls got %d; want 0", n)
+	}
+	sctx, scancel := context.WithTimeout(context.Background(), time.Second)
+	defer scancel()
+	if err := pool.Shutdown(sctx); err != nil {
+		t.Errorf("Shutdown() after reflection calls got %v; want nil", err)
+	}
+}
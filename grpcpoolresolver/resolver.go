@@ -0,0 +1,190 @@
+// grpcpoolresolver registers a "grpcpool" resolver and balancer with gRPC, so that an existing
+// grpc.Dial("grpcpool:///billing") call site is backed by a pool of connections registered under the name "billing",
+// without changing the code that owns the grpc.ClientConn.
+//
+// Importing the package registers the scheme. Every registered connection becomes a separate SubConn, and therefore
+// a separate HTTP/2 connection, of the single grpc.ClientConn, and calls are spread over the ready ones in
+// round-robin order.
+package grpcpoolresolver
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-coldbrew/grpcpool"
+	"google.golang.org/grpc/attributes"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/resolver"
+)
+
+// Scheme is the target scheme handled by the resolver.
+const Scheme = "grpcpool"
+
+// BalancerName is the name of the load balancing policy used for grpcpool targets.
+const BalancerName = "grpcpool"
+
+const serviceConfig = `{"loadBalancingConfig": [{"` + BalancerName + `": {}}]}`
+
+func init() {
+	resolver.Register(&builder{})
+	balancer.Register(base.NewBalancerBuilder(BalancerName, &pickerBuilder{}, base.Config{HealthCheck: true}))
+}
+
+// connIndexKey is the address attribute making the addresses of connections to the same endpoint distinct.
+type connIndexKey struct{}
+
+type entry struct {
+	addrs     []resolver.Address
+	version   uint64 // of addrs, so resolvers skip updates overtaken by later ones
+	resolvers map[*poolResolver]struct{}
+}
+
+// mu guards entries. It is never held while updating a grpc.ClientConn, so a slow update of one doesn't hold up
+// the others.
+var (
+	mu      sync.Mutex
+	entries = map[string]*entry{}
+)
+
+// Register makes grpc.Dial("grpcpool:///" + name) open size connections spread over endpoints in order.
+//
+// If size is zero, one connection per endpoint is opened. Registering a name again updates the connections of
+// every grpc.ClientConn already dialed to it.
+// Endpoint addresses are dialed directly and must be host:port addresses rather than gRPC targets.
+func Register(name string, size int, endpoints ...grpcpool.Endpoint) error {
+	if len(endpoints) == 0 {
+		return errors.New("grpcpoolresolver: no endpoints")
+	}
+	if size <= 0 {
+		size = len(endpoints)
+	}
+	addrs := make([]resolver.Address, size)
+	for i := range addrs {
+		addrs[i] = resolver.Address{
+			Addr:       endpoints[i%len(endpoints)].Addr,
+			Attributes: attributes.New(connIndexKey{}, i),
+		}
+	}
+
+	mu.Lock()
+	e, ok := entries[name]
+	if !ok {
+		e = &entry{resolvers: map[*poolResolver]struct{}{}}
+		entries[name] = e
+	}
+	e.addrs = addrs
+	e.version++
+	version := e.version
+	resolvers := make([]*poolResolver, 0, len(e.resolvers))
+	for r := range e.resolvers {
+		resolvers = append(resolvers, r)
+	}
+	mu.Unlock()
+
+	for _, r := range resolvers {
+		r.update(addrs, version)
+	}
+	return nil
+}
+
+// RegisterPool registers name with one connection to the endpoint of every connection in pool.
+func RegisterPool(name string, pool *grpcpool.Pool) error {
+	return Register(name, 0, pool.Endpoints()...)
+}
+
+// Unregister removes name. grpc.ClientConns already dialed to it keep their connections.
+func Unregister(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(entries, name)
+}
+
+// Target returns the target to dial for name.
+func Target(name string) string {
+	return Scheme + ":///" + name
+}
+
+type builder struct{}
+
+func (*builder) Scheme() string {
+	return Scheme
+}
+
+func (*builder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	name := target.Endpoint()
+
+	mu.Lock()
+	e, ok := entries[name]
+	if !ok {
+		mu.Unlock()
+		return nil, fmt.Errorf("grpcpoolresolver: %q is not registered", name)
+	}
+	r := &poolResolver{name: name, cc: cc}
+	e.resolvers[r] = struct{}{}
+	addrs, version := e.addrs, e.version
+	mu.Unlock()
+
+	r.update(addrs, version)
+	return r, nil
+}
+
+type poolResolver struct {
+	name string
+	cc   resolver.ClientConn
+
+	mu      sync.Mutex // serializes updates of cc
+	version uint64     // of the addresses cc was last updated with
+}
+
+// update updates cc with addrs, unless it was already updated with a later version.
+func (r *poolResolver) update(addrs []resolver.Address, version uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if version < r.version {
+		return
+	}
+	r.version = version
+	r.cc.UpdateState(resolver.State{
+		Addresses:     addrs,
+		ServiceConfig: r.cc.ParseServiceConfig(serviceConfig),
+	})
+}
+
+func (*poolResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+func (r *poolResolver) Close() {
+	mu.Lock()
+	defer mu.Unlock()
+	if e, ok := entries[r.name]; ok {
+		delete(e.resolvers, r)
+	}
+}
+
+type pickerBuilder struct{}
+
+func (*pickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	scs := make([]balancer.SubConn, 0, len(info.ReadySCs))
+	for sc := range info.ReadySCs {
+		scs = append(scs, sc)
+	}
+	return &picker{subConns: scs}
+}
+
+type picker struct {
+	// idx is 64-bit so it never wraps in practice: a wrapping 32-bit counter skews the rotation when the number
+	// of SubConns isn't a power of two, as 2^32 isn't a multiple of it.
+	idx uint64 // access via sync/atomic; first field to be 64-bit aligned on 32-bit platforms
+
+	subConns []balancer.SubConn
+}
+
+func (p *picker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	i := atomic.AddUint64(&p.idx, 1)
+	return balancer.PickResult{SubConn: p.subConns[i%uint64(len(p.subConns))]}, nil
+}
@@ -0,0 +1,162 @@
+package grpcpoolresolver
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-coldbrew/grpcpool"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/serviceconfig"
+)
+
+// countingListener counts accepted connections.
+type countingListener struct {
+	net.Listener
+	accepted int32
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		atomic.AddInt32(&l.accepted, 1)
+	}
+	return conn, err
+}
+
+func TestRegister(t *testing.T) {
+	inner, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := &countingListener{Listener: inner}
+	s := grpc.NewServer()
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	go s.Serve(l)
+	defer s.Stop()
+
+	if err := Register("billing", 3, grpcpool.Endpoint{Addr: inner.Addr().String()}); err != nil {
+		t.Fatal(err)
+	}
+	defer Unregister("billing")
+
+	cc, err := grpc.Dial(Target("billing"), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client := healthpb.NewHealthClient(cc)
+	for i := 0; i < 10; i++ {
+		if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.WaitForReady(true)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for atomic.LoadInt32(&l.accepted) < 3 {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("server accepted %d connections; want 3", atomic.LoadInt32(&l.accepted))
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func TestUnregistered(t *testing.T) {
+	cc, err := grpc.Dial(Target("unknown"), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err == nil {
+		cc.Close()
+		t.Fatal("grpc.Dial of an unregistered name succeeded")
+	}
+}
+
+type fakeSubConn struct {
+	balancer.SubConn
+	id int
+}
+
+func TestPickerWraparound(t *testing.T) {
+	scs := []balancer.SubConn{&fakeSubConn{id: 0}, &fakeSubConn{id: 1}, &fakeSubConn{id: 2}}
+	// Past 2^32 picks, where a 32-bit counter wraps and 2^32%3 != 0 repeated a SubConn.
+	p := &picker{subConns: scs, idx: math.MaxUint32 - 2}
+	prev, _ := p.Pick(balancer.PickInfo{})
+	for i := 0; i < 6; i++ {
+		got, _ := p.Pick(balancer.PickInfo{})
+		if got.SubConn == prev.SubConn {
+			t.Fatalf("pick #%d repeated the previous SubConn", i)
+		}
+		prev = got
+	}
+}
+
+// blockingClientConn is a resolver.ClientConn whose updates block until release is closed.
+type blockingClientConn struct {
+	resolver.ClientConn
+	updating chan struct{}
+	release  chan struct{}
+	states   chan resolver.State
+}
+
+func (cc *blockingClientConn) UpdateState(s resolver.State) error {
+	cc.updating <- struct{}{}
+	<-cc.release
+	cc.states <- s
+	return nil
+}
+
+func (*blockingClientConn) ParseServiceConfig(string) *serviceconfig.ParseResult {
+	return nil
+}
+
+func TestSlowUpdate(t *testing.T) {
+	defer Unregister("slow")
+	defer Unregister("other")
+	if err := Register("slow", 1, grpcpool.Endpoint{Addr: "localhost:1"}); err != nil {
+		t.Fatal(err)
+	}
+	cc := &blockingClientConn{updating: make(chan struct{}, 1), release: make(chan struct{}), states: make(chan resolver.State, 2)}
+	built := make(chan resolver.Resolver, 1)
+	go func() {
+		r, err := (&builder{}).Build(resolver.Target{URL: url.URL{Scheme: Scheme, Path: "/slow"}}, cc, resolver.BuildOptions{})
+		if err != nil {
+			t.Error(err)
+		}
+		built <- r
+	}()
+	<-cc.updating
+
+	done := make(chan error, 1)
+	go func() { done <- Register("other", 1, grpcpool.Endpoint{Addr: "localhost:2"}) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Register of another name waited for a slow ClientConn update")
+	}
+
+	// A later update of the slow ClientConn isn't overtaken by the first one.
+	go Register("slow", 2, grpcpool.Endpoint{Addr: "localhost:1"})
+	close(cc.release)
+	r := <-built
+	defer r.Close()
+	<-cc.updating
+	if s := <-cc.states; len(s.Addresses) != 1 {
+		t.Errorf("first update got %d addresses; want 1", len(s.Addresses))
+	}
+	if s := <-cc.states; len(s.Addresses) != 2 {
+		t.Errorf("second update got %d addresses; want 2", len(s.Addresses))
+	}
+}
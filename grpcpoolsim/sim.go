@@ -0,0 +1,331 @@
+// grpcpoolsim replays synthetic workloads against a grpcpool.Pool without a network, to compare pickers and
+// other pool options before rolling them out.
+//
+// The simulated pool is a real Pool whose connections never connect: interceptors answer every call and stream
+// after a latency drawn from the backend's distribution, and fail them during the backend's failure bursts. Time
+// is real, so latencies should be kept short, e.g. in milliseconds.
+//
+//	results, err := grpcpoolsim.Compare(ctx, backends, workload, map[string]grpcpool.Picker{
+//		"round_robin": grpcpool.RoundRobin(),
+//		"least_inflight": leastInFlight,
+//	})
+package grpcpoolsim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-coldbrew/grpcpool"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Distribution draws a latency from r.
+type Distribution func(r *rand.Rand) time.Duration
+
+// Constant returns a Distribution that is always d.
+func Constant(d time.Duration) Distribution {
+	return func(*rand.Rand) time.Duration { return d }
+}
+
+// Uniform returns a Distribution uniform between min and max.
+func Uniform(min, max time.Duration) Distribution {
+	return func(r *rand.Rand) time.Duration {
+		if max <= min {
+			return min
+		}
+		return min + time.Duration(r.Int63n(int64(max-min)))
+	}
+}
+
+// Exponential returns an exponential Distribution with the given mean, with the long tail of real backends.
+func Exponential(mean time.Duration) Distribution {
+	return func(r *rand.Rand) time.Duration {
+		return time.Duration(r.ExpFloat64() * float64(mean))
+	}
+}
+
+// Burst is a period, relative to the start of the simulation, during which a backend fails every call.
+type Burst struct {
+	After time.Duration
+	For   time.Duration
+}
+
+// Backend describes a simulated backend. The pool has one connection per backend, unless grpcpool.WithSize is
+// given.
+type Backend struct {
+	// Latency is the latency of calls and of stream creation. The default is no latency.
+	Latency Distribution
+
+	// ErrorRate is the fraction, from 0 to 1, of calls and streams failing with codes.Unavailable.
+	ErrorRate float64
+
+	// Failures are the failure bursts of the backend.
+	Failures []Burst
+}
+
+// Workload describes the calls of a simulation.
+type Workload struct {
+	// Calls is the number of unary calls and streams.
+	Calls int
+
+	// Concurrency is the number of goroutines making calls. The default is one.
+	Concurrency int
+
+	// StreamFraction is the fraction, from 0 to 1, of the calls that are streams.
+	StreamFraction float64
+
+	// StreamDuration is how long streams stay open after creation.
+	StreamDuration time.Duration
+
+	// Seed seeds the random choices of the simulation.
+	Seed int64
+}
+
+// BackendResult are the results of a backend.
+type BackendResult struct {
+	Calls       int64
+	Errors      int64
+	MaxInFlight int64
+}
+
+// Result are the results of a simulation.
+type Result struct {
+	Calls, Errors int
+	Elapsed       time.Duration
+
+	// P50, P99 and Max are the latency percentiles of the calls, including the time spent in the pool.
+	P50, P99, Max time.Duration
+
+	// Backends are the results per backend, by index.
+	Backends []BackendResult
+}
+
+// String summarizes r on one line.
+func (r Result) String() string {
+	s := fmt.Sprintf("calls=%d errors=%d p50=%v p99=%v max=%v per-backend=[", r.Calls, r.Errors, r.P50, r.P99, r.Max)
+	for i, b := range r.Backends {
+		if i > 0 {
+			s += " "
+		}
+		s += fmt.Sprint(b.Calls)
+	}
+	return s + "]"
+}
+
+const method = "/grpcpoolsim.Sim/Call"
+
+// backend is the state of a simulated backend.
+type backend struct {
+	Backend
+	start time.Time
+
+	mu sync.Mutex
+	r  *rand.Rand
+
+	inflight, maxInFlight atomic.Int64
+}
+
+// serve simulates a call or stream creation on the backend.
+func (b *backend) serve(ctx context.Context) error {
+	b.mu.Lock()
+	var d time.Duration
+	if b.Latency != nil {
+		d = b.Latency(b.r)
+	}
+	fail := b.ErrorRate > 0 && b.r.Float64() < b.ErrorRate
+	b.mu.Unlock()
+
+	n := b.inflight.Add(1)
+	defer b.inflight.Add(-1)
+	for {
+		peak := b.maxInFlight.Load()
+		if n <= peak || b.maxInFlight.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	if err := sleep(ctx, d); err != nil {
+		return err
+	}
+	since := time.Since(b.start)
+	for _, f := range b.Failures {
+		if since >= f.After && since < f.After+f.For {
+			fail = true
+		}
+	}
+	if fail {
+		return status.Error(codes.Unavailable, "grpcpoolsim: simulated failure")
+	}
+	return nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// Run runs workload against a pool of the simulated backends, created with opts.
+func Run(ctx context.Context, backends []Backend, workload Workload, opts ...grpcpool.Option) (Result, error) {
+	if len(backends) == 0 {
+		return Result{}, errors.New("grpcpoolsim: no backends")
+	}
+	start := time.Now()
+	byTarget := map[string]int{}
+	sims := make([]*backend, len(backends))
+	endpoints := make([]grpcpool.Endpoint, len(backends))
+	for i, b := range backends {
+		sims[i] = &backend{Backend: b, start: start, r: rand.New(rand.NewSource(workload.Seed + int64(i) + 1))}
+		endpoints[i] = grpcpool.Endpoint{Addr: fmt.Sprintf("passthrough:///backend-%d", i)}
+		byTarget[endpoints[i].Addr] = i
+	}
+
+	unary := func(ctx context.Context, _ string, _, _ interface{}, cc *grpc.ClientConn, _ grpc.UnaryInvoker, _ ...grpc.CallOption) error {
+		return sims[byTarget[cc.Target()]].serve(ctx)
+	}
+	stream := func(ctx context.Context, _ *grpc.StreamDesc, cc *grpc.ClientConn, _ string, _ grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if err := sims[byTarget[cc.Target()]].serve(ctx); err != nil {
+			return nil, err
+		}
+		s := &simStream{ctx: ctx, d: workload.StreamDuration}
+		for _, o := range opts {
+			if f, ok := o.(grpc.OnFinishCallOption); ok {
+				s.onFinish = append(s.onFinish, f.OnFinish)
+			}
+		}
+		return s, nil
+	}
+	opts = append([]grpcpool.Option{grpcpool.WithDialOptions(
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(unary),
+		grpc.WithStreamInterceptor(stream),
+	)}, opts...)
+	pool, err := grpcpool.NewEndpointPool(ctx, endpoints, opts...)
+	if err != nil {
+		return Result{}, err
+	}
+	defer pool.Close()
+
+	concurrency := workload.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	// The simulated backends answer in interceptors and are never dialed, so their connections go into
+	// TRANSIENT_FAILURE. Waiting for ready keeps the pool from taking simulated failures for unsent calls and
+	// retrying them on other backends.
+	callOpts := []grpc.CallOption{grpc.WaitForReady(true)}
+	var (
+		next      atomic.Int64
+		mu        sync.Mutex
+		latencies []time.Duration
+		errs      int
+		wg        sync.WaitGroup
+	)
+	for w := 0; w < concurrency; w++ {
+		r := rand.New(rand.NewSource(workload.Seed - int64(w)))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for next.Add(1) <= int64(workload.Calls) && ctx.Err() == nil {
+				t := time.Now()
+				var err error
+				if workload.StreamFraction > 0 && r.Float64() < workload.StreamFraction {
+					var cs grpc.ClientStream
+					if cs, err = pool.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, method, callOpts...); err == nil {
+						if err = cs.RecvMsg(nil); err == io.EOF {
+							err = nil
+						}
+					}
+				} else {
+					err = pool.Invoke(ctx, method, nil, nil, callOpts...)
+				}
+				d := time.Since(t)
+				mu.Lock()
+				latencies = append(latencies, d)
+				if err != nil {
+					errs++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	res := Result{Calls: len(latencies), Errors: errs, Elapsed: time.Since(start), Backends: make([]BackendResult, len(backends))}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	if n := len(latencies); n > 0 {
+		res.P50 = latencies[n/2]
+		res.P99 = latencies[(n*99)/100]
+		res.Max = latencies[n-1]
+	}
+	for _, c := range pool.Conns() {
+		i := byTarget[c.Endpoint().Addr]
+		st := c.Stats()
+		res.Backends[i].Calls += st.Calls
+		res.Backends[i].Errors += st.Errors
+	}
+	for i, b := range sims {
+		res.Backends[i].MaxInFlight = b.maxInFlight.Load()
+	}
+	return res, ctx.Err()
+}
+
+// Compare runs workload against the simulated backends once per picker, sequentially, and returns the results
+// by picker name. opts are applied before the picker.
+func Compare(ctx context.Context, backends []Backend, workload Workload, pickers map[string]grpcpool.Picker, opts ...grpcpool.Option) (map[string]Result, error) {
+	results := make(map[string]Result, len(pickers))
+	for name, picker := range pickers {
+		res, err := Run(ctx, backends, workload, append(opts[:len(opts):len(opts)], grpcpool.WithPicker(picker))...)
+		if err != nil {
+			return nil, fmt.Errorf("grpcpoolsim: %s: %w", name, err)
+		}
+		results[name] = res
+	}
+	return results, nil
+}
+
+// simStream is a stream open for d after creation.
+type simStream struct {
+	ctx      context.Context
+	d        time.Duration
+	onFinish []func(error)
+	once     sync.Once
+}
+
+func (s *simStream) Header() (metadata.MD, error) { return nil, nil }
+func (s *simStream) Trailer() metadata.MD         { return nil }
+func (s *simStream) CloseSend() error             { return nil }
+func (s *simStream) Context() context.Context     { return s.ctx }
+func (s *simStream) SendMsg(interface{}) error    { return nil }
+
+// RecvMsg blocks until the stream ends, returning io.EOF.
+func (s *simStream) RecvMsg(interface{}) error {
+	err := sleep(s.ctx, s.d)
+	s.once.Do(func() {
+		for _, f := range s.onFinish {
+			f(err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return io.EOF
+}
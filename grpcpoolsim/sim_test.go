@@ -0,0 +1,62 @@
+package grpcpoolsim
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-coldbrew/grpcpool"
+)
+
+// leastInFlight picks the connection with the fewest in-flight calls.
+var leastInFlight = grpcpool.PickerFunc(func(_ grpcpool.PickInfo, conns []*grpcpool.PoolConn) *grpcpool.PoolConn {
+	best := conns[0]
+	for _, c := range conns[1:] {
+		if c.InFlight() < best.InFlight() {
+			best = c
+		}
+	}
+	return best
+})
+
+func TestCompare(t *testing.T) {
+	backends := []Backend{
+		{Latency: Constant(10 * time.Millisecond)},
+		{Latency: Uniform(0, time.Millisecond)},
+	}
+	workload := Workload{Calls: 200, Concurrency: 4, StreamFraction: 0.25, StreamDuration: time.Millisecond, Seed: 1}
+	results, err := Compare(context.Background(), backends, workload, map[string]grpcpool.Picker{
+		"round_robin":    grpcpool.RoundRobin(),
+		"least_inflight": leastInFlight,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr, lif := results["round_robin"], results["least_inflight"]
+	for name, res := range results {
+		t.Logf("%s: %v", name, res)
+		if res.Calls != 200 || res.Errors != 0 {
+			t.Errorf("%s: got %d calls, %d errors; want 200 calls, none failed", name, res.Calls, res.Errors)
+		}
+	}
+	if d := rr.Backends[0].Calls - rr.Backends[1].Calls; d < -1 || d > 1 {
+		t.Errorf("round_robin per-backend calls got %v; want an even split", rr)
+	}
+	if lif.Backends[1].Calls <= 2*lif.Backends[0].Calls {
+		t.Errorf("least_inflight per-backend calls got %v; want most calls on the fast backend", lif)
+	}
+}
+
+func TestFailures(t *testing.T) {
+	res, err := Run(context.Background(), []Backend{
+		{Failures: []Burst{{For: time.Hour}}},
+		{ErrorRate: 0},
+	}, Workload{Calls: 10}, grpcpool.WithPicker(grpcpool.RoundRobinFrom(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Errors != 5 || res.Backends[0].Errors != 5 || res.Backends[1].Errors != 0 {
+		t.Errorf("Run with a failing backend got %v errors, per backend %+v; want 5, all on backend 0", res.Errors, res.Backends)
+	}
+}
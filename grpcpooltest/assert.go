@@ -0,0 +1,57 @@
+package grpcpooltest
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/go-coldbrew/grpcpool"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// callsPerConn is the number of calls per connection made by AssertBalancedWithin.
+const callsPerConn = 100
+
+// Distribution makes n calls with call, sequentially, and returns the number of them handled by each connection
+// of pool, by connection index. Failed calls count too.
+func Distribution(pool *grpcpool.Pool, n int, call func(context.Context) error) []int64 {
+	conns := pool.Conns()
+	before := make([]int64, len(conns))
+	for i, c := range conns {
+		before[i] = c.Stats().Calls
+	}
+	for i := 0; i < n; i++ {
+		call(context.Background())
+	}
+	dist := make([]int64, len(conns))
+	for i, c := range conns {
+		dist[i] = c.Stats().Calls - before[i]
+	}
+	return dist
+}
+
+// AssertBalancedWithin makes 100 calls per connection of pool and fails t unless every connection handled an even
+// share of them within tolerance, a fraction of the even share, e.g. 0.1 for ±10%.
+//
+// The calls are made to a method no server implements, so it works with any server, see NewTestPool.
+// Use AssertBalancedCalls to drive specific calls.
+func AssertBalancedWithin(t testing.TB, pool *grpcpool.Pool, tolerance float64) {
+	t.Helper()
+	AssertBalancedCalls(t, pool, tolerance, callsPerConn*pool.Num(), func(ctx context.Context) error {
+		return pool.Invoke(ctx, "/grpcpooltest.Balance/Call", &emptypb.Empty{}, &emptypb.Empty{})
+	})
+}
+
+// AssertBalancedCalls makes n calls with call and fails t unless every connection of pool handled an even share
+// of them within tolerance, see AssertBalancedWithin.
+func AssertBalancedCalls(t testing.TB, pool *grpcpool.Pool, tolerance float64, n int, call func(context.Context) error) {
+	t.Helper()
+	dist := Distribution(pool, n, call)
+	even := float64(n) / float64(len(dist))
+	for i, calls := range dist {
+		if math.Abs(float64(calls)-even) > tolerance*even {
+			t.Errorf("grpcpooltest: conn #%d handled %d of %d calls; want %.0f ±%.0f%%, distribution %v",
+				i, calls, n, even, tolerance*100, dist)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package grpcpooltest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-coldbrew/grpcpool"
+)
+
+// recordingTB records failures instead of failing the test.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertBalancedWithin(t *testing.T) {
+	AssertBalancedWithin(t, NewTestPool(t, nil, 3), 0.01)
+}
+
+func TestAssertBalancedUnbalanced(t *testing.T) {
+	pool := NewTestPool(t, nil, 2, grpcpool.WithPicker(grpcpool.PickerFunc(func(_ grpcpool.PickInfo, conns []*grpcpool.PoolConn) *grpcpool.PoolConn {
+		return conns[0]
+	})))
+	r := &recordingTB{TB: t}
+	AssertBalancedWithin(r, pool, 0.1)
+	if len(r.errors) != 2 || !strings.Contains(r.errors[0], "conn #0 handled 200 of 200 calls") {
+		t.Errorf("AssertBalancedWithin on a pool using only conn #0 reported %q", r.errors)
+	}
+}
+
+func TestDistribution(t *testing.T) {
+	pool := NewTestPool(t, nil, 2, grpcpool.WithPicker(grpcpool.RoundRobinFrom(0)))
+	dist := Distribution(pool, 3, func(ctx context.Context) error {
+		return pool.Invoke(ctx, "/test.Test/Call", nil, nil)
+	})
+	if len(dist) != 2 || dist[0] != 2 || dist[1] != 1 {
+		t.Errorf("Distribution got %v; want [2 1]", dist)
+	}
+}
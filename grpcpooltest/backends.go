@@ -0,0 +1,161 @@
+package grpcpooltest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-coldbrew/grpcpool"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Backend is an in-process gRPC server started by NewBackends, whose behavior can be changed while the test runs.
+type Backend struct {
+	// Index is the index of the backend, and of its connection in the pool.
+	Index int
+
+	// Server is the gRPC server of the backend.
+	Server *grpc.Server
+
+	calls atomic.Int64
+
+	mu     sync.Mutex
+	paused chan struct{} // closed on Resume, nil if not paused
+	code   codes.Code
+	delay  time.Duration
+}
+
+// Pause holds calls and new streams on the backend until Resume or until their context is done.
+func (b *Backend) Pause() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.paused == nil {
+		b.paused = make(chan struct{})
+	}
+}
+
+// Resume releases the calls held by Pause.
+func (b *Backend) Resume() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.paused != nil {
+		close(b.paused)
+		b.paused = nil
+	}
+}
+
+// Fail makes calls and new streams on the backend fail with code. codes.OK stops the failures.
+func (b *Backend) Fail(code codes.Code) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.code = code
+}
+
+// Delay delays calls and new streams on the backend by d. Zero stops the delay.
+func (b *Backend) Delay(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.delay = d
+}
+
+// Calls returns the number of calls and streams the backend received.
+func (b *Backend) Calls() int64 {
+	return b.calls.Load()
+}
+
+// Stop stops the server of the backend, closing its connections.
+func (b *Backend) Stop() {
+	b.Resume()
+	b.Server.Stop()
+}
+
+// intercept applies the controls of the backend to a call or stream.
+func (b *Backend) intercept(ctx context.Context) error {
+	b.calls.Add(1)
+	b.mu.Lock()
+	paused, code, delay := b.paused, b.code, b.delay
+	b.mu.Unlock()
+	if paused != nil {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-paused:
+		}
+	}
+	if delay > 0 {
+		t := time.NewTimer(delay)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-t.C:
+		}
+	}
+	if code != codes.OK {
+		return status.Errorf(code, "grpcpooltest: backend %d fails", b.Index)
+	}
+	return nil
+}
+
+// NewBackends starts n independent in-process gRPC servers, with the services registered by register, and returns
+// them with a Pool with one connection to each, e.g. to test multi-target, failover and outlier ejection features.
+// The pool's connection #i is connected to backend #i.
+//
+// opts are applied after the options wiring the pool to the servers. The servers and pool are stopped when the
+// test finishes.
+func NewBackends(t testing.TB, n int, register func(*grpc.Server), opts ...grpcpool.Option) (*grpcpool.Pool, []*Backend) {
+	t.Helper()
+
+	backends := make([]*Backend, n)
+	listeners := map[string]*bufconn.Listener{}
+	endpoints := make([]grpcpool.Endpoint, n)
+	for i := range backends {
+		b := &Backend{Index: i}
+		b.Server = grpc.NewServer(
+			grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+				if err := b.intercept(ctx); err != nil {
+					return nil, err
+				}
+				return handler(ctx, req)
+			}),
+			grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+				if err := b.intercept(ss.Context()); err != nil {
+					return err
+				}
+				return handler(srv, ss)
+			}),
+		)
+		if register != nil {
+			register(b.Server)
+		}
+		l := bufconn.Listen(bufSize)
+		go b.Server.Serve(l)
+		t.Cleanup(b.Stop)
+
+		addr := fmt.Sprintf("backend-%d", i)
+		listeners[addr] = l
+		endpoints[i] = grpcpool.Endpoint{Addr: "passthrough:///" + addr}
+		backends[i] = b
+	}
+
+	opts = append([]grpcpool.Option{
+		grpcpool.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return listeners[addr].DialContext(ctx)
+		}),
+		grpcpool.WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	}, opts...)
+	p, err := grpcpool.NewEndpointPool(context.Background(), endpoints, opts...)
+	if err != nil {
+		t.Fatalf("grpcpooltest: %v", err)
+	}
+	t.Cleanup(func() { p.Close() })
+	return p, backends
+}
@@ -0,0 +1,79 @@
+package grpcpooltest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-coldbrew/grpcpool"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func registerHealth(s *grpc.Server) {
+	healthpb.RegisterHealthServer(s, health.NewServer())
+}
+
+func TestNewBackends(t *testing.T) {
+	pool, backends := NewBackends(t, 3, registerHealth, grpcpool.WithPicker(grpcpool.RoundRobinFrom(0)))
+	client := healthpb.NewHealthClient(pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, b := range backends {
+		if b.Calls() != 1 {
+			t.Errorf("backend #%d got %d calls; want 1", i, b.Calls())
+		}
+	}
+
+	backends[1].Fail(codes.Unavailable)
+	for i, want := range []codes.Code{codes.OK, codes.Unavailable, codes.OK} {
+		if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); status.Code(err) != want {
+			t.Errorf("call on backend #%d got %v; want %v", i, err, want)
+		}
+	}
+	backends[1].Fail(codes.OK)
+
+	backends[0].Delay(20 * time.Millisecond)
+	start := time.Now()
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 20*time.Millisecond {
+		t.Errorf("delayed call took %v; want at least 20ms", d)
+	}
+}
+
+func TestBackendPause(t *testing.T) {
+	pool, backends := NewBackends(t, 1, registerHealth)
+	client := healthpb.NewHealthClient(pool)
+
+	backends[0].Pause()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("call on a paused backend got %v; want DeadlineExceeded", err)
+	}
+
+	before := backends[0].Calls()
+	done := make(chan error)
+	go func() {
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		done <- err
+	}()
+	for backends[0].Calls() == before {
+		time.Sleep(time.Millisecond)
+	}
+	backends[0].Resume()
+	if err := <-done; err != nil {
+		t.Errorf("call after Resume got %v", err)
+	}
+}
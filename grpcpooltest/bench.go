@@ -0,0 +1,93 @@
+package grpcpooltest
+
+import (
+	"context"
+	"runtime"
+	"runtime/metrics"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-coldbrew/grpcpool"
+)
+
+// mutexWaitMetric is the runtime metric of the time goroutines spent blocked on sync.Mutex and sync.RWMutex.
+const mutexWaitMetric = "/sync/mutex/wait/total:seconds"
+
+// BenchConfig configures Bench.
+type BenchConfig struct {
+	// Goroutines is the number of goroutines making calls. The default is GOMAXPROCS.
+	Goroutines int
+
+	// Call makes one call on the pool. The default calls pool.Conn, measuring the picker alone.
+	Call func(ctx context.Context, pool grpcpool.ConnPool) error
+}
+
+// Bench is a benchmark body hammering pool from cfg.Goroutines goroutines, b.N calls in total, to validate picker
+// and counter designs on the hardware at hand:
+//
+//	func BenchmarkPool(b *testing.B) {
+//		pool := grpcpooltest.NewTestPool(b, register, 4)
+//		for _, g := range []int{1, 8, 64} {
+//			b.Run(fmt.Sprint(g), func(b *testing.B) {
+//				grpcpooltest.Bench(b, pool, grpcpooltest.BenchConfig{Goroutines: g})
+//			})
+//		}
+//	}
+//
+// Besides the time and allocations per call, it reports the throughput in calls/s and the mutex contention in
+// mutex-wait-ns/op, where the runtime supports it. Failed calls fail the benchmark.
+func Bench(b *testing.B, pool grpcpool.ConnPool, cfg BenchConfig) {
+	goroutines := cfg.Goroutines
+	if goroutines <= 0 {
+		goroutines = runtime.GOMAXPROCS(0)
+	}
+	call := cfg.Call
+	if call == nil {
+		call = func(context.Context, grpcpool.ConnPool) error {
+			pool.Conn()
+			return nil
+		}
+	}
+
+	ctx := context.Background()
+	b.ReportAllocs()
+	wait := mutexWait()
+	b.ResetTimer()
+	start := time.Now()
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		n := b.N / goroutines
+		if g < b.N%goroutines {
+			n++
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				if err := call(ctx, pool); err != nil {
+					b.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+	b.StopTimer()
+
+	b.ReportMetric(float64(b.N)/elapsed.Seconds(), "calls/s")
+	if w := mutexWait(); w >= 0 && wait >= 0 {
+		b.ReportMetric((w-wait)*1e9/float64(b.N), "mutex-wait-ns/op")
+	}
+}
+
+// mutexWait returns the total mutex wait time of the process in seconds, or -1 if the runtime doesn't track it.
+func mutexWait() float64 {
+	s := []metrics.Sample{{Name: mutexWaitMetric}}
+	metrics.Read(s)
+	if s[0].Value.Kind() != metrics.KindFloat64 {
+		return -1
+	}
+	return s[0].Value.Float64()
+}
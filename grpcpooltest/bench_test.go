@@ -0,0 +1,45 @@
+package grpcpooltest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-coldbrew/grpcpool"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestBench(t *testing.T) {
+	pool := NewTestPool(t, nil, 2)
+	res := testing.Benchmark(func(b *testing.B) {
+		Bench(b, pool, BenchConfig{Goroutines: 3})
+	})
+	if res.N == 0 || res.Extra["calls/s"] <= 0 {
+		t.Errorf("Bench result got %v; want calls with a throughput", res)
+	}
+	if calls := pool.Conns()[0].Stats().Calls; calls != 0 {
+		t.Errorf("Bench with the default Call made %d calls; want only picks", calls)
+	}
+}
+
+func BenchmarkConn(b *testing.B) {
+	pool := NewTestPool(b, nil, 4)
+	for _, g := range []int{1, 8, 64} {
+		b.Run(fmt.Sprint(g), func(b *testing.B) {
+			Bench(b, pool, BenchConfig{Goroutines: g})
+		})
+	}
+}
+
+func BenchmarkInvoke(b *testing.B) {
+	pool := NewTestPool(b, registerHealth, 4)
+	call := func(ctx context.Context, pool grpcpool.ConnPool) error {
+		_, err := healthpb.NewHealthClient(pool).Check(ctx, &healthpb.HealthCheckRequest{})
+		return err
+	}
+	for _, g := range []int{1, 8, 64} {
+		b.Run(fmt.Sprint(g), func(b *testing.B) {
+			Bench(b, pool, BenchConfig{Goroutines: g, Call: call})
+		})
+	}
+}
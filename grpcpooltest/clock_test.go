@@ -0,0 +1,79 @@
+package grpcpooltest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-coldbrew/grpcpool"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Unix(0, 0)
+	c := NewFakeClock(start)
+	timer := c.NewTimer(time.Second)
+	ticker := c.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	c.Advance(999 * time.Millisecond)
+	select {
+	case <-timer.C():
+		t.Fatal("timer fired early")
+	case <-ticker.C():
+		t.Fatal("ticker fired early")
+	default:
+	}
+
+	c.Advance(time.Millisecond)
+	if got := <-timer.C(); !got.Equal(start.Add(time.Second)) {
+		t.Errorf("timer fired at %v; want %v", got, start.Add(time.Second))
+	}
+	<-ticker.C()
+	if timer.Stop() {
+		t.Error("timer.Stop() after firing got true")
+	}
+	if c.Waiters() != 1 {
+		t.Errorf("c.Waiters() got %d; want the ticker only", c.Waiters())
+	}
+
+	c.Advance(2 * time.Second)
+	<-ticker.C()
+	if !c.Now().Equal(start.Add(3 * time.Second)) {
+		t.Errorf("c.Now() got %v; want %v", c.Now(), start.Add(3*time.Second))
+	}
+}
+
+func TestFakeClockReportHealth(t *testing.T) {
+	c := NewFakeClock(time.Now())
+	pool := NewTestPool(t, nil, 1, grpcpool.WithClock(c))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := pool.WaitForReady(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := health.NewServer()
+	grpcpool.ReportHealth(ctx, srv, "billing", pool, time.Minute)
+	pool.Close()
+
+	check := func() healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := srv.Check(ctx, &healthpb.HealthCheckRequest{Service: "billing"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.Status
+	}
+	if got := check(); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("status before the first tick got %v; want SERVING", got)
+	}
+	c.Advance(time.Minute)
+	deadline := time.Now().Add(5 * time.Second)
+	for check() != healthpb.HealthCheckResponse_NOT_SERVING {
+		if time.Now().After(deadline) {
+			t.Fatal("status never became NOT_SERVING after the tick")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
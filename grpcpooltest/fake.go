@@ -0,0 +1,280 @@
+package grpcpooltest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/go-coldbrew/grpcpool"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// UnaryHandler scripts the response to a unary call on a FakePool. It may also make assertions on the request.
+type UnaryHandler func(ctx context.Context, req proto.Message) (proto.Message, error)
+
+// StreamHandler scripts the server side of a stream on a FakePool. The stream ends with the returned error.
+type StreamHandler func(s *ServerStream) error
+
+// Respond returns a UnaryHandler responding with resp.
+func Respond(resp proto.Message) UnaryHandler {
+	return func(context.Context, proto.Message) (proto.Message, error) {
+		return resp, nil
+	}
+}
+
+// Fail returns a UnaryHandler failing with err, e.g. a status.Error.
+func Fail(err error) UnaryHandler {
+	return func(context.Context, proto.Message) (proto.Message, error) {
+		return nil, err
+	}
+}
+
+// Delay returns a UnaryHandler calling h after d, or failing with the context error if the call's context is done
+// first.
+func Delay(d time.Duration, h UnaryHandler) UnaryHandler {
+	return func(ctx context.Context, req proto.Message) (proto.Message, error) {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		case <-t.C:
+		}
+		return h(ctx, req)
+	}
+}
+
+// Call is a call or stream made on a FakePool.
+type Call struct {
+	Method string
+
+	// Request is a copy of the request of a unary call, nil for streams.
+	Request proto.Message
+}
+
+// FakePool is a grpcpool.ConnPool whose calls and streams are scripted per method, for unit tests of code using a
+// ConnPool without a gRPC server. Calls of methods without a handler fail with codes.Unimplemented.
+//
+// Conn returns nil, as there is no real connection. FakePool is safe for concurrent use.
+type FakePool struct {
+	mu      sync.Mutex
+	unary   map[string]UnaryHandler
+	streams map[string]StreamHandler
+	calls   []Call
+	closed  bool
+}
+
+var _ grpcpool.ConnPool = (*FakePool)(nil)
+
+// NewFakePool returns a FakePool without handlers.
+func NewFakePool() *FakePool {
+	return &FakePool{unary: map[string]UnaryHandler{}, streams: map[string]StreamHandler{}}
+}
+
+// HandleUnary sets the handler of calls of the full method name method, e.g. "/grpc.health.v1.Health/Check".
+func (f *FakePool) HandleUnary(method string, h UnaryHandler) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.unary[method] = h
+}
+
+// HandleStream sets the handler of streams of the full method name method.
+func (f *FakePool) HandleStream(method string, h StreamHandler) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.streams[method] = h
+}
+
+// Calls returns the calls and streams made so far, in order.
+func (f *FakePool) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// Conn returns nil.
+func (f *FakePool) Conn() *grpc.ClientConn {
+	return nil
+}
+
+// Num returns 1.
+func (f *FakePool) Num() int {
+	return 1
+}
+
+// Close makes later calls and streams fail with codes.Canceled.
+func (f *FakePool) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+// start records a call and returns whether the pool is open.
+func (f *FakePool) start(method string, req proto.Message) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return false
+	}
+	if req != nil {
+		req = proto.Clone(req)
+	}
+	f.calls = append(f.calls, Call{Method: method, Request: req})
+	return true
+}
+
+func (f *FakePool) Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error {
+	req, ok := args.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "grpcpooltest: request %T isn't a proto.Message", args)
+	}
+	out, ok := reply.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "grpcpooltest: reply %T isn't a proto.Message", reply)
+	}
+	if !f.start(method, req) {
+		return status.Error(codes.Canceled, "grpcpooltest: pool is closed")
+	}
+	f.mu.Lock()
+	h := f.unary[method]
+	f.mu.Unlock()
+	if h == nil {
+		return status.Errorf(codes.Unimplemented, "grpcpooltest: no handler for %s", method)
+	}
+	if err := ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	resp, err := h(ctx, req)
+	if err != nil {
+		return err
+	}
+	proto.Reset(out)
+	if resp != nil {
+		proto.Merge(out, resp)
+	}
+	return nil
+}
+
+func (f *FakePool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if !f.start(method, nil) {
+		return nil, status.Error(codes.Canceled, "grpcpooltest: pool is closed")
+	}
+	f.mu.Lock()
+	h := f.streams[method]
+	f.mu.Unlock()
+	if h == nil {
+		return nil, status.Errorf(codes.Unimplemented, "grpcpooltest: no handler for %s", method)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s := &ServerStream{ctx: ctx, in: make(chan proto.Message), out: make(chan proto.Message)}
+	cs := &fakeClientStream{s: s, done: make(chan struct{})}
+	go func() {
+		defer cancel()
+		cs.err = h(s)
+		close(cs.done)
+	}()
+	return cs, nil
+}
+
+// ServerStream is the server side of a stream on a FakePool.
+type ServerStream struct {
+	ctx context.Context
+	in  chan proto.Message // closed by CloseSend
+	out chan proto.Message
+}
+
+// Context returns the context of the stream.
+func (s *ServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// Recv receives the next message sent by the client into m. It returns io.EOF once the client called CloseSend.
+func (s *ServerStream) Recv(m proto.Message) error {
+	select {
+	case <-s.ctx.Done():
+		return status.FromContextError(s.ctx.Err()).Err()
+	case msg, ok := <-s.in:
+		if !ok {
+			return io.EOF
+		}
+		proto.Reset(m)
+		proto.Merge(m, msg)
+		return nil
+	}
+}
+
+// Send sends m to the client, blocking until the client receives it.
+func (s *ServerStream) Send(m proto.Message) error {
+	select {
+	case <-s.ctx.Done():
+		return status.FromContextError(s.ctx.Err()).Err()
+	case s.out <- proto.Clone(m):
+		return nil
+	}
+}
+
+type fakeClientStream struct {
+	s    *ServerStream
+	done chan struct{} // closed when the handler returned
+	err  error         // returned by the handler
+
+	mu         sync.Mutex
+	sendClosed bool
+}
+
+func (cs *fakeClientStream) Header() (metadata.MD, error) { return nil, nil }
+func (cs *fakeClientStream) Trailer() metadata.MD         { return nil }
+func (cs *fakeClientStream) Context() context.Context     { return cs.s.ctx }
+
+func (cs *fakeClientStream) CloseSend() error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if !cs.sendClosed {
+		cs.sendClosed = true
+		close(cs.s.in)
+	}
+	return nil
+}
+
+func (cs *fakeClientStream) SendMsg(m interface{}) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "grpcpooltest: message %T isn't a proto.Message", m)
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.sendClosed {
+		return fmt.Errorf("grpcpooltest: SendMsg after CloseSend")
+	}
+	select {
+	case <-cs.done:
+		return io.EOF
+	case cs.s.in <- proto.Clone(msg):
+		return nil
+	}
+}
+
+func (cs *fakeClientStream) RecvMsg(m interface{}) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "grpcpooltest: message %T isn't a proto.Message", m)
+	}
+	select {
+	case out := <-cs.s.out:
+		proto.Reset(msg)
+		proto.Merge(msg, out)
+		return nil
+	case <-cs.done:
+		if cs.err != nil {
+			return cs.err
+		}
+		return io.EOF
+	}
+}
@@ -0,0 +1,101 @@
+package grpcpooltest
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestFakePoolUnary(t *testing.T) {
+	f := NewFakePool()
+	f.HandleUnary("/grpc.health.v1.Health/Check", func(ctx context.Context, req proto.Message) (proto.Message, error) {
+		if svc := req.(*healthpb.HealthCheckRequest).Service; svc != "billing" {
+			t.Errorf("request service got %q; want billing", svc)
+		}
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+	})
+	client := healthpb.NewHealthClient(f)
+
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "billing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("Check status got %v; want SERVING", resp.Status)
+	}
+	calls := f.Calls()
+	if len(calls) != 1 || calls[0].Method != "/grpc.health.v1.Health/Check" || calls[0].Request.(*healthpb.HealthCheckRequest).Service != "billing" {
+		t.Errorf("f.Calls() got %v; want the Check call", calls)
+	}
+
+	f.HandleUnary("/grpc.health.v1.Health/Check", Fail(status.Error(codes.Unavailable, "down")))
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); status.Code(err) != codes.Unavailable {
+		t.Errorf("failing Check got %v; want Unavailable", err)
+	}
+
+	f.HandleUnary("/grpc.health.v1.Health/Check", Delay(time.Second, Respond(&healthpb.HealthCheckResponse{})))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("delayed Check got %v; want DeadlineExceeded", err)
+	}
+
+	f.Close()
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); status.Code(err) != codes.Canceled {
+		t.Errorf("Check after Close got %v; want Canceled", err)
+	}
+}
+
+func TestFakePoolUnimplemented(t *testing.T) {
+	client := healthpb.NewHealthClient(NewFakePool())
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Check without handler got %v; want Unimplemented", err)
+	}
+	if _, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Watch without handler got %v; want Unimplemented", err)
+	}
+}
+
+func TestFakePoolStream(t *testing.T) {
+	f := NewFakePool()
+	f.HandleStream("/grpc.health.v1.Health/Watch", func(s *ServerStream) error {
+		var req healthpb.HealthCheckRequest
+		if err := s.Recv(&req); err != nil {
+			return err
+		}
+		if req.Service != "billing" {
+			t.Errorf("request service got %q; want billing", req.Service)
+		}
+		for _, st := range []healthpb.HealthCheckResponse_ServingStatus{healthpb.HealthCheckResponse_SERVING, healthpb.HealthCheckResponse_NOT_SERVING} {
+			if err := s.Send(&healthpb.HealthCheckResponse{Status: st}); err != nil {
+				return err
+			}
+		}
+		return status.Error(codes.Unavailable, "gone")
+	})
+
+	stream, err := healthpb.NewHealthClient(f).Watch(context.Background(), &healthpb.HealthCheckRequest{Service: "billing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []healthpb.HealthCheckResponse_ServingStatus
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if status.Code(err) != codes.Unavailable || err == io.EOF {
+				t.Errorf("stream end got %v; want Unavailable", err)
+			}
+			break
+		}
+		got = append(got, resp.Status)
+	}
+	if len(got) != 2 || got[0] != healthpb.HealthCheckResponse_SERVING || got[1] != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("stream responses got %v; want SERVING, NOT_SERVING", got)
+	}
+}
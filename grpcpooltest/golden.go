@@ -0,0 +1,43 @@
+package grpcpooltest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// UpdateEnv is the environment variable making AssertGolden write golden files instead of comparing with them,
+// e.g. GRPCPOOLTEST_UPDATE=1 go test ./...
+const UpdateEnv = "GRPCPOOLTEST_UPDATE"
+
+// GoldenState is a pool state snapshot, a grpcpool.DebugState or grpcpool.TenantDebugState.
+type GoldenState interface {
+	MarshalGolden() ([]byte, error)
+}
+
+// AssertGolden fails t unless the serialized state equals the contents of the golden file at path, e.g. to
+// snapshot pool state transitions after evictions or resizes. With UpdateEnv set, it writes the file instead.
+func AssertGolden(t testing.TB, path string, state GoldenState) {
+	t.Helper()
+	got, err := state.MarshalGolden()
+	if err != nil {
+		t.Fatalf("grpcpooltest: %v", err)
+	}
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("grpcpooltest: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("grpcpooltest: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("grpcpooltest: %v; run with %s=1 to create it", err, UpdateEnv)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("grpcpooltest: state differs from %s; run with %s=1 to update it\ngot:\n%s\nwant:\n%s", path, UpdateEnv, got, want)
+	}
+}
@@ -0,0 +1,34 @@
+package grpcpooltest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-coldbrew/grpcpool"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestAssertGolden(t *testing.T) {
+	pool, _ := NewBackends(t, 2, registerHealth,
+		grpcpool.WithPicker(grpcpool.RoundRobinFrom(0)),
+		grpcpool.WithStreamConns(1),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pool.WaitForReady(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := healthpb.NewHealthClient(pool).Check(grpcpool.ContextWithCallLabel(ctx, "/v1/health"), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	AssertGolden(t, "testdata/debugstate.golden", pool.DebugState())
+}
+
+func TestAssertGoldenMismatch(t *testing.T) {
+	r := &recordingTB{TB: t}
+	AssertGolden(r, "testdata/debugstate.golden", grpcpool.DebugState{Version: grpcpool.DebugStateVersion})
+	if len(r.errors) != 1 {
+		t.Errorf("AssertGolden with a different state reported %q; want one error", r.errors)
+	}
+}
@@ -0,0 +1,48 @@
+// grpcpooltest provides helpers for tests of code using a grpcpool.Pool.
+package grpcpooltest
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/go-coldbrew/grpcpool"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// bufSize is the buffer size of the in-process connections.
+const bufSize = 1 << 20
+
+// NewTestPool starts an in-process gRPC server, with the services registered by register, and returns a Pool with
+// num connections to it. The connections use bufconn instead of sockets, but are otherwise real, so tests
+// exercise the pooling behavior.
+//
+// opts are applied after the options wiring the pool to the server. The server and pool are stopped when the
+// test finishes.
+func NewTestPool(t testing.TB, register func(*grpc.Server), num int, opts ...grpcpool.Option) *grpcpool.Pool {
+	t.Helper()
+
+	l := bufconn.Listen(bufSize)
+	s := grpc.NewServer()
+	if register != nil {
+		register(s)
+	}
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+
+	opts = append([]grpcpool.Option{
+		grpcpool.WithSize(uint(num)),
+		grpcpool.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return l.DialContext(ctx)
+		}),
+		grpcpool.WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	}, opts...)
+	p, err := grpcpool.NewPool(context.Background(), "passthrough:///bufnet", opts...)
+	if err != nil {
+		t.Fatalf("grpcpooltest: %v", err)
+	}
+	t.Cleanup(func() { p.Close() })
+	return p
+}
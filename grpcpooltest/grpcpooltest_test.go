@@ -0,0 +1,35 @@
+package grpcpooltest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestNewTestPool(t *testing.T) {
+	pool := NewTestPool(t, func(s *grpc.Server) {
+		healthpb.RegisterHealthServer(s, health.NewServer())
+	}, 3)
+	if pool.Num() != 3 {
+		t.Errorf("pool.Num() got %d; want 3", pool.Num())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client := healthpb.NewHealthClient(pool)
+	for i := 0; i < 6; i++ {
+		if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, c := range pool.Conns() {
+		if state := c.State(); state != connectivity.Ready {
+			t.Errorf("conn #%d state got %v; want READY", i, state)
+		}
+	}
+}
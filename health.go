@@ -0,0 +1,48 @@
+package grpcpool
+
+import (
+	"context"
+	"time"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// HealthSetter is the part of *health.Server of google.golang.org/grpc/health used to report pool health.
+type HealthSetter interface {
+	SetServingStatus(service string, servingStatus healthpb.HealthCheckResponse_ServingStatus)
+}
+
+// ReportHealth feeds the health of p into the health server of the hosting service under service, e.g. the name
+// of the dependency, until ctx is done. The service is SERVING while p has a healthy connection, see Pool.Healthy,
+// and NOT_SERVING otherwise.
+//
+// The status is set before ReportHealth returns and then refreshed every interval, by default every second.
+func ReportHealth(ctx context.Context, srv HealthSetter, service string, p *Pool, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	status := p.healthStatus()
+	srv.SetServingStatus(service, status)
+	t := p.opts.clock.NewTicker(interval)
+	go func() {
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C():
+				if s := p.healthStatus(); s != status {
+					status = s
+					srv.SetServingStatus(service, status)
+				}
+			}
+		}
+	}()
+}
+
+func (p *Pool) healthStatus() healthpb.HealthCheckResponse_ServingStatus {
+	if p.Healthy() {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
@@ -0,0 +1,51 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestReportHealth(t *testing.T) {
+	_, l := mockServer(t)
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(2),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := pool.WaitForReady(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := health.NewServer()
+	ReportHealth(ctx, srv, "billing", pool, 10*time.Millisecond)
+	check := func() healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := srv.Check(ctx, &healthpb.HealthCheckRequest{Service: "billing"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.Status
+	}
+	if got := check(); got != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("status with a ready pool got %v; want SERVING", got)
+	}
+
+	pool.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for check() != healthpb.HealthCheckResponse_NOT_SERVING {
+		if time.Now().After(deadline) {
+			t.Fatal("status never became NOT_SERVING after the pool closed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
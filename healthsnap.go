@@ -0,0 +1,69 @@
+package grpcpool
+
+import (
+	"context"
+
+	"google.golang.org/grpc/connectivity"
+)
+
+// The health-aware features, WithLocality and WithPriorityFailover, pick from snapshots of the healthy connections
+// of every tier. The snapshots are replaced copy-on-write by a monitor watching the connectivity state of the
+// connections, so picks only load an atomic.Pointer and never block on health bookkeeping.
+
+// healthyConns returns the healthy connections of t.
+func (t *tier) healthyConns() []*PoolConn {
+	if h := t.healthy.Load(); h != nil {
+		return *h
+	}
+	return filterHealthy(t.conns)
+}
+
+// healthyLocal returns the healthy local connections of t.
+func (t *tier) healthyLocal() []*PoolConn {
+	if h := t.healthyLoc.Load(); h != nil {
+		return *h
+	}
+	return filterHealthy(t.local)
+}
+
+// refresh replaces the snapshots of t.
+func (t *tier) refresh() {
+	healthy := filterHealthy(t.conns)
+	t.healthy.Store(&healthy)
+	local := filterHealthy(t.local)
+	t.healthyLoc.Store(&local)
+}
+
+// watchHealth starts maintaining the healthy snapshots of the tiers of s until s is closed.
+func (s *connSet) watchHealth() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopWatch = cancel
+	s.refreshHealth()
+	for _, c := range s.conns {
+		go s.watch(ctx, c)
+	}
+}
+
+// watch refreshes the healthy snapshots of s on every connectivity state change of c.
+func (s *connSet) watch(ctx context.Context, c *PoolConn) {
+	state := c.cc.GetState()
+	s.refreshHealth() // the state may have changed since watchHealth
+	for state != connectivity.Shutdown && c.cc.WaitForStateChange(ctx, state) {
+		state = c.cc.GetState()
+		s.refreshHealth()
+	}
+}
+
+// refreshHealth replaces the healthy snapshots of every tier of s.
+func (s *connSet) refreshHealth() {
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+	for _, t := range s.all.tiers {
+		t.refresh()
+	}
+	for _, g := range s.groups {
+		for _, t := range g.tiers {
+			t.refresh()
+		}
+	}
+}
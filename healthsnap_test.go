@@ -0,0 +1,61 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// waitForSnapshot waits for the healthy snapshots of p to catch up with the connectivity states of its conns.
+func waitForSnapshot(t *testing.T, p *Pool) {
+	t.Helper()
+
+	s := p.set.Load()
+	current := func() bool {
+		for _, t := range s.all.tiers {
+			if len(t.healthyConns()) != len(filterHealthy(t.conns)) || len(t.healthyLocal()) != len(filterHealthy(t.local)) {
+				return false
+			}
+		}
+		return true
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !current() {
+		if time.Now().After(deadline) {
+			t.Fatal("healthy snapshots never caught up with the conn states")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHealthSnapshot(t *testing.T) {
+	_, l := mockServer(t)
+	pool, err := NewEndpointPool(context.Background(),
+		[]Endpoint{{Addr: "localhost:1"}, {Addr: l.Addr().String()}},
+		WithPriorityFailover(1),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tier := pool.set.Load().all.tiers[0]
+	if tier.healthy.Load() == nil {
+		t.Fatal("pool with failover has no healthy snapshot")
+	}
+	waitForState(t, pool.Conns()[0].ClientConn(), connectivity.TransientFailure)
+	waitForSnapshot(t, pool)
+	if healthy := tier.healthyConns(); len(healthy) != 1 || healthy[0] != pool.Conns()[1] {
+		t.Errorf("healthy snapshot got %v; want the reachable conn", healthy)
+	}
+
+	pool.Close()
+	waitForSnapshot(t, pool)
+	if healthy := tier.healthyConns(); len(healthy) != 0 {
+		t.Errorf("healthy snapshot after Close got %d conns; want none", len(healthy))
+	}
+}
@@ -0,0 +1,26 @@
+package grpcpool
+
+import (
+	"google.golang.org/grpc"
+)
+
+// WithInterceptorChain chains the given unary and stream client interceptors on every connection the pool dials,
+// so they run for calls through the pool's Invoke and NewStream as well as through ClientConns obtained from Conn.
+//
+// The pool installs no interceptors of its own and doesn't depend on github.com/go-coldbrew/interceptors. To give
+// pooled clients the telemetry of non-pooled ones in go-coldbrew services, pass its standard chain explicitly:
+//
+//	grpcpool.WithInterceptorChain(
+//		interceptors.DefaultClientInterceptors(),
+//		interceptors.DefaultClientStreamInterceptors(),
+//	)
+func WithInterceptorChain(unary []grpc.UnaryClientInterceptor, stream []grpc.StreamClientInterceptor) Option {
+	var dialOpts []grpc.DialOption
+	if len(unary) > 0 {
+		dialOpts = append(dialOpts, grpc.WithChainUnaryInterceptor(unary...))
+	}
+	if len(stream) > 0 {
+		dialOpts = append(dialOpts, grpc.WithChainStreamInterceptor(stream...))
+	}
+	return WithDialOptions(dialOpts...)
+}
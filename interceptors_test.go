@@ -0,0 +1,54 @@
+package grpcpool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestInterceptorChain(t *testing.T) {
+	l := healthTestServer(t, "", healthpb.HealthCheckResponse_SERVING)
+
+	var unaryCalls, streamCalls int32
+	unary := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		atomic.AddInt32(&unaryCalls, 1)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	stream := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		atomic.AddInt32(&streamCalls, 1)
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(2),
+		WithInterceptorChain([]grpc.UnaryClientInterceptor{unary}, []grpc.StreamClientInterceptor{stream}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	if _, err := healthpb.NewHealthClient(pool).Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := healthpb.NewHealthClient(pool.Conn()).Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if _, err := healthpb.NewHealthClient(pool).Watch(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := atomic.LoadInt32(&unaryCalls); got != 2 {
+		t.Errorf("unary interceptor ran %d times; want 2", got)
+	}
+	if got := atomic.LoadInt32(&streamCalls); got != 1 {
+		t.Errorf("stream interceptor ran %d times; want 1", got)
+	}
+}
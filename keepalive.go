@@ -0,0 +1,37 @@
+package grpcpool
+
+import (
+	"time"
+
+	"google.golang.org/grpc/keepalive"
+)
+
+// DefaultKeepalive are the keepalive parameters of connections dialed without WithKeepalive.
+//
+// They ping connections with active calls every 5 minutes, the shortest interval grpc-go servers accept by
+// default (see keepalive.EnforcementPolicy), so L4 load balancers with idle timeouts don't drop them silently
+// during long calls and streams. To keep connections without calls alive too, see WithKeepalive and WithWarmPings.
+var DefaultKeepalive = keepalive.ClientParameters{
+	Time:    5 * time.Minute,
+	Timeout: 20 * time.Second,
+}
+
+// WithKeepalive sets the keepalive parameters of every connection the pool dials. The default is DefaultKeepalive;
+// the zero ClientParameters disable keepalive pings. A grpc.WithKeepaliveParams given to WithDialOptions takes
+// precedence.
+//
+// Pinging more often than the servers permit, or without active calls if they don't permit that, makes them close
+// the connections with a GOAWAY.
+func WithKeepalive(params keepalive.ClientParameters) Option {
+	return func(o *options) {
+		o.keepalive = &params
+	}
+}
+
+// keepaliveParams returns the keepalive parameters of the connections.
+func (o *options) keepaliveParams() keepalive.ClientParameters {
+	if o.keepalive != nil {
+		return *o.keepalive
+	}
+	return DefaultKeepalive
+}
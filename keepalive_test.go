@@ -0,0 +1,37 @@
+package grpcpool
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/keepalive"
+)
+
+func TestKeepalive(t *testing.T) {
+	custom := keepalive.ClientParameters{Time: time.Minute, Timeout: time.Second, PermitWithoutStream: true}
+	for name, tc := range map[string]struct {
+		opts []Option
+		want keepalive.ClientParameters
+	}{
+		"default":  {nil, DefaultKeepalive},
+		"custom":   {[]Option{WithKeepalive(custom)}, custom},
+		"disabled": {[]Option{WithKeepalive(keepalive.ClientParameters{})}, keepalive.ClientParameters{}},
+		"config": {mustConfigOptions(t, Config{KeepaliveTime: time.Minute, KeepaliveTimeout: time.Second}),
+			keepalive.ClientParameters{Time: time.Minute, Timeout: time.Second}},
+		"config default": {mustConfigOptions(t, Config{}), DefaultKeepalive},
+	} {
+		o := newOptions(tc.opts)
+		if got := o.keepaliveParams(); got != tc.want {
+			t.Errorf("%s: keepalive got %+v; want %+v", name, got, tc.want)
+		}
+	}
+}
+
+func mustConfigOptions(t *testing.T, cfg Config) []Option {
+	t.Helper()
+	opts, err := cfg.Options()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return opts
+}
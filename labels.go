@@ -0,0 +1,116 @@
+package grpcpool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+type labelKey struct{}
+
+// MaxCallLabels is the number of labels a pool keeps call counters for. Calls with further labels are counted
+// under OtherCallLabel, so labels derived from unbounded values, e.g. request paths with IDs, can't grow the memory
+// of the pool without bound.
+const MaxCallLabels = 1000
+
+// OtherCallLabel is the label of the calls made with labels beyond MaxCallLabels.
+const OtherCallLabel = "other"
+
+// labelsUsed is set once ContextWithCallLabel is called, so calls without labels skip the context lookup.
+var labelsUsed atomic.Bool
+
+// ContextWithCallLabel returns a context that attributes every call made with it on a Pool to label,
+// e.g. the HTTP route a call is made for. See Pool.LabelStats.
+//
+// Labels should come from a bounded set, e.g. route templates rather than paths; see MaxCallLabels.
+func ContextWithCallLabel(ctx context.Context, label string) context.Context {
+	labelsUsed.Store(true)
+	return context.WithValue(ctx, labelKey{}, label)
+}
+
+// labelCounters are the call counters of a label.
+type labelCounters struct {
+	calls    atomic.Int64
+	errors   atomic.Int64
+	inflight atomic.Int64
+}
+
+// labelRegistry holds the call counters of a pool by label.
+type labelRegistry struct {
+	m sync.Map     // label -> *labelCounters
+	n atomic.Int64 // labels in m, other than OtherCallLabel
+}
+
+// start returns the counters of the label of ctx, with the in-flight count incremented, or nil if ctx has none.
+func (r *labelRegistry) start(ctx context.Context) *labelCounters {
+	if !labelsUsed.Load() || ctx == nil {
+		return nil
+	}
+	label, ok := ctx.Value(labelKey{}).(string)
+	if !ok {
+		return nil
+	}
+	v, ok := r.m.Load(label)
+	if !ok {
+		v = r.add(label)
+	}
+	lc := v.(*labelCounters)
+	lc.inflight.Add(1)
+	return lc
+}
+
+// add returns the counters of label, added to r, or those of OtherCallLabel once r has MaxCallLabels labels.
+func (r *labelRegistry) add(label string) interface{} {
+	if label != OtherCallLabel {
+		if r.n.Add(1) <= MaxCallLabels {
+			v, loaded := r.m.LoadOrStore(label, &labelCounters{})
+			if loaded {
+				r.n.Add(-1)
+			}
+			return v
+		}
+		r.n.Add(-1)
+		if v, ok := r.m.Load(OtherCallLabel); ok {
+			return v
+		}
+	}
+	v, _ := r.m.LoadOrStore(OtherCallLabel, &labelCounters{})
+	return v
+}
+
+func (lc *labelCounters) finish(err error) {
+	if lc == nil {
+		return
+	}
+	lc.inflight.Add(-1)
+	lc.calls.Add(1)
+	if err != nil {
+		lc.errors.Add(1)
+	}
+}
+
+// LabelStats returns the call counters of the calls made with label, see ContextWithCallLabel.
+//
+// CallStats.Conns and CallStats.Streams are always zero.
+func (p *Pool) LabelStats(label string) CallStats {
+	v, ok := p.labels.m.Load(label)
+	if !ok {
+		return CallStats{}
+	}
+	lc := v.(*labelCounters)
+	return CallStats{
+		Calls:    lc.calls.Load(),
+		Errors:   lc.errors.Load(),
+		InFlight: lc.inflight.Load(),
+	}
+}
+
+// Labels returns the labels calls were made with on the pool.
+func (p *Pool) Labels() []string {
+	var labels []string
+	p.labels.m.Range(func(k, _ interface{}) bool {
+		labels = append(labels, k.(string))
+		return true
+	})
+	return labels
+}
@@ -0,0 +1,229 @@
+package grpcpool
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Priority is the admission priority of a call, see CallPriority.
+type Priority int
+
+const (
+	// PriorityNormal calls wait in the queue when the pool is at its concurrency limit. It is the default.
+	PriorityNormal Priority = iota
+
+	// PriorityHigh calls bypass the queue and the concurrency limit, e.g. to protect user-facing calls during overload.
+	PriorityHigh
+
+	// PriorityBestEffort calls are admitted after queued normal calls and are shed first: they only queue while the
+	// queue is less than half full.
+	PriorityBestEffort
+)
+
+// priorityCallOption sets the admission priority of a call.
+type priorityCallOption struct {
+	grpc.EmptyCallOption
+	priority Priority
+}
+
+// CallPriority returns a CallOption setting the admission priority of a call, see WithConcurrencyLimit.
+//
+// It has no effect on pools without a concurrency limit.
+func CallPriority(p Priority) grpc.CallOption {
+	return priorityCallOption{priority: p}
+}
+
+// callPriority returns the admission priority set on opts.
+func callPriority(opts []grpc.CallOption) Priority {
+	for _, opt := range opts {
+		if o, ok := opt.(priorityCallOption); ok {
+			return o.priority
+		}
+	}
+	return PriorityNormal
+}
+
+// WithConcurrencyLimit limits the pool to limit in-flight calls and open streams.
+//
+// Calls over the limit wait in a queue of up to maxQueue calls until a slot frees up or their context is done.
+// Calls that don't fit in the queue fail with codes.ResourceExhausted. See CallPriority for how calls can bypass
+// the queue or be shed first.
+func WithConcurrencyLimit(limit, maxQueue int) Option {
+	return func(o *options) {
+		o.limiter = newLimiter(limit, maxQueue)
+	}
+}
+
+// limiter admits calls up to a concurrency limit, queueing the rest by priority.
+type limiter struct {
+	limit    int
+	maxQueue int
+	caller   func(context.Context) string // see WithFairQueuing
+	releaser func()                       // release, bound once so admitting a call doesn't allocate
+
+	mu       sync.Mutex
+	inflight int
+	normal   lane
+	bestEff  lane
+}
+
+type waiter struct {
+	ready    chan struct{}
+	admitted bool // guarded by limiter.mu
+}
+
+func newLimiter(limit, maxQueue int) *limiter {
+	l := &limiter{limit: limit, maxQueue: maxQueue}
+	l.releaser = l.release
+	return l
+}
+
+func (l *limiter) queued() int {
+	return l.normal.len + l.bestEff.len
+}
+
+// acquire blocks until the call is admitted. The caller must call release once the call finishes.
+func (l *limiter) acquire(ctx context.Context, prio Priority) error {
+	l.mu.Lock()
+	if prio == PriorityHigh || (l.inflight < l.limit && l.queued() == 0) {
+		l.inflight++
+		l.mu.Unlock()
+		return nil
+	}
+
+	queue := &l.normal
+	maxQueue := l.maxQueue
+	if prio == PriorityBestEffort {
+		queue = &l.bestEff
+		maxQueue = l.maxQueue / 2
+	}
+	if l.queued() >= maxQueue {
+		l.mu.Unlock()
+		return status.Error(codes.ResourceExhausted, "grpcpool: concurrency limit reached")
+	}
+	caller := ""
+	if l.caller != nil {
+		caller = l.caller(ctx)
+	}
+	w := &waiter{ready: make(chan struct{})}
+	cq, e := queue.push(caller, w)
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if w.admitted {
+			// Admitted concurrently; the call proceeds and fails on its context.
+			return nil
+		}
+		queue.remove(cq, e)
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// release frees the slot of a finished call and admits queued calls.
+func (l *limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inflight--
+	for l.inflight < l.limit {
+		w := l.normal.pop()
+		if w == nil {
+			w = l.bestEff.pop()
+		}
+		if w == nil {
+			return
+		}
+		w.admitted = true
+		l.inflight++
+		close(w.ready)
+	}
+}
+
+// lane is the queue of one priority. It admits callers in round-robin order, and each caller's calls in FIFO order.
+type lane struct {
+	callers map[string]*callerQueue
+	ring    list.List // of *callerQueue with waiters, next caller first
+	len     int
+}
+
+type callerQueue struct {
+	caller  string
+	waiters list.List // of *waiter
+	elem    *list.Element
+}
+
+func (q *lane) push(caller string, w *waiter) (*callerQueue, *list.Element) {
+	if q.callers == nil {
+		q.callers = map[string]*callerQueue{}
+	}
+	cq, ok := q.callers[caller]
+	if !ok {
+		cq = &callerQueue{caller: caller}
+		q.callers[caller] = cq
+		cq.elem = q.ring.PushBack(cq)
+	}
+	q.len++
+	return cq, cq.waiters.PushBack(w)
+}
+
+// pop removes and returns the next waiter, or nil if the lane is empty.
+func (q *lane) pop() *waiter {
+	front := q.ring.Front()
+	if front == nil {
+		return nil
+	}
+	cq := front.Value.(*callerQueue)
+	w := cq.waiters.Remove(cq.waiters.Front()).(*waiter)
+	q.len--
+	if cq.waiters.Len() == 0 {
+		q.drop(cq)
+	} else {
+		q.ring.MoveToBack(front)
+	}
+	return w
+}
+
+func (q *lane) remove(cq *callerQueue, e *list.Element) {
+	cq.waiters.Remove(e)
+	q.len--
+	if cq.waiters.Len() == 0 {
+		q.drop(cq)
+	}
+}
+
+func (q *lane) drop(cq *callerQueue) {
+	q.ring.Remove(cq.elem)
+	delete(q.callers, cq.caller)
+}
+
+// WithFairQueuing makes calls queued by WithConcurrencyLimit be admitted fairly across callers instead of in FIFO
+// order, so one aggressive caller can't monopolize the pool during contention.
+//
+// caller returns the identity of the caller of a call, e.g. from a context value. Queued callers are admitted in
+// round-robin order. It has no effect without WithConcurrencyLimit.
+func WithFairQueuing(caller func(ctx context.Context) string) Option {
+	return func(o *options) {
+		o.fairCaller = caller
+	}
+}
+
+// admit blocks until a call with opts is admitted by the pool's concurrency limit, if any,
+// and returns the function to call once it finishes.
+func (rt *runtimeConfig) admit(ctx context.Context, opts []grpc.CallOption) (func(), error) {
+	if rt.limiter == nil {
+		return func() {}, nil
+	}
+	if err := rt.limiter.acquire(ctx, callPriority(opts)); err != nil {
+		return nil, err
+	}
+	return rt.limiter.releaser, nil
+}
@@ -0,0 +1,162 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// waitQueued waits until l has n queued calls.
+func waitQueued(t *testing.T, l *limiter, n int) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		l.mu.Lock()
+		queued := l.queued()
+		l.mu.Unlock()
+		if queued == n {
+			return
+		}
+	}
+	t.Fatalf("limiter never had %d queued calls", n)
+}
+
+func TestLimiterPriorities(t *testing.T) {
+	l := newLimiter(1, 4)
+	ctx := context.Background()
+
+	if err := l.acquire(ctx, PriorityNormal); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.acquire(ctx, PriorityHigh); err != nil {
+		t.Fatalf("high priority call not admitted over the limit: %v", err)
+	}
+
+	admitted := make(chan Priority, 2)
+	go func() {
+		if err := l.acquire(ctx, PriorityBestEffort); err == nil {
+			admitted <- PriorityBestEffort
+		}
+	}()
+	waitQueued(t, l, 1)
+	go func() {
+		if err := l.acquire(ctx, PriorityNormal); err == nil {
+			admitted <- PriorityNormal
+		}
+	}()
+	waitQueued(t, l, 2)
+
+	// The queue is half full, so further best-effort calls are shed.
+	if err := l.acquire(ctx, PriorityBestEffort); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("best-effort call with a half-full queue got %v; want ResourceExhausted", err)
+	}
+
+	l.release()
+	l.release()
+	if got := <-admitted; got != PriorityNormal {
+		t.Errorf("first admitted call got priority %v; want normal", got)
+	}
+	l.release()
+	if got := <-admitted; got != PriorityBestEffort {
+		t.Errorf("second admitted call got priority %v; want best effort", got)
+	}
+}
+
+func TestLimiterContext(t *testing.T) {
+	l := newLimiter(1, 1)
+	if err := l.acquire(context.Background(), PriorityNormal); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.acquire(ctx, PriorityNormal); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("queued call past its deadline got %v; want DeadlineExceeded", err)
+	}
+	if l.queued() != 0 {
+		t.Errorf("limiter has %d queued calls after the deadline; want 0", l.queued())
+	}
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	l := healthTestServer(t, "", healthpb.HealthCheckResponse_SERVING)
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithConcurrencyLimit(1, 0),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	client := healthpb.NewHealthClient(pool)
+	ctx, cancel := context.WithCancel(context.Background())
+	watch, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := watch.Recv(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("call over the limit got %v; want ResourceExhausted", err)
+	}
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}, CallPriority(PriorityHigh)); err != nil {
+		t.Errorf("high priority call over the limit failed: %v", err)
+	}
+
+	cancel()
+	deadline, cancelDeadline := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelDeadline()
+	for {
+		if _, err := client.Check(deadline, &healthpb.HealthCheckRequest{}); err == nil {
+			break
+		} else if status.Code(err) != codes.ResourceExhausted {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+type callerKey struct{}
+
+func TestLimiterFairQueuing(t *testing.T) {
+	l := newLimiter(1, 10)
+	l.caller = func(ctx context.Context) string {
+		caller, _ := ctx.Value(callerKey{}).(string)
+		return caller
+	}
+	if err := l.acquire(context.Background(), PriorityNormal); err != nil {
+		t.Fatal(err)
+	}
+
+	admitted := make(chan string, 4)
+	enqueue := func(caller string, n int) {
+		ctx := context.WithValue(context.Background(), callerKey{}, caller)
+		go func() {
+			if err := l.acquire(ctx, PriorityNormal); err == nil {
+				admitted <- caller
+			}
+		}()
+		waitQueued(t, l, n)
+	}
+	// The aggressive caller queues first, but its calls alternate with the other caller's.
+	enqueue("aggressive", 1)
+	enqueue("aggressive", 2)
+	enqueue("aggressive", 3)
+	enqueue("polite", 4)
+
+	var order []string
+	for i := 0; i < 4; i++ {
+		l.release()
+		order = append(order, <-admitted)
+	}
+	if order[0] != "aggressive" || order[1] != "polite" {
+		t.Errorf("admission order got %v; want the polite caller second", order)
+	}
+}
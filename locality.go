@@ -0,0 +1,72 @@
+package grpcpool
+
+// LocalityConfig configures zone-aware connection preference, see WithLocality.
+type LocalityConfig struct {
+	// Zone is the zone of the client. Connections to endpoints in Zone are preferred.
+	Zone string
+
+	// MinHealthy is the fraction of healthy local connections below which calls spill to other zones.
+	// Zero spills only when no local connection is healthy.
+	MinHealthy float64
+
+	// MaxInFlight is the number of in-flight calls on every healthy local connection at which calls
+	// spill to other zones. Zero disables spilling on load.
+	MaxInFlight int
+}
+
+type locality struct {
+	LocalityConfig
+}
+
+// WithLocality makes the pool prefer connections to endpoints in cfg.Zone.
+//
+// Calls use healthy local connections as long as their health or capacity doesn't degrade below the limits in cfg,
+// and spill over to the healthy connections in all zones otherwise, to cut cross-zone traffic.
+// The zone of a connection is Endpoint.Zone, or the result of the function given to WithZoneFunc.
+func WithLocality(cfg LocalityConfig) Option {
+	return func(o *options) {
+		o.locality = &locality{cfg}
+	}
+}
+
+// WithZoneFunc sets a function deriving the zone of endpoints without Endpoint.Zone, e.g. from their address.
+func WithZoneFunc(f func(Endpoint) string) Option {
+	return func(o *options) {
+		o.zoneFunc = f
+	}
+}
+
+// candidates returns the connections of t calls may be sent to.
+func (l *locality) candidates(t *tier) []*PoolConn {
+	local := t.healthyLocal()
+	loaded := 0
+	if l.MaxInFlight > 0 {
+		for _, c := range local {
+			if c.InFlight() >= l.MaxInFlight {
+				loaded++
+			}
+		}
+	}
+	degraded := len(local) == 0 ||
+		float64(len(local)) < l.MinHealthy*float64(len(t.local)) ||
+		(l.MaxInFlight > 0 && loaded == len(local))
+
+	if !degraded {
+		return local
+	}
+	if conns := t.healthyConns(); len(conns) > 0 {
+		return conns
+	}
+	return t.conns
+}
+
+// filterHealthy returns the healthy connections in conns.
+func filterHealthy(conns []*PoolConn) []*PoolConn {
+	healthy := make([]*PoolConn, 0, len(conns))
+	for _, c := range conns {
+		if c.Healthy() {
+			healthy = append(healthy, c)
+		}
+	}
+	return healthy
+}
@@ -0,0 +1,83 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestLocality(t *testing.T) {
+	_, local := mockServer(t)
+	_, remote := mockServer(t)
+
+	pool, err := NewEndpointPool(context.Background(), []Endpoint{
+		{Addr: local.Addr().String(), Zone: "a"},
+		{Addr: remote.Addr().String(), Zone: "b"},
+	},
+		WithLocality(LocalityConfig{Zone: "a", MaxInFlight: 1}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	localConn, remoteConn := pool.Conns()[0], pool.Conns()[1]
+	for i := 0; i < 10; i++ {
+		if got := pool.Conn(); got != localConn.ClientConn() {
+			t.Fatalf("pool.Conn() #%d got %v; want the local conn", i, got)
+		}
+	}
+
+	// Spill to the remote zone once the local conn is at capacity.
+	localConn.inflight.Add(1)
+	remoteCalls := 0
+	for i := 0; i < 10; i++ {
+		if pool.Conn() == remoteConn.ClientConn() {
+			remoteCalls++
+		}
+	}
+	localConn.inflight.Add(-1)
+	if remoteCalls == 0 {
+		t.Error("no calls spilled to the remote zone with the local conn at capacity")
+	}
+}
+
+func TestLocalityUnhealthy(t *testing.T) {
+	_, remote := mockServer(t)
+
+	pool, err := NewEndpointPool(context.Background(), []Endpoint{
+		{Addr: "localhost:1"},
+		{Addr: remote.Addr().String()},
+	},
+		WithLocality(LocalityConfig{Zone: "a"}),
+		WithZoneFunc(func(e Endpoint) string {
+			if e.Addr == "localhost:1" {
+				return "a"
+			}
+			return "b"
+		}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	localConn, remoteConn := pool.Conns()[0], pool.Conns()[1]
+	if zone := localConn.Endpoint().Zone; zone != "a" {
+		t.Fatalf("local conn zone got %q; want a", zone)
+	}
+
+	waitForState(t, localConn.ClientConn(), connectivity.TransientFailure)
+	waitForSnapshot(t, pool)
+
+	for i := 0; i < 10; i++ {
+		if got := pool.Conn(); got != remoteConn.ClientConn() {
+			t.Fatalf("pool.Conn() #%d got %v; want the remote conn", i, got)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package grpcpool
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxStreamsPoll is how often calls queued by WithMaxStreamsPerConn look for a connection below its cap.
+const maxStreamsPoll = time.Millisecond
+
+// StreamSpillover configures what calls do when every connection is at its cap, see WithMaxStreamsPerConn.
+type StreamSpillover struct {
+	// MaxConns is the number of connections the pool grows to at most by dialing spillover connections.
+	// Zero or at most the pool size dials none.
+	MaxConns int
+
+	// QueueTimeout is how long calls wait for a connection below its cap before failing. Zero fails right away.
+	QueueTimeout time.Duration
+}
+
+type maxStreams struct {
+	StreamSpillover
+	n       int64
+	dialing atomic.Bool // a spillover connection is being added
+}
+
+// WithMaxStreamsPerConn caps the in-flight calls and streams of every connection at n, the server's HTTP/2
+// MaxConcurrentStreams, so calls never queue inside the transport unnoticed.
+//
+// Calls picking a connection at its cap are moved to another connection of the same group below its cap. If there
+// is none, an ungrouped spillover connection is dialed in the background as by WithStreamGrowth, up to
+// spill.MaxConns, and the call waits up to spill.QueueTimeout for a connection to free up or come up. Calls that
+// don't get one fail with ResourceExhausted. Calls bound to a session (see BindSession) aren't capped.
+func WithMaxStreamsPerConn(n int, spill StreamSpillover) Option {
+	return func(o *options) {
+		o.maxStreams = &maxStreams{StreamSpillover: spill, n: int64(n)}
+	}
+}
+
+// reserve picks a connection for a call, another one than avoid if possible, and counts the call in flight on it,
+// see WithMaxStreamsPerConn.
+func (p *Pool) reserve(info PickInfo, avoid *PoolConn) (*connSet, *PoolConn, error) {
+	if sess := p.session(info.Ctx); sess != nil && sess.acquire() {
+		sess.conn.inflight.Add(1)
+		return sess.set, sess.conn, nil
+	}
+	s := p.acquire()
+	c := p.pick(s, info)
+	if c == avoid {
+		c = s.other(c)
+	}
+	c, err := p.reserveConn(info.Ctx, c)
+	if err != nil {
+		s.active.Add(-1)
+		return nil, nil, err
+	}
+	return s, c, nil
+}
+
+// reserveConn counts a call in flight on picked, or on another connection of its group if picked is at the cap
+// of WithMaxStreamsPerConn, and returns that connection.
+func (p *Pool) reserveConn(ctx context.Context, picked *PoolConn) (*PoolConn, error) {
+	if p.opts.maxStreams == nil {
+		picked.inflight.Add(1)
+		return picked, nil
+	}
+	return p.reserveCapped(ctx, picked)
+}
+
+// tryReserve counts a call in flight on c unless c has max in flight.
+func (c *PoolConn) tryReserve(max int64) bool {
+	if c.inflight.Add(1) <= max {
+		return true
+	}
+	c.inflight.Add(-1)
+	return false
+}
+
+// reserveCapped counts a call in flight on picked or, if it is at its cap, on another connection of its group.
+func (p *Pool) reserveCapped(ctx context.Context, picked *PoolConn) (*PoolConn, error) {
+	m := p.opts.maxStreams
+	if picked.tryReserve(m.n) {
+		return picked, nil
+	}
+	var deadline Timer
+	for {
+		if g, ok := p.set.Load().groups[picked.group]; ok {
+			for _, c := range g.conns {
+				if c.tryReserve(m.n) {
+					return c, nil
+				}
+			}
+		}
+		if picked.group == "" {
+			p.spill()
+		}
+		if m.QueueTimeout <= 0 {
+			break
+		}
+		if deadline == nil {
+			deadline = p.opts.clock.NewTimer(m.QueueTimeout)
+			defer deadline.Stop()
+		}
+		poll := p.opts.clock.NewTimer(maxStreamsPoll)
+		select {
+		case <-ctx.Done():
+			poll.Stop()
+			return nil, status.FromContextError(ctx.Err()).Err()
+		case <-deadline.C():
+			poll.Stop()
+			return nil, errStreamLimit
+		case <-poll.C():
+		}
+	}
+	return nil, errStreamLimit
+}
+
+var errStreamLimit = status.Error(codes.ResourceExhausted, "grpcpool: every connection is at its stream limit")
+
+// spill adds a spillover connection in the background, unless one is being added, the pool is at its maximum or
+// closed.
+func (p *Pool) spill() {
+	m := p.opts.maxStreams
+	if p.closed.Load() || len(p.set.Load().conns) >= m.MaxConns || !m.dialing.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer m.dialing.Store(false)
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if old := p.set.Load(); len(old.conns) < m.MaxConns {
+			if _, ok := old.groups[""]; ok {
+				if p.addConn(context.Background(), old) == nil {
+					p.saveSize()
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,93 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestMaxStreamsPerConn(t *testing.T) {
+	l := echoStreamServer(t)
+	desc := &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}
+
+	for name, tc := range map[string]struct {
+		spill     StreamSpillover
+		wantCode  codes.Code
+		wantConns int
+	}{
+		"fail":  {StreamSpillover{}, codes.ResourceExhausted, 2},
+		"queue": {StreamSpillover{QueueTimeout: 5 * time.Second}, codes.OK, 2},
+		"spill": {StreamSpillover{MaxConns: 3, QueueTimeout: 5 * time.Second}, codes.OK, 3},
+	} {
+		pool, err := NewPool(context.Background(), l.Addr().String(),
+			WithSize(2),
+			WithMaxStreamsPerConn(1, tc.spill),
+			WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		var streams []grpc.ClientStream
+		for i := 0; i < 2; i++ {
+			cs, err := pool.NewStream(ctx, desc, "/test.Echo/Echo")
+			if err != nil {
+				t.Fatal(err)
+			}
+			streams = append(streams, cs)
+		}
+		for i, c := range pool.Conns() {
+			if got := c.InFlight(); got != 1 {
+				t.Errorf("%s: conn %d has %d streams; want 1", name, i, got)
+			}
+		}
+
+		if name == "queue" {
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				streams[0].CloseSend()
+				streams[0].RecvMsg(&emptypb.Empty{})
+			}()
+		}
+		_, err = pool.NewStream(ctx, desc, "/test.Echo/Echo")
+		if got := status.Code(err); got != tc.wantCode {
+			t.Errorf("%s: third NewStream() got %v; want %v", name, err, tc.wantCode)
+		}
+		if got := pool.Num(); got != tc.wantConns {
+			t.Errorf("%s: pool.Num() got %d; want %d", name, got, tc.wantConns)
+		}
+		for _, c := range pool.Conns() {
+			if got := c.InFlight(); got > 1 {
+				t.Errorf("%s: conn %d has %d streams; want at most 1", name, c.Index(), got)
+			}
+		}
+		cancel()
+		pool.Close()
+	}
+}
+
+func TestSpillAfterClose(t *testing.T) {
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithMaxStreamsPerConn(1, StreamSpillover{MaxConns: 2}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.Close()
+
+	pool.spill()
+	if pool.opts.maxStreams.dialing.Load() {
+		t.Error("spill() after Close started dialing a spillover connection")
+	}
+	if n := pool.Num(); n != 1 {
+		t.Errorf("pool.Num() after spilling on a closed pool got %d; want 1", n)
+	}
+}
@@ -0,0 +1,60 @@
+package grpcpool
+
+import (
+	"errors"
+	"math"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// MigrationGroup is the group of the connections dialed with the new credentials of WithCredentialsMigration.
+const MigrationGroup = "migration"
+
+type migration struct {
+	fraction float64
+	creds    credentials.TransportCredentials
+
+	weight uint64 // share of calls in parts per splitScale, set when dialing
+	idx    uint64 // access via sync/atomic
+}
+
+// WithCredentialsMigration dials a fraction of the pool's connections with creds instead of the pool's transport
+// credentials, e.g. to move from TLS to mTLS or to new roots, and spreads calls evenly over old and new
+// connections.
+//
+// The migrated connections are in MigrationGroup, so their calls and errors can be compared against those of the
+// ungrouped connections with GroupStats before rolling out the new credentials everywhere. The number of migrated
+// connections is rounded, and at least one connection keeps the old credentials.
+func WithCredentialsMigration(fraction float64, creds credentials.TransportCredentials) Option {
+	return func(o *options) {
+		o.migration = &migration{fraction: fraction, creds: creds}
+	}
+}
+
+// reserve adds the group of the migrated connections of a pool of num connections to o.
+func (m *migration) reserve(o *options, num int) error {
+	if m.fraction < 0 || m.fraction > 1 {
+		return errors.New("grpcpool: credentials migration fraction must be between 0 and 1")
+	}
+	n := int(math.Round(m.fraction * float64(num)))
+	if n >= num {
+		n = num - 1
+	}
+	if n == 0 {
+		return nil
+	}
+	m.weight = uint64(n * splitScale / num)
+	o.addGroup(groupSpec{name: MigrationGroup, conns: n, dialOpts: []grpc.DialOption{grpc.WithTransportCredentials(m.creds)}})
+	return nil
+}
+
+// pick reports whether the next call is routed to the migrated connections.
+func (m *migration) pick() bool {
+	if m.weight == 0 {
+		return false
+	}
+	i := atomic.AddUint64(&m.idx, 1)
+	return (i*m.weight)/splitScale != ((i-1)*m.weight)/splitScale
+}
@@ -0,0 +1,98 @@
+package grpcpool
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// countingCreds are insecure credentials counting their client handshakes.
+type countingCreds struct {
+	credentials.TransportCredentials
+	handshakes *atomic.Int64
+}
+
+func (c countingCreds) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	c.handshakes.Add(1)
+	return c.TransportCredentials.ClientHandshake(ctx, authority, conn)
+}
+
+func (c countingCreds) Clone() credentials.TransportCredentials {
+	return countingCreds{TransportCredentials: c.TransportCredentials.Clone(), handshakes: c.handshakes}
+}
+
+func TestCredentialsMigration(t *testing.T) {
+	_, l := mockServer(t)
+
+	var handshakes atomic.Int64
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(4),
+		WithCredentialsMigration(0.5, countingCreds{TransportCredentials: insecure.NewCredentials(), handshakes: &handshakes}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithUnaryInterceptor(nopInvoker)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	for i, c := range pool.Conns() {
+		want := ""
+		if i >= 2 {
+			want = MigrationGroup
+		}
+		if got := c.Group(); got != want {
+			t.Errorf("conn %d group got %q; want %q", i, got, want)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pool.WarmStreams(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if n := handshakes.Load(); n != 2 {
+		t.Errorf("handshakes with the new credentials got %d; want 2", n)
+	}
+
+	for i := 0; i < 100; i++ {
+		if err := pool.Invoke(context.Background(), "/test.Test/Call", nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := pool.GroupStats(MigrationGroup).Calls; got != 50 {
+		t.Errorf("calls on migrated conns got %d; want 50", got)
+	}
+	if got := pool.GroupStats("").Calls; got != 50 {
+		t.Errorf("calls on old conns got %d; want 50", got)
+	}
+}
+
+func TestCredentialsMigrationBounds(t *testing.T) {
+	creds := insecure.NewCredentials()
+	for _, tc := range []struct {
+		fraction float64
+		want     int
+	}{{0, 0}, {0.1, 0}, {0.2, 1}, {1, 2}} {
+		pool, err := NewPool(context.Background(), "localhost:1",
+			WithSize(3),
+			WithCredentialsMigration(tc.fraction, creds),
+			WithDialOptions(grpc.WithTransportCredentials(creds)),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := pool.GroupStats(MigrationGroup).Conns; got != tc.want {
+			t.Errorf("migrated conns of 3 with fraction %v got %d; want %d", tc.fraction, got, tc.want)
+		}
+		pool.Close()
+	}
+	if _, err := NewPool(context.Background(), "localhost:1", WithCredentialsMigration(1.5, creds)); err == nil {
+		t.Error("NewPool() with a migration fraction of 1.5 got no error")
+	}
+}
@@ -0,0 +1,18 @@
+package grpcpool
+
+import "google.golang.org/grpc"
+
+// WithMaxMessageSize sets the largest message, in bytes, that calls and streams of the pool send and receive,
+// instead of gRPC's 4MB receive limit, which pooled bulk APIs easily exceed. Zero keeps the gRPC default of the
+// direction. They are default CallOptions, see WithCallOptions, so grpc.MaxCallSendMsgSize and
+// grpc.MaxCallRecvMsgSize given to a call override them. Servers enforce their own receive limit.
+func WithMaxMessageSize(send, recv int) Option {
+	return func(o *options) {
+		if send > 0 {
+			o.callOpts = append(o.callOpts, grpc.MaxCallSendMsgSize(send))
+		}
+		if recv > 0 {
+			o.callOpts = append(o.callOpts, grpc.MaxCallRecvMsgSize(recv))
+		}
+	}
+}
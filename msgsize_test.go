@@ -0,0 +1,59 @@
+package grpcpool
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestMaxMessageSize(t *testing.T) {
+	s := grpc.NewServer(grpc.MaxRecvMsgSize(16<<20), grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+		m := &wrapperspb.BytesValue{}
+		if err := stream.RecvMsg(m); err != nil && err != io.EOF {
+			return err
+		}
+		return stream.SendMsg(m)
+	}))
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(l)
+	defer s.Stop()
+
+	big := wrapperspb.Bytes(bytes.Repeat([]byte{1}, 8<<20))
+	for name, tc := range map[string]struct {
+		opts     []Option
+		callOpts []grpc.CallOption
+		want     codes.Code
+	}{
+		"default":  {nil, nil, codes.ResourceExhausted},
+		"raised":   {[]Option{WithMaxMessageSize(0, 16<<20)}, nil, codes.OK},
+		"override": {[]Option{WithMaxMessageSize(0, 16<<20)}, []grpc.CallOption{grpc.MaxCallRecvMsgSize(1 << 20)}, codes.ResourceExhausted},
+		"send":     {[]Option{WithMaxMessageSize(1<<20, 16<<20)}, nil, codes.ResourceExhausted},
+	} {
+		pool, err := NewPool(context.Background(), l.Addr().String(),
+			append(tc.opts, WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())))...,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := &wrapperspb.BytesValue{}
+		err = pool.Invoke(context.Background(), "/test.Bulk/Echo", big, got, tc.callOpts...)
+		if status.Code(err) != tc.want {
+			t.Errorf("%s: echo of 8MB got %v; want %v", name, err, tc.want)
+		}
+		if err == nil && len(got.Value) != len(big.Value) {
+			t.Errorf("%s: echoed %d bytes; want %d", name, len(got.Value), len(big.Value))
+		}
+		pool.Close()
+	}
+}
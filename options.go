@@ -0,0 +1,129 @@
+package grpcpool
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+)
+
+// Option configures a Pool.
+type Option func(*options)
+
+type options struct {
+	size        int
+	dialOpts    []grpc.DialOption
+	dialer      func(context.Context, string) (net.Conn, error)
+	subsetIndex uint
+	subsetSize  int
+	picker      Picker
+	locality    *locality
+	failover    *failover
+	canary      *canary
+	groups      []groupSpec
+
+	streamConns    bool
+	bulk           *methodMatcher
+	routes         *routeTable
+	allowed        *methodMatcher
+	denied         *methodMatcher
+	limiter        *limiter
+	fairCaller     func(context.Context) string
+	callTimeout    time.Duration
+	methodTimeouts map[string]time.Duration
+	dialMetrics    *dialMetrics
+	peerAddrs      bool
+	sizeStore      *sizeStore
+	maxResize      int
+	singleflight   *singleflight
+	affinity       func(context.Context) (string, bool)
+	orca           bool
+	pprofTarget    string
+	connIDName     string
+	baggageName    string
+	baggage        BaggageFunc
+	chaos          *ChaosConfig
+	clock          Clock
+
+	batchWorkers int
+	growth       *growth
+	callOpts     []grpc.CallOption
+	callDefaults *callDefaults
+	sourcePorts  *sourcePorts
+	throughput   *ThroughputGrowthConfig
+	certs        *certRotation
+	perRPC       *perRPCSource
+	migration    *migration
+	egress       *egress
+	certExpiry   *certExpiry
+	audit        *audit
+	credsFunc    func(Endpoint) (credentials.TransportCredentials, error)
+
+	requestKeyFunc RequestKeyFunc
+
+	connCache     *ConnCache
+	connCacheKey  string
+	zoneFunc      func(Endpoint) string
+	authorityFunc func(Endpoint) string
+	socketControl SocketControl
+	goAway        *goAway
+	keepalive     *keepalive.ClientParameters
+	warmPings     *warmPings
+	shutdownHooks []func(ctx context.Context)
+	maxStreams    *maxStreams
+	streamRetry   int
+	streamLoad    *streamLoadReport
+	flowControl   *FlowControl
+	streamHooks   []StreamHooks
+	compression   string
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.picker == nil {
+		o.picker = RoundRobin()
+	}
+	if o.clock == nil {
+		o.clock = SystemClock()
+	}
+	if o.limiter != nil {
+		o.limiter.caller = o.fairCaller
+	}
+	o.callDefaults = newCallDefaults(o.callOpts)
+	defaults := append([]grpc.DialOption{grpc.WithKeepaliveParams(o.keepaliveParams())}, o.flowControlDialOptions()...)
+	o.dialOpts = append(defaults, o.dialOpts...) // first, so dial options win
+	return o
+}
+
+// WithSize sets the number of connections the pool dials.
+//
+// When dialing a set of endpoints the connections are spread over the endpoints in order.
+// The default is one connection per endpoint.
+func WithSize(n uint) Option {
+	return func(o *options) {
+		o.size = int(n)
+	}
+}
+
+// WithDialOptions sets the grpc.DialOptions used for every connection the pool dials.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) {
+		o.dialOpts = append(o.dialOpts, opts...)
+	}
+}
+
+// WithSubset limits the pool to a deterministic subset of size endpoints chosen for the client with clientIndex.
+//
+// See Subset for details on how the subset is chosen.
+func WithSubset(clientIndex uint, size int) Option {
+	return func(o *options) {
+		o.subsetIndex = clientIndex
+		o.subsetSize = size
+	}
+}
@@ -0,0 +1,169 @@
+package grpcpool
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// orcaTrailer is the trailer carrying the per-call ORCA load report, a serialized xds.data.orca.v3.OrcaLoadReport.
+const orcaTrailer = "endpoint-load-metrics-bin"
+
+// Fields of xds.data.orca.v3.OrcaLoadReport.
+const (
+	orcaCPUUtilization         = 1
+	orcaApplicationUtilization = 9
+)
+
+// minORCAUtilization caps the weight of backends reporting (close to) no load.
+const minORCAUtilization = 0.01
+
+// WithORCA weights connection selection by the ORCA load reports backends send in the trailers of unary calls,
+// e.g. through google.golang.org/grpc/orca.CallMetricsRecorder.
+//
+// Connections are picked at random with a weight inversely proportional to the last reported utilization of
+// their backend: application_utilization if set, cpu_utilization otherwise. Connections without a report yet get
+// the mean weight of the others, so the pool backs off from overloaded backends the way the gRPC
+// weighted_round_robin policy does. WithORCA replaces the Picker.
+func WithORCA() Option {
+	return func(o *options) {
+		o.orca = true
+		o.picker = orcaPicker{}
+	}
+}
+
+// Utilization returns the utilization last reported by the backend of c, see WithORCA.
+func (c *PoolConn) Utilization() (float64, bool) {
+	bits := c.utilization.Load()
+	if bits == 0 {
+		return 0, false
+	}
+	return math.Float64frombits(bits), true
+}
+
+// orcaCall is the per-call bookkeeping of a unary call reading the ORCA trailer. orcaCalls reuses them, so
+// WithORCA doesn't add garbage to every call.
+type orcaCall struct {
+	trailer metadata.MD
+	opt     grpc.CallOption // grpc.Trailer(&trailer)
+	opts    []grpc.CallOption
+}
+
+var orcaCalls = sync.Pool{New: func() interface{} {
+	oc := &orcaCall{}
+	oc.opt = grpc.Trailer(&oc.trailer)
+	return oc
+}}
+
+// invokeORCA makes a unary call on c and records the load report in its trailer.
+func (c *PoolConn) invokeORCA(ctx context.Context, method string, args interface{}, reply interface{}, opts []grpc.CallOption) error {
+	oc := orcaCalls.Get().(*orcaCall)
+	oc.opts = append(append(oc.opts, opts...), oc.opt)
+	err := c.cc.Invoke(ctx, method, args, reply, oc.opts...)
+	c.recordLoad(oc.trailer)
+	for i := range oc.opts {
+		oc.opts[i] = nil // don't retain the options of the call
+	}
+	oc.opts = oc.opts[:0]
+	oc.trailer = nil
+	orcaCalls.Put(oc)
+	return err
+}
+
+// recordLoad records the utilization of the ORCA load report in trailer, if any.
+func (c *PoolConn) recordLoad(trailer metadata.MD) {
+	v := trailer.Get(orcaTrailer)
+	if len(v) == 0 {
+		return
+	}
+	util, ok := parseUtilization([]byte(v[len(v)-1]))
+	if !ok {
+		return
+	}
+	// Zero is stored as the smallest positive float, as zero bits mean no report.
+	c.utilization.Store(math.Float64bits(math.Max(util, math.SmallestNonzeroFloat64)))
+}
+
+// parseUtilization returns the application utilization of a serialized OrcaLoadReport, falling back to the CPU
+// utilization.
+func parseUtilization(b []byte) (float64, bool) {
+	var cpu, app float64
+	var hasCPU, hasApp bool
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return 0, false
+		}
+		b = b[n:]
+		if typ == protowire.Fixed64Type && (num == orcaCPUUtilization || num == orcaApplicationUtilization) {
+			v, n := protowire.ConsumeFixed64(b)
+			if n < 0 {
+				return 0, false
+			}
+			b = b[n:]
+			if num == orcaCPUUtilization {
+				cpu, hasCPU = math.Float64frombits(v), true
+			} else {
+				app, hasApp = math.Float64frombits(v), true
+			}
+			continue
+		}
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			return 0, false
+		}
+		b = b[n:]
+	}
+	switch {
+	case hasApp && app > 0:
+		return app, true
+	case hasCPU:
+		return cpu, true
+	}
+	return 0, false
+}
+
+type orcaPicker struct{}
+
+func (orcaPicker) Pick(_ PickInfo, conns []*PoolConn) *PoolConn {
+	if len(conns) == 1 {
+		return conns[0]
+	}
+	var buf [16]float64
+	weights := buf[:0]
+	var sum float64
+	var reported int
+	for _, c := range conns {
+		var w float64
+		if util, ok := c.Utilization(); ok {
+			w = 1 / math.Max(util, minORCAUtilization)
+			sum += w
+			reported++
+		}
+		weights = append(weights, w)
+	}
+	mean := 1.0
+	if reported > 0 {
+		mean = sum / float64(reported)
+	}
+	var total float64
+	for i, w := range weights {
+		if w == 0 {
+			weights[i] = mean
+		}
+		total += weights[i]
+	}
+	r := rand.Float64() * total
+	for i, w := range weights {
+		if r < w {
+			return conns[i]
+		}
+		r -= w
+	}
+	return conns[len(conns)-1]
+}
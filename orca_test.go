@@ -0,0 +1,92 @@
+package grpcpool
+
+import (
+	"context"
+	"math"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// orcaReport returns a serialized OrcaLoadReport with the given CPU utilization.
+func orcaReport(cpu float64) string {
+	b := protowire.AppendTag(nil, 3, protowire.VarintType) // deprecated rps
+	b = protowire.AppendVarint(b, 100)
+	b = protowire.AppendTag(b, orcaCPUUtilization, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, math.Float64bits(cpu))
+	return string(b)
+}
+
+// orcaTestServer starts a health server reporting cpu as its utilization on every call.
+func orcaTestServer(t *testing.T, cpu float64) net.Listener {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		grpc.SetTrailer(ctx, metadata.Pairs(orcaTrailer, orcaReport(cpu)))
+		return handler(ctx, req)
+	}))
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+	return l
+}
+
+func TestParseUtilization(t *testing.T) {
+	app := protowire.AppendTag([]byte(orcaReport(0.5)), orcaApplicationUtilization, protowire.Fixed64Type)
+	app = protowire.AppendFixed64(app, math.Float64bits(0.25))
+	for name, tc := range map[string]struct {
+		report string
+		want   float64
+		ok     bool
+	}{
+		"cpu":         {orcaReport(0.5), 0.5, true},
+		"application": {string(app), 0.25, true},
+		"empty":       {"", 0, false},
+		"malformed":   {"\xff", 0, false},
+	} {
+		got, ok := parseUtilization([]byte(tc.report))
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("%s: parseUtilization got %v, %v; want %v, %v", name, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestORCA(t *testing.T) {
+	busy := orcaTestServer(t, 0.9)
+	idle := orcaTestServer(t, 0.1)
+	pool, err := NewEndpointPool(context.Background(),
+		[]Endpoint{{Addr: busy.Addr().String()}, {Addr: idle.Addr().String()}},
+		WithORCA(),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	client := healthpb.NewHealthClient(pool)
+	for i := 0; i < 500; i++ {
+		if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conns := pool.Conns()
+	if util, ok := conns[0].Utilization(); !ok || util != 0.9 {
+		t.Errorf("busy conn Utilization() got %v, %v; want 0.9, true", util, ok)
+	}
+	// The idle backend reports a ninth of the load, so it gets about 90% of the calls once both reported.
+	if calls := conns[0].calls.Load(); calls > 125 {
+		t.Errorf("busy conn got %d of 500 calls; want at most 125", calls)
+	}
+}
@@ -0,0 +1,69 @@
+package grpcpool
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+)
+
+// WithPeerAddrs records the remote address of the transport of every connection once it is established, so
+// operators can see which backend instances the pool is attached to, e.g. the pod IP behind a DNS name or a
+// Kubernetes service. It is read with PoolConn.PeerAddr and is part of the DebugState.
+func WithPeerAddrs() Option {
+	return func(o *options) {
+		o.peerAddrs = true
+	}
+}
+
+// PeerAddr returns the remote address of the current transport of the connection, or the empty string while it
+// has none or without WithPeerAddrs.
+func (c *PoolConn) PeerAddr() string {
+	if addr := c.peerAddr.Load(); addr != nil {
+		return *addr
+	}
+	return ""
+}
+
+// peerAddrDialOptions returns the dial options recording the peer address of c, if recorded.
+func (o *options) peerAddrDialOptions(c *PoolConn) []grpc.DialOption {
+	if !o.peerAddrs {
+		return nil
+	}
+	return []grpc.DialOption{grpc.WithStatsHandler(peerWatcher{c})}
+}
+
+// peerWatcher is a stats.Handler recording the remote address of the transports of a connection.
+type peerWatcher struct {
+	conn *PoolConn
+}
+
+// peerAddrKey is the context key of the *string recorded as the peer address of a transport.
+type peerAddrKey struct{}
+
+func (w peerWatcher) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (w peerWatcher) HandleRPC(context.Context, stats.RPCStats) {}
+
+func (w peerWatcher) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	if info.RemoteAddr == nil {
+		return ctx
+	}
+	addr := info.RemoteAddr.String()
+	return context.WithValue(ctx, peerAddrKey{}, &addr)
+}
+
+func (w peerWatcher) HandleConn(ctx context.Context, s stats.ConnStats) {
+	addr, ok := ctx.Value(peerAddrKey{}).(*string)
+	if !ok {
+		return
+	}
+	switch s.(type) {
+	case *stats.ConnBegin:
+		w.conn.peerAddr.Store(addr)
+	case *stats.ConnEnd:
+		w.conn.peerAddr.CompareAndSwap(addr, nil) // unless a newer transport replaced it
+	}
+}
@@ -0,0 +1,57 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestPeerAddrs(t *testing.T) {
+	s, l := mockServer(t)
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(1),
+		WithPeerAddrs(),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	c := pool.Conns()[0]
+	waitForState(t, c.ClientConn(), connectivity.Ready)
+	deadline := time.Now().Add(5 * time.Second)
+	for c.PeerAddr() == "" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got, want := c.PeerAddr(), l.Addr().String(); got != want {
+		t.Errorf("PeerAddr() got %q; want %q", got, want)
+	}
+	if got := pool.DebugState().Conns[0].Peer; got != c.PeerAddr() {
+		t.Errorf("DebugState peer got %q; want %q", got, c.PeerAddr())
+	}
+
+	s.Stop()
+	for c.PeerAddr() != "" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := c.PeerAddr(); got != "" {
+		t.Errorf("PeerAddr() after the transport closed got %q; want none", got)
+	}
+}
+
+func TestPeerAddrsDisabled(t *testing.T) {
+	_, l := mockServer(t)
+	pool, err := NewPool(context.Background(), l.Addr().String(), WithSize(1), WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	waitForState(t, pool.Conn(), connectivity.Ready)
+	if got := pool.Conns()[0].PeerAddr(); got != "" {
+		t.Errorf("PeerAddr() without WithPeerAddrs got %q; want none", got)
+	}
+}
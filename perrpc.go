@@ -0,0 +1,92 @@
+package grpcpool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// WithPerRPCCredentialsSource applies the PerRPCCredentials returned by src to every call and stream of the pool,
+// e.g. OAuth or JWT tokens, without dialing them into the connections.
+//
+// src is called for the first call, and again whenever a unary call fails with codes.Unauthenticated, so a token
+// revoked or expired early is replaced without re-dialing. Concurrent failures share a single refresh, and every
+// unary call failing with the credentials that were refreshed is retried once with the new ones. Streams use the
+// current credentials and aren't retried. Calls fail with codes.Unauthenticated if src fails.
+func WithPerRPCCredentialsSource(src func(ctx context.Context) (credentials.PerRPCCredentials, error)) Option {
+	return func(o *options) {
+		o.perRPC = &perRPCSource{src: src}
+	}
+}
+
+// perRPCSource caches the credentials of a WithPerRPCCredentialsSource source.
+type perRPCSource struct {
+	src     func(ctx context.Context) (credentials.PerRPCCredentials, error)
+	mu      sync.Mutex // serializes refreshes
+	current atomic.Pointer[perRPCCreds]
+}
+
+// perRPCCreds are credentials returned by the source, as a CallOption.
+type perRPCCreds struct {
+	opt grpc.CallOption
+}
+
+// get returns the current credentials, getting the first ones from the source.
+func (s *perRPCSource) get(ctx context.Context) (*perRPCCreds, error) {
+	if c := s.current.Load(); c != nil {
+		return c, nil
+	}
+	return s.refresh(ctx, nil)
+}
+
+// refresh replaces stale with new credentials from the source, unless they were already replaced.
+func (s *perRPCSource) refresh(ctx context.Context, stale *perRPCCreds) (*perRPCCreds, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c := s.current.Load(); c != stale {
+		return c, nil
+	}
+	creds, err := s.src(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "grpcpool: getting per-RPC credentials: %v", err)
+	}
+	c := &perRPCCreds{opt: grpc.PerRPCCredentials(creds)}
+	s.current.Store(c)
+	return c, nil
+}
+
+// invoke calls f with opts and the current credentials, and retries it with refreshed credentials if it fails
+// with codes.Unauthenticated.
+func (s *perRPCSource) invoke(ctx context.Context, opts []grpc.CallOption, f func(opts []grpc.CallOption) error) error {
+	creds, err := s.get(ctx)
+	if err != nil {
+		return err
+	}
+	opts = opts[:len(opts):len(opts)]
+	err = f(append(opts, creds.opt))
+	if status.Code(err) != codes.Unauthenticated {
+		return err
+	}
+	fresh, rerr := s.refresh(ctx, creds)
+	if rerr != nil {
+		return err
+	}
+	return f(append(opts, fresh.opt))
+}
+
+// streamOption returns the CallOption of the current credentials for a stream, or nil without a source.
+func (s *perRPCSource) streamOption(ctx context.Context) (grpc.CallOption, error) {
+	if s == nil {
+		return nil, nil
+	}
+	creds, err := s.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return creds.opt, nil
+}
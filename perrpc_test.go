@@ -0,0 +1,133 @@
+package grpcpool
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// tokenCreds are PerRPCCredentials sending a token, also over plaintext.
+type tokenCreds string
+
+func (c tokenCreds) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": string(c)}, nil
+}
+
+func (tokenCreds) RequireTransportSecurity() bool { return false }
+
+// tokenServer starts a server accepting only the token returned by valid.
+func tokenServer(t *testing.T, valid func() string) net.Listener {
+	t.Helper()
+	s := grpc.NewServer(grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		if v := md.Get("authorization"); len(v) != 1 || v[0] != valid() {
+			return status.Error(codes.Unauthenticated, "bad token")
+		}
+		if err := stream.RecvMsg(&emptypb.Empty{}); err != nil {
+			return err
+		}
+		return stream.SendMsg(&emptypb.Empty{})
+	}))
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+	return l
+}
+
+func TestPerRPCCredentialsSource(t *testing.T) {
+	var validToken atomic.Int64
+	validToken.Store(1)
+	l := tokenServer(t, func() string { return strconv.FormatInt(validToken.Load(), 10) })
+
+	var refreshes atomic.Int64
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(2),
+		WithPerRPCCredentialsSource(func(context.Context) (credentials.PerRPCCredentials, error) {
+			refreshes.Add(1)
+			return tokenCreds(strconv.FormatInt(validToken.Load(), 10)), nil
+		}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	call := func() error {
+		return pool.Invoke(context.Background(), "/test.Test/Call", &emptypb.Empty{}, &emptypb.Empty{})
+	}
+	for i := 0; i < 3; i++ {
+		if err := call(); err != nil {
+			t.Fatalf("call #%d got %v", i, err)
+		}
+	}
+	if n := refreshes.Load(); n != 1 {
+		t.Errorf("source called %d times for valid credentials; want once", n)
+	}
+
+	// Revoke the token: concurrent failures refresh once and retry.
+	validToken.Store(2)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := call(); err != nil {
+				t.Errorf("call after the token was revoked got %v; want a retry with a fresh token", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := refreshes.Load(); n != 2 {
+		t.Errorf("source called %d times after one revocation; want twice", n)
+	}
+
+	cs, err := pool.NewStream(context.Background(), &grpc.StreamDesc{ServerStreams: true}, "/test.Test/Stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.SendMsg(&emptypb.Empty{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.RecvMsg(&emptypb.Empty{}); err != nil {
+		t.Errorf("stream got %v; want the current credentials applied", err)
+	}
+}
+
+func TestPerRPCCredentialsSourceError(t *testing.T) {
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithPerRPCCredentialsSource(func(context.Context) (credentials.PerRPCCredentials, error) {
+			return nil, errors.New("no token")
+		}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	if err := pool.Invoke(context.Background(), "/test.Test/Call", nil, nil); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("call with a failing source got %v; want Unauthenticated", err)
+	}
+	if _, err := pool.NewStream(context.Background(), &grpc.StreamDesc{}, "/test.Test/Stream"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("stream with a failing source got %v; want Unauthenticated", err)
+	}
+	if n := pool.Conns()[0].InFlight(); n != 0 {
+		t.Errorf("in-flight count after failed calls got %d; want 0", n)
+	}
+}
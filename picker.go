@@ -0,0 +1,186 @@
+package grpcpool
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+)
+
+// PickInfo describes the call a connection is picked for.
+type PickInfo struct {
+	// Ctx is the context of the call. It is context.Background() for Conn.
+	Ctx context.Context
+
+	// Method is the full method name of the call. It is empty for Conn.
+	Method string
+
+	// Stream is true if the connection is picked for NewStream.
+	Stream bool
+
+	// Args is the request message of a unary call.
+	Args interface{}
+
+	// CallOptions are the CallOptions of the call.
+	CallOptions []grpc.CallOption
+
+	// AffinityKey is the affinity key of the call, see WithAffinityFunc and WithRequestHash.
+	// Calls with an affinity key are routed by hashing it instead of by the Picker.
+	AffinityKey string
+
+	// PinKey is the pin key of the call, see ContextWithPinKey.
+	// Calls with a pin key are routed by hashing it over all of the pool's connections.
+	PinKey string
+}
+
+// Picker chooses the connection used for a call.
+//
+// Pick is called concurrently and must be safe for concurrent use.
+type Picker interface {
+	// Pick returns one of conns, which is never empty, for the call described by info.
+	Pick(info PickInfo, conns []*PoolConn) *PoolConn
+}
+
+// PickerFunc adapts an ordinary function to a Picker.
+type PickerFunc func(info PickInfo, conns []*PoolConn) *PoolConn
+
+// Pick calls f(info, conns).
+func (f PickerFunc) Pick(info PickInfo, conns []*PoolConn) *PoolConn {
+	return f(info, conns)
+}
+
+// WithPicker sets the Picker used to choose the connection for a call.
+//
+// The default picks connections in round-robin order.
+func WithPicker(picker Picker) Option {
+	return func(o *options) {
+		o.picker = picker
+	}
+}
+
+// RoundRobin returns a Picker that picks connections in round-robin order.
+func RoundRobin() Picker {
+	return &roundRobinPicker{}
+}
+
+// RoundRobinFrom returns a Picker that picks connections in round-robin order, starting with the connection at
+// index start. Unlike RoundRobin, whose first pick is the second connection, tests can rely on the order.
+func RoundRobinFrom(start int) Picker {
+	return &roundRobinPicker{idx: uint64(start) - 1}
+}
+
+type roundRobinPicker struct {
+	idx uint64                  // access via sync/atomic, 64-bit to never wrap, see roundRobinConnPool
+	_   [cacheLineSize - 8]byte // keeps idx on its own cache line, see roundRobinConnPool
+}
+
+func (p *roundRobinPicker) Pick(_ PickInfo, conns []*PoolConn) *PoolConn {
+	i := atomic.AddUint64(&p.idx, 1)
+	return conns[i%uint64(len(conns))]
+}
+
+// StripedRoundRobin returns a Picker that picks connections in round-robin order per stripe, with stripes local
+// to the processor (P) running the pick. Picks don't touch a shared counter, removing the contention of
+// RoundRobin on machines with many cores, at the cost of a strict global rotation order: each stripe rotates on
+// its own from a different starting connection.
+func StripedRoundRobin() Picker {
+	p := &stripedPicker{}
+	p.stripes.New = func() interface{} {
+		return &stripe{n: uint64(atomic.AddUint32(&p.next, 1))}
+	}
+	return p
+}
+
+type stripedPicker struct {
+	next    uint32    // stripes created, access via sync/atomic
+	stripes sync.Pool // of *stripe, processor-local
+}
+
+// stripe is a round-robin counter owned by the goroutine that got it from stripedPicker.stripes.
+type stripe struct {
+	n uint64
+	_ [cacheLineSize - 8]byte
+}
+
+func (p *stripedPicker) Pick(_ PickInfo, conns []*PoolConn) *PoolConn {
+	s := p.stripes.Get().(*stripe)
+	s.n++
+	c := conns[s.n%uint64(len(conns))]
+	p.stripes.Put(s)
+	return c
+}
+
+// Random returns a Picker that picks connections uniformly at random, from pseudo-random sources local to the
+// processor (P) running the pick. Like StripedRoundRobin it shares no state between picks on different cores, and
+// is the cheapest picker for users who care more about pick throughput than about an even rotation.
+func Random() Picker {
+	p := &randomPicker{}
+	p.sources.New = func() interface{} {
+		// Seed every source differently; xorshift needs a non-zero state.
+		return &randSource{x: (uint64(atomic.AddUint32(&p.next, 1)) * 0x9e3779b97f4a7c15) | 1}
+	}
+	return p
+}
+
+type randomPicker struct {
+	next    uint32    // sources created, access via sync/atomic
+	sources sync.Pool // of *randSource, processor-local
+}
+
+// randSource is a xorshift64* generator owned by the goroutine that got it from randomPicker.sources.
+type randSource struct {
+	x uint64
+	_ [cacheLineSize - 8]byte
+}
+
+func (r *randSource) next() uint64 {
+	r.x ^= r.x >> 12
+	r.x ^= r.x << 25
+	r.x ^= r.x >> 27
+	return r.x * 0x2545f4914f6cdd1d
+}
+
+func (p *randomPicker) Pick(_ PickInfo, conns []*PoolConn) *PoolConn {
+	r := p.sources.Get().(*randSource)
+	// Maps the high bits of the output to [0, len) without a division.
+	hi, _ := bits.Mul64(r.next(), uint64(len(conns)))
+	p.sources.Put(r)
+	return conns[hi]
+}
+
+// WithDeterministicPick picks connections at random from a sequence seeded by seed, so tests asserting on which
+// connection handled a call see the same picks on every run.
+func WithDeterministicPick(seed int64) Option {
+	return WithPicker(&seededPicker{r: rand.New(rand.NewSource(seed))})
+}
+
+type seededPicker struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (p *seededPicker) Pick(_ PickInfo, conns []*PoolConn) *PoolConn {
+	p.mu.Lock()
+	i := p.r.Intn(len(conns))
+	p.mu.Unlock()
+	return conns[i]
+}
+
+// PickerByName returns a new Picker by name, e.g. for config files.
+//
+// Known names are "round_robin", "striped_round_robin" and "random".
+func PickerByName(name string) (Picker, error) {
+	switch name {
+	case "round_robin":
+		return RoundRobin(), nil
+	case "striped_round_robin":
+		return StripedRoundRobin(), nil
+	case "random":
+		return Random(), nil
+	}
+	return nil, fmt.Errorf("grpcpool: unknown picker %q", name)
+}
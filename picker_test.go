@@ -0,0 +1,131 @@
+package grpcpool
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+func testConns(n int) []*PoolConn {
+	conns := make([]*PoolConn, n)
+	for i := range conns {
+		conns[i] = &PoolConn{index: i}
+	}
+	return conns
+}
+
+func pickIndexes(p Picker, conns []*PoolConn, n int) []int {
+	picks := make([]int, n)
+	for i := range picks {
+		picks[i] = p.Pick(PickInfo{}, conns).Index()
+	}
+	return picks
+}
+
+func TestRoundRobinFrom(t *testing.T) {
+	conns := testConns(3)
+	for start, want := range map[int][]int{
+		0: {0, 1, 2, 0},
+		2: {2, 0, 1, 2},
+	} {
+		got := pickIndexes(RoundRobinFrom(start), conns, 4)
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("RoundRobinFrom(%d) picks got %v; want %v", start, got, want)
+				break
+			}
+		}
+	}
+}
+
+func TestDeterministicPick(t *testing.T) {
+	conns := testConns(4)
+	newPicker := func(seed int64) Picker {
+		return newOptions([]Option{WithDeterministicPick(seed)}).picker
+	}
+	a := pickIndexes(newPicker(42), conns, 20)
+	b := pickIndexes(newPicker(42), conns, 20)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("picks with the same seed differ: %v and %v", a, b)
+		}
+	}
+}
+
+func TestStripedRoundRobin(t *testing.T) {
+	conns := testConns(3)
+	p, err := PickerByName("striped_round_robin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make([]int, len(conns))
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, i := range pickIndexes(p, conns, 3000) {
+				mu.Lock()
+				counts[i]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	for i, n := range counts {
+		if n < 3600 || n > 4400 {
+			t.Errorf("conn #%d got %d of 12000 picks; want about 4000", i, n)
+		}
+	}
+}
+
+func TestRandom(t *testing.T) {
+	conns := testConns(3)
+	p, err := PickerByName("random")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make([]int, len(conns))
+	for _, i := range pickIndexes(p, conns, 12000) {
+		counts[i]++
+	}
+	for i, n := range counts {
+		if n < 3600 || n > 4400 {
+			t.Errorf("conn #%d got %d of 12000 picks; want about 4000", i, n)
+		}
+	}
+}
+
+func BenchmarkRoundRobin(b *testing.B) {
+	benchmarkPicker(b, RoundRobin())
+}
+
+func BenchmarkStripedRoundRobin(b *testing.B) {
+	benchmarkPicker(b, StripedRoundRobin())
+}
+
+func BenchmarkRandom(b *testing.B) {
+	benchmarkPicker(b, Random())
+}
+
+func benchmarkPicker(b *testing.B, p Picker) {
+	conns := testConns(4)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			p.Pick(PickInfo{}, conns)
+		}
+	})
+}
+
+func TestRoundRobinPickerWraparound(t *testing.T) {
+	conns := testConns(3)
+	p := &roundRobinPicker{idx: math.MaxUint32 - 2}
+	picks := pickIndexes(p, conns, 6)
+	for i := 1; i < len(picks); i++ {
+		if picks[i] == picks[i-1] {
+			t.Fatalf("picks past 2^32 got %v; want a rotation", picks)
+		}
+	}
+}
@@ -4,10 +4,16 @@ package grpcpool
 import (
 	"context"
 	"errors"
+	"io"
+	"runtime/pprof"
+	"runtime/trace"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 )
 
 // based on https://github.com/googleapis/google-api-go-client/blob/v0.115.0/transport/grpc/pool.go
@@ -35,10 +41,18 @@ type ConnPool interface {
 
 var _ ConnPool = &roundRobinConnPool{}
 
+// cacheLineSize is the size of a CPU cache line on common hardware.
+const cacheLineSize = 64
+
 type roundRobinConnPool struct {
-	conns []*grpc.ClientConn
+	// idx is 64-bit so it never wraps in practice: a wrapping 32-bit counter skews the rotation when the number
+	// of conns isn't a power of two, as 2^32 isn't a multiple of it.
+	idx uint64 // access via sync/atomic; first field to be 64-bit aligned on 32-bit platforms
+	// Keeps the written idx and the read-only conns on separate cache lines, so picks on other cores don't
+	// invalidate the line holding the slice header.
+	_ [cacheLineSize - 8]byte
 
-	idx uint32 // access via sync/atomic
+	conns []*grpc.ClientConn
 }
 
 func (p *roundRobinConnPool) Num() int {
@@ -46,8 +60,11 @@ func (p *roundRobinConnPool) Num() int {
 }
 
 func (p *roundRobinConnPool) Conn() *grpc.ClientConn {
-	i := atomic.AddUint32(&p.idx, 1)
-	return p.conns[i%uint32(len(p.conns))]
+	if len(p.conns) == 1 {
+		return p.conns[0]
+	}
+	i := atomic.AddUint64(&p.idx, 1)
+	return p.conns[i%uint64(len(p.conns))]
 }
 
 func (p *roundRobinConnPool) Close() error {
@@ -96,3 +113,705 @@ func DialContext(ctx context.Context, target string, num uint, opts ...grpc.Dial
 func Dial(target string, num uint, opts ...grpc.DialOption) (ConnPool, error) {
 	return DialContext(context.Background(), target, num, opts...)
 }
+
+var _ ConnPool = &Pool{}
+
+// Pool is a ConnPool dialed from a set of endpoints and configured with Options.
+type Pool struct {
+	opts options
+	set  atomic.Pointer[connSet]
+	rt   atomic.Pointer[runtimeConfig] // see SetPicker
+
+	mu   sync.Mutex // serializes changes to set
+	rtMu sync.Mutex // serializes changes to rt, apart from mu so they don't wait for a draining swap
+
+	sessions atomic.Int64 // bound sessions, see BindSession
+	shutdown atomic.Bool  // see Shutdown
+	closed   atomic.Bool  // see Close, no connections are added once set
+	labels   labelRegistry
+	stops    []func()  // stop the background work of the pool
+	stopOnce sync.Once // the stops run once, also if the pool is closed again
+}
+
+// connSet is an immutable snapshot of the connections in a Pool.
+type connSet struct {
+	conns  []*PoolConn
+	all    *connGroup            // all conns
+	groups map[string]*connGroup // conns by PoolConn.Group
+
+	active atomic.Int64 // in-flight calls and open streams on conns
+
+	healthMu  sync.Mutex         // serializes refreshes of the healthy snapshots, see watchHealth
+	stopWatch context.CancelFunc // stops the health monitor, nil if not running
+
+	// prev is the set s was grown from, which may still count calls on its conns, see WithStreamGrowth. It is
+	// unlinked once it has none, see activeCalls.
+	prev atomic.Pointer[connSet]
+}
+
+// PoolConn is a connection in a Pool.
+type PoolConn struct {
+	cc       *grpc.ClientConn
+	endpoint Endpoint
+	index    int
+	group    string
+
+	cache    *ConnCache // set if cc is shared through a ConnCache
+	cacheKey connCacheKey
+
+	inflight atomic.Int64 // in-flight calls and open streams
+	streams  atomic.Int64 // open streams
+	sending  atomic.Int64 // open streams still sending, see StreamLoad
+	calls    atomic.Int64 // finished calls and streams
+	errors   atomic.Int64 // finished calls and streams with an error
+
+	utilization atomic.Uint64 // float64 bits of the last ORCA utilization, see WithORCA
+	pprofLabels pprof.LabelSet
+	connID      string            // see WithConnIDHeader
+	baggage     map[string]string // see WithBaggage
+	traffic     *connTraffic      // see WithThroughputGrowth
+
+	certExpiry     atomic.Int64           // unix nanoseconds of the certificate expiry, see WithCertExpiryMonitor
+	replacedExpiry int64                  // certificate expiry of the conn this one re-dialed
+	principal      atomic.Pointer[string] // server principal, see WithAudit
+	dials          *connDials             // see WithDialMetrics
+	peerAddr       atomic.Pointer[string] // remote address of the transport, see WithPeerAddrs
+	stateSince     atomic.Int64           // unix nanoseconds of the last state change, see StateSince
+}
+
+// ClientConn returns the underlying grpc.ClientConn.
+func (c *PoolConn) ClientConn() *grpc.ClientConn {
+	return c.cc
+}
+
+// Endpoint returns the endpoint the connection was dialed to.
+func (c *PoolConn) Endpoint() Endpoint {
+	return c.endpoint
+}
+
+// Index returns the position of the connection in the pool.
+func (c *PoolConn) Index() int {
+	return c.index
+}
+
+// Group returns the name of the connection group the connection belongs to, e.g. CanaryGroup.
+//
+// Connections not in a group return the empty string.
+func (c *PoolConn) Group() string {
+	return c.group
+}
+
+// InFlight returns the number of in-flight calls and open streams on the connection.
+func (c *PoolConn) InFlight() int {
+	return int(c.inflight.Load())
+}
+
+// ActiveStreams returns the number of streams open on the connection. A stream is open until RecvMsg returns an
+// error, io.EOF at its end, or its context is done.
+func (c *PoolConn) ActiveStreams() int {
+	return int(c.streams.Load())
+}
+
+// Stats returns the call counters of the connection. CallStats.Conns is one.
+func (c *PoolConn) Stats() CallStats {
+	return CallStats{
+		Conns:    1,
+		Calls:    c.calls.Load(),
+		Errors:   c.errors.Load(),
+		InFlight: c.inflight.Load(),
+		Streams:  c.StreamLoad(),
+	}
+}
+
+// State returns the connectivity state of the connection.
+func (c *PoolConn) State() connectivity.State {
+	return c.cc.GetState()
+}
+
+// Healthy reports whether the connection is usable, i.e. not in TRANSIENT_FAILURE or SHUTDOWN.
+func (c *PoolConn) Healthy() bool {
+	return ccHealthy(c.cc)
+}
+
+// ccHealthy reports whether cc is usable, see PoolConn.Healthy.
+func ccHealthy(cc *grpc.ClientConn) bool {
+	switch cc.GetState() {
+	case connectivity.TransientFailure, connectivity.Shutdown:
+		return false
+	}
+	return true
+}
+
+func (s *connSet) close() error {
+	var errs error
+	for _, c := range s.conns {
+		if err := c.close(); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	if s.stopWatch != nil {
+		s.stopWatch()
+		s.refreshHealth()
+	}
+	return errs
+}
+
+// NewPool creates a new Pool with connections to target.
+//
+// Unless WithSize is given the pool has a single connection.
+func NewPool(ctx context.Context, target string, opts ...Option) (*Pool, error) {
+	return NewEndpointPool(ctx, []Endpoint{{Addr: target}}, opts...)
+}
+
+// NewEndpointPool creates a new Pool with connections to the given endpoints.
+func NewEndpointPool(ctx context.Context, endpoints []Endpoint, opts ...Option) (*Pool, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("grpcpool: no endpoints")
+	}
+	p := &Pool{opts: newOptions(opts)}
+	if err := p.opts.checkCompression(); err != nil {
+		return nil, err
+	}
+	if err := p.opts.checkDialer(); err != nil {
+		return nil, err
+	}
+	p.rt.Store(p.opts.runtime())
+	if p.opts.allowed != nil || p.opts.denied != nil {
+		p.opts.dialOpts = append(p.aclDialOptions(), p.opts.dialOpts...) // first, so denied calls reach no interceptor
+	}
+	if p.opts.subsetSize > 0 {
+		endpoints = Subset(endpoints, p.opts.subsetIndex, p.opts.subsetSize)
+	}
+	if p.opts.certs != nil {
+		if err := p.opts.certs.load(); err != nil {
+			return nil, err
+		}
+	}
+	if p.opts.goAway != nil {
+		p.stops = append(p.stops, p.startGoAway()) // before dialing, the conns are watched from their dial
+	}
+	s, err := p.dial(ctx, endpoints)
+	if err != nil {
+		return nil, err
+	}
+	p.set.Store(s)
+	p.restoreSize(ctx)
+	if p.opts.throughput != nil {
+		p.stops = append(p.stops, p.startThroughputGrowth())
+	}
+	if p.opts.certs != nil {
+		p.stops = append(p.stops, p.startCertificateRotation())
+	}
+	if p.opts.certExpiry != nil {
+		p.stops = append(p.stops, p.startCertExpiryMonitor())
+	}
+	if p.opts.warmPings != nil {
+		p.stops = append(p.stops, p.startWarmPings())
+	}
+	if p.opts.streamLoad != nil {
+		p.stops = append(p.stops, p.startStreamLoadReport())
+	}
+	if p.opts.audit != nil {
+		p.stops = append(p.stops, p.opts.audit.run())
+	}
+	return p, nil
+}
+
+// dial creates a connSet with the configured number of connections spread over endpoints.
+func (p *Pool) dial(ctx context.Context, endpoints []Endpoint) (*connSet, error) {
+	num := p.opts.size
+	if num <= 0 {
+		num = len(endpoints)
+	}
+	if p.opts.migration != nil {
+		if err := p.opts.migration.reserve(&p.opts, num); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.opts.validateGroups(num); err != nil {
+		return nil, err
+	}
+	if err := p.opts.routes.validate(p.opts.groups); err != nil {
+		return nil, err
+	}
+	if p.opts.canary != nil {
+		if err := p.opts.canary.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if p.opts.egress != nil {
+		if err := p.opts.egress.validate(); err != nil {
+			return nil, err
+		}
+	}
+	return p.dialSize(ctx, endpoints, num)
+}
+
+// dialSize creates a connSet with num connections spread over endpoints.
+func (p *Pool) dialSize(ctx context.Context, endpoints []Endpoint, num int) (*connSet, error) {
+	conns := make([]*PoolConn, 0, num)
+	seen := map[connCacheKey]int{} // conns per endpoint and group, for the conn cache
+	for i := 0; i < num; i++ {
+		e := endpoints[i%len(endpoints)]
+		if e.Zone == "" && p.opts.zoneFunc != nil {
+			e.Zone = p.opts.zoneFunc(e)
+		}
+		if e.Authority == "" && p.opts.authorityFunc != nil {
+			e.Authority = p.opts.authorityFunc(e)
+		}
+		group := p.opts.groupOf(i, num)
+		c := &PoolConn{endpoint: e, index: i, group: group}
+		if p.opts.connCache != nil {
+			slot := connCacheKey{key: p.opts.connCacheKey, addr: e.Addr, authority: e.Authority, group: group}
+			c.cache = p.opts.connCache
+			c.cacheKey = slot
+			c.cacheKey.n = seen[slot]
+			seen[slot]++
+		}
+		if err := p.newConn(ctx, c); err != nil {
+			(&connSet{conns: conns}).close()
+			return nil, err
+		}
+		conns = append(conns, c)
+		if p.opts.failover != nil {
+			// Keep connections to every tier warm so failover doesn't have to wait for a dial.
+			c.cc.Connect()
+		}
+	}
+	return p.newConnSet(conns), nil
+}
+
+// newConn sets up c with the per-connection options, dials it and starts watching it. Every connection of the
+// pool is dialed through newConn.
+func (p *Pool) newConn(ctx context.Context, c *PoolConn) error {
+	p.opts.setProfilerLabels(c)
+	p.opts.setConnID(c)
+	p.opts.setBaggage(c)
+	p.opts.setTraffic(c)
+	p.opts.setDials(c)
+	dialOpts, err := p.opts.connDialOptions(c)
+	if err != nil {
+		return err
+	}
+	r := trace.StartRegion(ctx, traceDial)
+	err = c.dial(ctx, dialOpts)
+	r.End()
+	if err != nil {
+		return err
+	}
+	p.watchGoAway(c)
+	p.watchDials(c)
+	p.watchState(c)
+	return nil
+}
+
+// newConnSet creates a connSet from conns.
+func (p *Pool) newConnSet(conns []*PoolConn) *connSet {
+	s := &connSet{conns: conns, all: p.newGroup(conns), groups: map[string]*connGroup{}}
+	byGroup := map[string][]*PoolConn{}
+	for _, c := range conns {
+		byGroup[c.group] = append(byGroup[c.group], c)
+	}
+	for name, conns := range byGroup {
+		s.groups[name] = p.newGroup(conns)
+	}
+	if p.opts.failover != nil || p.opts.locality != nil {
+		s.watchHealth()
+	}
+	return s
+}
+
+// connGroup is a group of connections calls are routed to.
+type connGroup struct {
+	conns []*PoolConn
+	tiers []*tier // conns grouped by priority, see WithPriorityFailover
+}
+
+func (p *Pool) newGroup(conns []*PoolConn) *connGroup {
+	g := &connGroup{conns: conns}
+	if p.opts.failover == nil {
+		g.tiers = []*tier{p.newTier(0, conns)}
+	} else {
+		g.tiers = p.newTiers(conns)
+	}
+	return g
+}
+
+// group returns the connections in s the call described by info is routed to.
+func (p *Pool) group(s *connSet, info PickInfo) *connGroup {
+	if g, ok := s.groups[p.groupName(info)]; ok {
+		return g
+	}
+	return s.all
+}
+
+// groupName returns the name of the group the call described by info is routed to.
+func (p *Pool) groupName(info PickInfo) string {
+	if p.opts.isBulk(info) {
+		return BulkGroup
+	}
+	if name, ok := p.opts.routes.lookup(info.Method); ok {
+		return name
+	}
+	if info.Stream && p.opts.streamConns {
+		return StreamGroup
+	}
+	if p.opts.canary != nil && p.opts.canary.pick() {
+		return CanaryGroup
+	}
+	if p.opts.migration != nil && p.opts.migration.pick() {
+		return MigrationGroup
+	}
+	return ""
+}
+
+// pick returns the connection from s to use for the call described by info.
+func (p *Pool) pick(s *connSet, info PickInfo) *PoolConn {
+	if len(s.conns) == 1 {
+		return s.conns[0] // nothing to choose from, skip the picker
+	}
+	g := p.group(s, info)
+	if info.PinKey != "" {
+		return rendezvous(info.PinKey, g.conns)
+	}
+	t := g.tiers[0]
+	if p.opts.failover != nil {
+		t = p.opts.failover.choose(g.tiers)
+	}
+	conns := t.conns
+	if p.opts.locality != nil {
+		conns = p.opts.locality.candidates(t)
+	}
+	if info.AffinityKey != "" {
+		return rendezvous(info.AffinityKey, conns)
+	}
+	return p.runtime().picker.Pick(info, conns)
+}
+
+func (p *Pool) pickInfo(ctx context.Context, method string, stream bool, args interface{}, opts []grpc.CallOption) PickInfo {
+	info := PickInfo{Ctx: ctx, Method: method, Stream: stream, Args: args, CallOptions: opts}
+	if len(p.set.Load().conns) == 1 {
+		return info // the keys can't change the pick
+	}
+	info.PinKey = pinKeyFromContext(ctx)
+	if info.PinKey == "" {
+		info.AffinityKey, _ = p.opts.affinityKey(ctx)
+	}
+	if info.PinKey == "" && info.AffinityKey == "" {
+		info.AffinityKey, _ = p.opts.requestKey(method, args)
+	}
+	return info
+}
+
+// Conn returns a ClientConn from the pool.
+func (p *Pool) Conn() *grpc.ClientConn {
+	return p.pick(p.set.Load(), PickInfo{Ctx: context.Background()}).cc
+}
+
+// Num returns the number of connections in the pool.
+func (p *Pool) Num() int {
+	return len(p.set.Load().conns)
+}
+
+// Conns returns the connections in the pool.
+func (p *Pool) Conns() []*PoolConn {
+	s := p.set.Load()
+	conns := make([]*PoolConn, len(s.conns))
+	copy(conns, s.conns)
+	return conns
+}
+
+// Healthy reports whether the pool has at least one healthy connection, see PoolConn.Healthy.
+func (p *Pool) Healthy() bool {
+	for _, c := range p.set.Load().conns {
+		if c.Healthy() {
+			return true
+		}
+	}
+	return false
+}
+
+// Endpoints returns the endpoint of every connection in the pool.
+func (p *Pool) Endpoints() []Endpoint {
+	return p.set.Load().endpoints()
+}
+
+// errPoolClosed is returned when connections would be added to a closed pool.
+var errPoolClosed = errors.New("grpcpool: pool is closed")
+
+// Close closes every ClientConn in the pool right away, ending open calls and streams. See Shutdown.
+func (p *Pool) Close() error {
+	p.closed.Store(true)
+	p.stopOnce.Do(func() {
+		for _, stop := range p.stops {
+			stop()
+		}
+	})
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.set.Load().close()
+}
+
+// acquire returns the current connSet with its active count incremented.
+//
+// The set is re-checked after incrementing so that a set swapped out concurrently is never used once the
+// swap started draining it.
+func (p *Pool) acquire() *connSet {
+	for {
+		s := p.set.Load()
+		s.active.Add(1)
+		if p.set.Load() == s {
+			return s
+		}
+		s.active.Add(-1)
+	}
+}
+
+// conn returns the connection to use for the call described by info, and its connSet with the active count
+// incremented.
+func (p *Pool) conn(info PickInfo) (*connSet, *PoolConn) {
+	if sess := p.session(info.Ctx); sess != nil && sess.acquire() {
+		return sess.set, sess.conn
+	}
+	s := p.acquire()
+	return s, p.pick(s, info)
+}
+
+func (p *Pool) Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error {
+	if err := p.runtime().checkMethod(method); err != nil {
+		return err
+	}
+	if err := p.checkShutdown(); err != nil {
+		return err
+	}
+	if ctx, cancel := p.opts.callContext(ctx, method); cancel != nil {
+		defer cancel()
+		return p.coalesce(ctx, method, args, reply, opts)
+	}
+	return p.coalesce(ctx, method, args, reply, opts)
+}
+
+func (p *Pool) invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts []grpc.CallOption) error {
+	ctx, end := traceTask(ctx, traceInvoke)
+	defer end()
+	opts, buf := p.opts.callDefaults.merge(opts)
+	defer p.opts.callDefaults.release(buf)
+	r := trace.StartRegion(ctx, traceAdmit)
+	done, err := p.runtime().admit(ctx, opts)
+	r.End()
+	if err != nil {
+		return err
+	}
+	defer done()
+	r = trace.StartRegion(ctx, tracePick)
+	s, c, err := p.reserve(p.pickInfo(ctx, method, false, args, opts), nil)
+	r.End()
+	if err != nil {
+		return err
+	}
+	defer s.active.Add(-1)
+	p.checkGrowth(c)
+	lc := p.labels.start(ctx)
+	start := p.opts.audit.start(p.opts.clock)
+	r = trace.StartRegion(ctx, traceRPC)
+	c, err = p.retryUnsent(ctx, s, c, method, args, reply, opts)
+	r.End()
+	c.finish(err)
+	lc.finish(err)
+	p.opts.audit.record(p.opts.clock, c, method, start, err)
+	return err
+}
+
+// attempt makes the unary call on c with the context of c.
+func (p *Pool) attempt(ctx context.Context, c *PoolConn, method string, args interface{}, reply interface{}, opts []grpc.CallOption) (err error) {
+	p.opts.withProfilerLabels(p.opts.connContext(ctx, c), c, func(ctx context.Context) {
+		err = p.call(ctx, c, method, args, reply, opts)
+	})
+	return err
+}
+
+// connContext returns the context of a call or stream on c.
+func (o *options) connContext(ctx context.Context, c *PoolConn) context.Context {
+	return o.withBaggage(o.withConnID(ctx, c), c)
+}
+
+// call makes a unary call on c.
+func (p *Pool) call(ctx context.Context, c *PoolConn, method string, args interface{}, reply interface{}, opts []grpc.CallOption) error {
+	if err := p.opts.chaos.inject(ctx, c, p.opts.clock); err != nil {
+		return err
+	}
+	if p.opts.perRPC != nil {
+		return p.opts.perRPC.invoke(ctx, opts, func(opts []grpc.CallOption) error {
+			return p.invokeConn(ctx, c, method, args, reply, opts)
+		})
+	}
+	return p.invokeConn(ctx, c, method, args, reply, opts)
+}
+
+// invokeConn sends a unary call on c.
+func (p *Pool) invokeConn(ctx context.Context, c *PoolConn, method string, args interface{}, reply interface{}, opts []grpc.CallOption) error {
+	if p.opts.orca {
+		return c.invokeORCA(ctx, method, args, reply, opts)
+	}
+	return c.cc.Invoke(ctx, method, args, reply, opts...)
+}
+
+// finish records the end of a call or stream on c.
+func (c *PoolConn) finish(err error) {
+	c.inflight.Add(-1)
+	c.calls.Add(1)
+	if err != nil {
+		c.errors.Add(1)
+	}
+}
+
+// streamCall tracks a stream from NewStream until it finishes. It is a single allocation holding everything
+// the release of the stream needs, and wraps the grpc.ClientStream to see it end.
+type streamCall struct {
+	grpc.ClientStream
+	once  sync.Once
+	set   *connSet
+	conn  *PoolConn
+	label *labelCounters
+	done  func()
+	opts  [4]grpc.CallOption // backs the CallOptions of streams with few options
+
+	sending atomic.Bool // counted in conn.sending
+
+	pool   *Pool // set if audited or hooked
+	method string
+	start  time.Time
+}
+
+// callOptions returns the default CallOptions followed by opts, with room for the OnFinish option of sc.
+func (sc *streamCall) callOptions(d *callDefaults, opts []grpc.CallOption) []grpc.CallOption {
+	defaults := d.defaults()
+	if n := len(defaults) + len(opts); n < len(sc.opts) {
+		return append(append(sc.opts[:0], defaults...), opts...)
+	} else if len(defaults) == 0 {
+		return opts[:n:n] // appending OnFinish copies opts
+	}
+	merged := make([]grpc.CallOption, 0, len(defaults)+len(opts)+1)
+	return append(append(merged, defaults...), opts...)
+}
+
+// RecvMsg releases the stream once it ended, without waiting for gRPC to finish it.
+func (sc *streamCall) RecvMsg(m interface{}) error {
+	err := sc.ClientStream.RecvMsg(m)
+	if err == io.EOF {
+		sc.release(nil)
+	} else if err != nil {
+		sc.release(err)
+	}
+	return err
+}
+
+// CloseSend stops counting the stream as sending.
+func (sc *streamCall) CloseSend() error {
+	sc.stopSending()
+	return sc.ClientStream.CloseSend()
+}
+
+func (sc *streamCall) stopSending() {
+	if sc.sending.CompareAndSwap(true, false) {
+		sc.conn.sending.Add(-1)
+	}
+}
+
+// release records the end of the stream, once.
+func (sc *streamCall) release(err error) {
+	sc.once.Do(func() {
+		sc.stopSending()
+		sc.conn.streams.Add(-1)
+		sc.conn.finish(err)
+		sc.label.finish(err)
+		sc.set.active.Add(-1)
+		sc.done()
+		if sc.pool != nil {
+			sc.pool.opts.audit.record(sc.pool.opts.clock, sc.conn, sc.method, sc.start, err)
+			sc.pool.streamEnd(sc, err)
+		}
+	})
+}
+
+func (p *Pool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if err := p.runtime().checkMethod(method); err != nil {
+		return nil, err
+	}
+	if err := p.checkShutdown(); err != nil {
+		return nil, err
+	}
+	if p.opts.streamRetry > 0 {
+		return p.newRetryStream(ctx, desc, method, opts)
+	}
+	sc, err := p.newStream(ctx, desc, method, opts, nil)
+	if err != nil {
+		return nil, err
+	}
+	return sc, nil
+}
+
+// newStream opens a stream, on another connection than avoid if possible. If it fails once a connection was picked,
+// the released streamCall is returned with the error.
+func (p *Pool) newStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts []grpc.CallOption, avoid *PoolConn) (*streamCall, error) {
+	tctx, end := traceTask(ctx, traceNewStream)
+	defer end()
+	sc := &streamCall{}
+	opts = sc.callOptions(p.opts.callDefaults, opts)
+	r := trace.StartRegion(tctx, traceAdmit)
+	done, err := p.runtime().admit(ctx, opts)
+	r.End()
+	if err != nil {
+		return nil, err
+	}
+	r = trace.StartRegion(tctx, tracePick)
+	s, c, err := p.reserve(p.pickInfo(ctx, method, true, nil, opts), avoid)
+	r.End()
+	if err != nil {
+		done()
+		return nil, err
+	}
+	c.streams.Add(1)
+	p.checkGrowth(c)
+	lc := p.labels.start(ctx)
+	sc.set, sc.conn, sc.label, sc.done = s, c, lc, done
+	if desc.ClientStreams {
+		sc.sending.Store(true)
+		c.sending.Add(1)
+	}
+	if p.opts.audit != nil || p.opts.streamHooks != nil {
+		sc.pool, sc.method, sc.start = p, method, p.opts.clock.Now()
+		p.streamStart(sc)
+	}
+	release := sc.release
+	credsOpt, err := p.opts.perRPC.streamOption(ctx)
+	if err != nil {
+		release(err)
+		return sc, err
+	}
+	if credsOpt != nil {
+		opts = append(opts, credsOpt)
+	}
+	opts = append(opts, grpc.OnFinish(release))
+	var cs grpc.ClientStream
+	p.opts.withProfilerLabels(p.opts.connContext(ctx, c), c, func(ctx context.Context) {
+		if err = p.opts.chaos.inject(ctx, c, p.opts.clock); err == nil {
+			cs, err = c.cc.NewStream(ctx, desc, method, opts...)
+		}
+	})
+	if err != nil {
+		release(err)
+		return sc, err
+	}
+	sc.ClientStream = cs
+	return sc, nil
+}
+
+// ActiveStreams returns the number of streams open on the connections of the pool, see PoolConn.ActiveStreams.
+func (p *Pool) ActiveStreams() int {
+	n := 0
+	for _, c := range p.set.Load().conns {
+		n += c.ActiveStreams()
+	}
+	return n
+}
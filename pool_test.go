@@ -1,10 +1,16 @@
 package grpcpool
 
 import (
+	"context"
+	"math"
 	"net"
 	"testing"
+	"time"
+	"unsafe"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 func TestPool(t *testing.T) {
@@ -64,3 +70,134 @@ func mockServer(t *testing.T) (*grpc.Server, net.Listener) {
 
 	return s, l
 }
+
+// waitForState connects cc and waits for it to enter state.
+func waitForState(t *testing.T, cc *grpc.ClientConn, state connectivity.State) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cc.Connect()
+	for s := cc.GetState(); s != state; s = cc.GetState() {
+		if !cc.WaitForStateChange(ctx, s) {
+			t.Fatalf("conn never entered %v, last state %v", state, s)
+		}
+	}
+}
+
+// nopInvoker answers every call without a server, to benchmark the pool alone.
+func nopInvoker(context.Context, string, interface{}, interface{}, *grpc.ClientConn, grpc.UnaryInvoker, ...grpc.CallOption) error {
+	return nil
+}
+
+func BenchmarkPoolConn(b *testing.B) {
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithSize(4),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer pool.Close()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			pool.Conn()
+		}
+	})
+}
+
+func BenchmarkPoolInvoke(b *testing.B) {
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithSize(4),
+		WithDialOptions(
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithUnaryInterceptor(nopInvoker),
+		),
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer pool.Close()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			pool.Invoke(context.Background(), "/test.Test/Call", nil, nil)
+		}
+	})
+}
+
+func TestRoundRobinConnPoolLayout(t *testing.T) {
+	var p roundRobinConnPool
+	if off := unsafe.Offsetof(p.conns); off < cacheLineSize {
+		t.Errorf("conns offset got %d; want at least %d to keep it off the cache line of idx", off, cacheLineSize)
+	}
+}
+
+func BenchmarkRoundRobinConnPool(b *testing.B) {
+	pool := &roundRobinConnPool{conns: make([]*grpc.ClientConn, 4)}
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			pool.Conn()
+		}
+	})
+}
+
+func TestSingleConnFastPath(t *testing.T) {
+	conn := &grpc.ClientConn{}
+	legacy := &roundRobinConnPool{conns: []*grpc.ClientConn{conn}}
+	for i := 0; i < 3; i++ {
+		if legacy.Conn() != conn {
+			t.Fatal("legacy single-conn pool returned another conn")
+		}
+	}
+	if legacy.idx != 0 {
+		t.Errorf("legacy single-conn pool counter got %d; want untouched", legacy.idx)
+	}
+
+	picked := false
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithPicker(PickerFunc(func(_ PickInfo, conns []*PoolConn) *PoolConn {
+			picked = true
+			return conns[0]
+		})),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithUnaryInterceptor(nopInvoker)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	pool.Conn()
+	if err := pool.Invoke(context.Background(), "/test.Test/Call", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if picked {
+		t.Error("single-conn pool called the picker")
+	}
+	if calls := pool.Conns()[0].Stats().Calls; calls != 1 {
+		t.Errorf("single conn calls got %d; want 1", calls)
+	}
+}
+
+func BenchmarkSingleConnPool(b *testing.B) {
+	pool := &roundRobinConnPool{conns: make([]*grpc.ClientConn, 1)}
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			pool.Conn()
+		}
+	})
+}
+
+func TestRoundRobinWraparound(t *testing.T) {
+	conns := []*grpc.ClientConn{{}, {}, {}}
+	// Past 2^32 picks, where a 32-bit counter wraps and 2^32%3 != 0 repeated a conn.
+	pool := &roundRobinConnPool{conns: conns, idx: math.MaxUint32 - 2}
+	prev := pool.Conn()
+	for i := 0; i < 6; i++ {
+		got := pool.Conn()
+		if got == prev {
+			t.Fatalf("pick #%d repeated the previous conn", i)
+		}
+		prev = got
+	}
+}
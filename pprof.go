@@ -0,0 +1,35 @@
+package grpcpool
+
+import (
+	"context"
+	"runtime/pprof"
+	"strconv"
+)
+
+// WithProfilerLabels attaches the pprof labels grpcpool_target=target and grpcpool_conn=<index> around the
+// client-side work of every call and stream, so CPU profiles of the process can be sliced by pool and connection,
+// e.g. with `go tool pprof -tagfocus grpcpool_target=billing`.
+//
+// Streams are labeled while they are created, which covers the goroutines gRPC starts for them, but not the
+// SendMsg and RecvMsg calls made by the application.
+func WithProfilerLabels(target string) Option {
+	return func(o *options) {
+		o.pprofTarget = target
+	}
+}
+
+// setProfilerLabels sets the pprof labels of c, see WithProfilerLabels.
+func (o *options) setProfilerLabels(c *PoolConn) {
+	if o.pprofTarget != "" {
+		c.pprofLabels = pprof.Labels("grpcpool_target", o.pprofTarget, "grpcpool_conn", strconv.Itoa(c.index))
+	}
+}
+
+// withProfilerLabels runs f with the pprof labels of c, if any.
+func (o *options) withProfilerLabels(ctx context.Context, c *PoolConn, f func(context.Context)) {
+	if o.pprofTarget == "" {
+		f(ctx)
+		return
+	}
+	pprof.Do(ctx, c.pprofLabels, f)
+}
@@ -0,0 +1,40 @@
+package grpcpool
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestProfilerLabels(t *testing.T) {
+	_, l := mockServer(t)
+	var target, conn string
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(2),
+		WithProfilerLabels("billing"),
+		WithDialOptions(
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+				target, _ = pprof.Label(ctx, "grpcpool_target")
+				conn, _ = pprof.Label(ctx, "grpcpool_conn")
+				return nil
+			}),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	for _, want := range []string{"1", "0"} {
+		if err := pool.Invoke(context.Background(), "/test.Test/Call", nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if target != "billing" || conn != want {
+			t.Errorf("call labels got grpcpool_target=%q grpcpool_conn=%q; want billing, %s", target, conn, want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package grpcpool
+
+import (
+	"sort"
+	"sync/atomic"
+)
+
+// tier is a group of connections with the same priority.
+type tier struct {
+	priority int
+	conns    []*PoolConn
+	local    []*PoolConn // conns in the local zone, see WithLocality
+
+	healthy    atomic.Pointer[[]*PoolConn] // healthy conns, see watchHealth
+	healthyLoc atomic.Pointer[[]*PoolConn] // healthy local conns
+}
+
+type failover struct {
+	minHealthy float64
+}
+
+// WithPriorityFailover routes calls by the priority tier of their endpoint, see Endpoint.Priority.
+//
+// Calls use the most preferred tier with at least a minHealthy fraction of healthy connections, similar to Envoy
+// priority levels. Connections to less preferred tiers are dialed eagerly so they are warm when traffic fails over.
+// If no tier meets the threshold, calls use the most preferred tier with any healthy connection.
+func WithPriorityFailover(minHealthy float64) Option {
+	return func(o *options) {
+		o.failover = &failover{minHealthy: minHealthy}
+	}
+}
+
+func (p *Pool) newTier(prio int, conns []*PoolConn) *tier {
+	t := &tier{priority: prio, conns: conns}
+	if p.opts.locality != nil {
+		for _, c := range conns {
+			if c.endpoint.Zone == p.opts.locality.Zone {
+				t.local = append(t.local, c)
+			}
+		}
+	}
+	return t
+}
+
+// newTiers groups conns by priority, most preferred first.
+func (p *Pool) newTiers(conns []*PoolConn) []*tier {
+	byPriority := map[int][]*PoolConn{}
+	for _, c := range conns {
+		byPriority[c.endpoint.Priority] = append(byPriority[c.endpoint.Priority], c)
+	}
+	tiers := make([]*tier, 0, len(byPriority))
+	for prio, conns := range byPriority {
+		tiers = append(tiers, p.newTier(prio, conns))
+	}
+	sort.Slice(tiers, func(i, j int) bool {
+		return tiers[i].priority < tiers[j].priority
+	})
+	return tiers
+}
+
+// choose returns the tier calls are routed to.
+func (f *failover) choose(tiers []*tier) *tier {
+	var fallback *tier
+	for _, t := range tiers {
+		healthy := len(t.healthyConns())
+		if healthy > 0 && float64(healthy) >= f.minHealthy*float64(len(t.conns)) {
+			return t
+		}
+		if healthy > 0 && fallback == nil {
+			fallback = t
+		}
+	}
+	if fallback != nil {
+		return fallback
+	}
+	return tiers[0]
+}
@@ -0,0 +1,63 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestPriorityFailover(t *testing.T) {
+	_, regional := mockServer(t)
+	_, global := mockServer(t)
+
+	pool, err := NewEndpointPool(context.Background(), []Endpoint{
+		{Addr: global.Addr().String(), Priority: 2},
+		{Addr: "localhost:1", Priority: 0},
+		{Addr: regional.Addr().String(), Priority: 1},
+	},
+		WithPriorityFailover(1),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	localConn, regionalConn, globalConn := pool.Conns()[1], pool.Conns()[2], pool.Conns()[0]
+	waitForState(t, localConn.ClientConn(), connectivity.TransientFailure)
+	waitForState(t, globalConn.ClientConn(), connectivity.Ready)
+	waitForSnapshot(t, pool)
+
+	for i := 0; i < 10; i++ {
+		if got := pool.Conn(); got != regionalConn.ClientConn() {
+			t.Fatalf("pool.Conn() #%d got %v; want the regional conn", i, got)
+		}
+	}
+}
+
+func TestPriorityPreferred(t *testing.T) {
+	_, local := mockServer(t)
+	_, regional := mockServer(t)
+
+	pool, err := NewEndpointPool(context.Background(), []Endpoint{
+		{Addr: regional.Addr().String(), Priority: 1},
+		{Addr: local.Addr().String()},
+	},
+		WithPriorityFailover(0.5),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	localConn := pool.Conns()[1]
+	for i := 0; i < 10; i++ {
+		if got := pool.Conn(); got != localConn.ClientConn() {
+			t.Fatalf("pool.Conn() #%d got %v; want the local conn", i, got)
+		}
+	}
+}
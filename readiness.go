@@ -0,0 +1,121 @@
+package grpcpool
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// ReadinessCheck is a pool checked by ReadinessChecksHandler.
+type ReadinessCheck struct {
+	// Name identifies the pool in the response body.
+	Name string
+
+	Pool ConnPool
+
+	// MinHealthy is the fraction of the connections of the pool that must be READY. Zero requires one READY
+	// connection.
+	MinHealthy float64
+}
+
+// ReadinessHandler returns a Kubernetes readiness-probe handler answering 200 OK while every pool has a READY
+// connection, and 503 Service Unavailable otherwise, so pods don't take traffic before their outbound pools are
+// usable. See ReadinessChecksHandler to check a subset of the pools or set health thresholds.
+func ReadinessHandler(pools ...ConnPool) http.Handler {
+	checks := make([]ReadinessCheck, len(pools))
+	for i, p := range pools {
+		checks[i] = ReadinessCheck{Name: fmt.Sprintf("pool %d", i), Pool: p}
+	}
+	return ReadinessChecksHandler(checks...)
+}
+
+// ReadinessChecksHandler returns a readiness-probe handler answering 200 OK while every check passes, and 503
+// Service Unavailable naming the failing ones otherwise. Pools not checked don't affect readiness.
+//
+// Only connections in the READY state count: IDLE and CONNECTING ones, e.g. right after NewPool or while dialing an
+// unreachable backend, can't serve calls yet. Checking asks connections that aren't READY to connect. The readiness
+// of a Pool, a ReloadablePool, a TreePool and a ConnPool created by New or DialContext is that of their connections;
+// other ConnPools are always ready.
+func ReadinessChecksHandler(checks ...ReadinessCheck) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		var failing []string
+		for _, c := range checks {
+			if msg, ok := c.check(); !ok {
+				failing = append(failing, msg)
+			}
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if len(failing) > 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintln(w, strings.Join(failing, "\n"))
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+}
+
+// check reports whether the pool of c is ready, and why not.
+func (c ReadinessCheck) check() (string, bool) {
+	ready, total := countConns(c.Pool, ccReady)
+	want := healthWant(total, c.MinHealthy)
+	if ready >= want && ready > 0 {
+		return "", true
+	}
+	return fmt.Sprintf("%s: %d of %d connections ready, want %d", c.Name, ready, total, want), false
+}
+
+// healthWant returns the number of healthy connections of total that meet minHealthy, at least one.
+func healthWant(total int, minHealthy float64) int {
+	if minHealthy > 0 {
+		return int(math.Ceil(minHealthy * float64(total)))
+	}
+	return 1
+}
+
+// ccReady reports whether cc is READY, and asks it to connect if not.
+func ccReady(cc *grpc.ClientConn) bool {
+	if cc.GetState() == connectivity.Ready {
+		return true
+	}
+	cc.Connect()
+	return false
+}
+
+// connHealth returns the number of healthy connections of p and its number of connections.
+func connHealth(p ConnPool) (healthy, total int) {
+	return countConns(p, ccHealthy)
+}
+
+// countConns returns the number of connections of p for which ok returns true and its number of connections.
+func countConns(p ConnPool, ok func(*grpc.ClientConn) bool) (n, total int) {
+	switch p := p.(type) {
+	case *ReloadablePool:
+		return countConns(p.Pool(), ok)
+	case *Pool:
+		conns := p.set.Load().conns
+		for _, c := range conns {
+			if ok(c.cc) {
+				n++
+			}
+		}
+		return n, len(conns)
+	case *TreePool:
+		for _, c := range p.children {
+			m, t := countConns(c.Pool, ok)
+			n, total = n+m, total+t
+		}
+		return n, total
+	case *roundRobinConnPool:
+		for _, cc := range p.conns {
+			if ok(cc) {
+				n++
+			}
+		}
+		return n, len(p.conns)
+	}
+	return 1, 1
+}
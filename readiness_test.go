@@ -0,0 +1,64 @@
+package grpcpool
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestReadinessHandler(t *testing.T) {
+	_, l := mockServer(t)
+	creds := WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials()))
+	up, err := NewPool(context.Background(), l.Addr().String(), WithSize(2), creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer up.Close()
+	for _, c := range up.Conns() {
+		waitForState(t, c.ClientConn(), connectivity.Ready)
+	}
+	half, err := NewEndpointPool(context.Background(), []Endpoint{{Addr: l.Addr().String()}, {Addr: "localhost:1"}}, creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer half.Close()
+	waitForState(t, half.Conns()[0].ClientConn(), connectivity.Ready)
+	waitForState(t, half.Conns()[1].ClientConn(), connectivity.TransientFailure)
+	unreachable, err := NewPool(context.Background(), "localhost:1", creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unreachable.Close()
+	legacy, err := DialContext(context.Background(), "localhost:1", 1, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer legacy.Close()
+	waitForState(t, legacy.Conn(), connectivity.TransientFailure)
+
+	for name, tc := range map[string]struct {
+		h        http.Handler
+		want     int
+		wantBody string
+	}{
+		"healthy":       {ReadinessHandler(up, half), http.StatusOK, "ok"},
+		"legacy down":   {ReadinessHandler(up, legacy), http.StatusServiceUnavailable, "pool 1: 0 of 1 connections ready, want 1"},
+		"unreachable":   {ReadinessHandler(up, unreachable), http.StatusServiceUnavailable, "pool 1: 0 of 1 connections ready, want 1"},
+		"subset":        {ReadinessChecksHandler(ReadinessCheck{Name: "up", Pool: up}), http.StatusOK, "ok"},
+		"threshold met": {ReadinessChecksHandler(ReadinessCheck{Name: "half", Pool: half, MinHealthy: 0.5}), http.StatusOK, "ok"},
+		"threshold missed": {ReadinessChecksHandler(ReadinessCheck{Name: "half", Pool: half, MinHealthy: 0.75}),
+			http.StatusServiceUnavailable, "half: 1 of 2 connections ready, want 2"},
+	} {
+		rec := httptest.NewRecorder()
+		tc.h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if rec.Code != tc.want || strings.TrimSpace(rec.Body.String()) != tc.wantBody {
+			t.Errorf("%s: got %d %q; want %d %q", name, rec.Code, rec.Body.String(), tc.want, tc.wantBody)
+		}
+	}
+}
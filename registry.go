@@ -0,0 +1,203 @@
+package grpcpool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Registry holds named pools created on first use, so libraries deep in the call stack can share a pool by name
+// instead of each creating their own.
+type Registry struct {
+	idleTTL time.Duration
+	clock   Clock
+
+	mu       sync.Mutex
+	entries  map[string]*registryEntry
+	shutdown bool
+}
+
+// RegistryOption configures a Registry.
+type RegistryOption func(*Registry)
+
+// WithIdleTTL closes the pools of the registry that had no calls for ttl, so a registry holding a pool per dynamic
+// endpoint, e.g. per tenant of a gateway, doesn't grow its connections without bound. Evicted pools stay
+// registered and are created again by the next Get, so callers must Get the pool for every use instead of keeping
+// it.
+//
+// Idle pools are found on Get, which counts as a use of the pool it returns, and pools with calls in flight are
+// never evicted.
+func WithIdleTTL(ttl time.Duration) RegistryOption {
+	return func(r *Registry) {
+		r.idleTTL = ttl
+	}
+}
+
+// WithRegistryClock sets the Clock of the idle TTL. The default is SystemClock.
+func WithRegistryClock(c Clock) RegistryOption {
+	return func(r *Registry) {
+		r.clock = c
+	}
+}
+
+type registryEntry struct {
+	name string
+	cfg  Config
+	opts []Option
+
+	mu     sync.Mutex
+	dialed bool // pool and err are set
+	closed bool // the registry was shut down
+	pool   *Pool
+	err    error
+
+	// Guarded by Registry.mu, see WithIdleTTL.
+	lastUsed  time.Time
+	lastCalls int64 // calls of pool when lastUsed was updated
+}
+
+// DefaultRegistry is the process-wide Registry used by Register and Get.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry creates an empty Registry.
+func NewRegistry(opts ...RegistryOption) *Registry {
+	r := &Registry{clock: SystemClock(), entries: map[string]*registryEntry{}}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// Register registers the pool name, created from cfg and opts by the first Get, with the environment variables of
+// name applied as by NewNamedPool. It fails if name is already registered.
+func (r *Registry) Register(name string, cfg Config, opts ...Option) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.shutdown {
+		return errRegistryShutdown
+	}
+	if _, ok := r.entries[name]; ok {
+		return fmt.Errorf("grpcpool: pool %s already registered", name)
+	}
+	r.entries[name] = &registryEntry{name: name, cfg: cfg, opts: opts}
+	return nil
+}
+
+// Get returns the pool registered under name, creating it on the first call. Concurrent first calls wait for the
+// same pool, and an error creating it is returned by every later call too, as it comes from the configuration.
+func (r *Registry) Get(name string) (*Pool, error) {
+	r.mu.Lock()
+	e, ok := r.entries[name]
+	if r.idleTTL > 0 {
+		now := r.clock.Now()
+		if ok {
+			e.lastUsed = now
+		}
+		r.evictLocked(now)
+	}
+	r.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("grpcpool: pool %s not registered", name)
+	}
+	return e.get()
+}
+
+// evictLocked closes the pools idle for the idle TTL.
+func (r *Registry) evictLocked(now time.Time) {
+	for _, e := range r.entries {
+		if !e.mu.TryLock() {
+			continue // being created or shut down
+		}
+		if e.pool != nil {
+			calls, inflight := e.pool.activity()
+			if calls != e.lastCalls || inflight > 0 {
+				e.lastUsed, e.lastCalls = now, calls
+			} else if now.Sub(e.lastUsed) >= r.idleTTL {
+				e.pool.Close()
+				e.pool, e.dialed, e.lastCalls = nil, false, 0
+			}
+		}
+		e.mu.Unlock()
+	}
+}
+
+// activity returns the number of finished and in-flight calls and streams of p.
+func (p *Pool) activity() (calls, inflight int64) {
+	for _, c := range p.set.Load().conns {
+		calls += c.calls.Load()
+		inflight += c.inflight.Load()
+	}
+	return calls, inflight
+}
+
+// Shutdown shuts down every pool created by Get, as by Pool.Shutdown, and fails later calls to Register and Get.
+// It is meant for process shutdown and returns the first error.
+func (r *Registry) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	r.shutdown = true
+	entries := r.entries
+	r.mu.Unlock()
+
+	var (
+		wg    sync.WaitGroup
+		errMu sync.Mutex
+		err   error
+	)
+	for _, e := range entries {
+		e.mu.Lock()
+		e.closed = true
+		p := e.pool
+		e.mu.Unlock()
+		if p == nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if serr := p.Shutdown(ctx); serr != nil {
+				errMu.Lock()
+				if err == nil {
+					err = serr
+				}
+				errMu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return err
+}
+
+var errRegistryShutdown = status.Error(codes.Unavailable, "grpcpool: registry is shut down")
+
+// get returns the pool of e, creating it on the first call.
+func (e *registryEntry) get() (*Pool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return nil, errRegistryShutdown
+	}
+	if !e.dialed {
+		e.pool, e.err = NewNamedPool(context.Background(), e.name, e.cfg, e.opts...)
+		e.dialed = true
+	}
+	return e.pool, e.err
+}
+
+// Register registers the pool name with DefaultRegistry, see Registry.Register.
+func Register(name string, cfg Config, opts ...Option) error {
+	return DefaultRegistry.Register(name, cfg, opts...)
+}
+
+// Get returns the pool name of DefaultRegistry, see Registry.Get.
+func Get(name string) (*Pool, error) {
+	return DefaultRegistry.Get(name)
+}
+
+// ShutdownRegistered shuts down the pools of DefaultRegistry at process shutdown, see Registry.Shutdown.
+func ShutdownRegistered(ctx context.Context) error {
+	return DefaultRegistry.Shutdown(ctx)
+}
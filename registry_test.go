@@ -0,0 +1,117 @@
+package grpcpool
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+)
+
+func TestRegistry(t *testing.T) {
+	_, l := mockServer(t)
+	r := NewRegistry()
+	if err := r.Register("billing", Config{Target: l.Addr().String(), Size: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register("billing", Config{Target: l.Addr().String()}); err == nil {
+		t.Error("Register of a registered name succeeded")
+	}
+	if err := r.Register("broken", Config{Target: l.Addr().String(), Picker: "nope"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Get("unknown"); err == nil {
+		t.Error("Get of an unregistered name succeeded")
+	}
+
+	pools := make([]*Pool, 8)
+	var wg sync.WaitGroup
+	for i := range pools {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p, err := r.Get("billing")
+			if err != nil {
+				t.Error(err)
+			}
+			pools[i] = p
+		}(i)
+	}
+	wg.Wait()
+	for _, p := range pools {
+		if p == nil || p != pools[0] {
+			t.Fatalf("Get returned different pools: %v", pools)
+		}
+	}
+	if n := pools[0].Num(); n != 2 {
+		t.Errorf("pool size got %d; want 2", n)
+	}
+	if _, err := r.Get("broken"); err == nil {
+		t.Error("Get of a misconfigured pool succeeded")
+	}
+
+	if err := r.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := pools[0].Invoke(context.Background(), "/test.Test/Echo", nil, nil); status.Code(err) != codes.Unavailable {
+		t.Errorf("Invoke after Shutdown got %v; want Unavailable", err)
+	}
+	if _, err := r.Get("billing"); status.Code(err) != codes.Unavailable {
+		t.Errorf("Get after Shutdown got %v; want Unavailable", err)
+	}
+	if err := r.Register("other", Config{Target: l.Addr().String()}); err == nil {
+		t.Error("Register after Shutdown succeeded")
+	}
+}
+
+func TestRegistryIdleTTL(t *testing.T) {
+	_, l := mockServer(t)
+	clock := &manualClock{now: time.Unix(0, 0)}
+	r := NewRegistry(WithIdleTTL(time.Minute), WithRegistryClock(clock))
+	defer r.Shutdown(context.Background())
+	for _, name := range []string{"a", "b", "c"} {
+		if err := r.Register(name, Config{Target: l.Addr().String()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	get := func(name string) *Pool {
+		t.Helper()
+		p, err := r.Get(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	closed := func(p *Pool) bool {
+		return p.Conns()[0].State() == connectivity.Shutdown
+	}
+
+	a, b := get("a"), get("b")
+	clock.advance(59 * time.Second)
+	b.Invoke(context.Background(), "/test.Test/Call", nil, nil)
+	clock.advance(time.Second)
+	get("c")
+	if !closed(a) {
+		t.Error("pool a not closed after the idle TTL")
+	}
+	if closed(b) {
+		t.Error("pool b closed though it had calls")
+	}
+
+	clock.advance(59 * time.Second)
+	get("c")
+	if closed(b) {
+		t.Error("pool b closed before the idle TTL")
+	}
+	clock.advance(time.Minute)
+	get("c")
+	if !closed(b) {
+		t.Error("pool b not closed after the idle TTL")
+	}
+	if p := get("a"); p == a || closed(p) {
+		t.Error("Get of an evicted pool didn't create it again")
+	}
+}
@@ -0,0 +1,208 @@
+package grpcpool
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// reloadDrainTimeout is how long the calls of a pool replaced on SIGHUP may take to finish before it is closed.
+const reloadDrainTimeout = time.Minute
+
+// ReloadablePool is a ConnPool built from a Config, rebuilt when the Config changes, e.g. on SIGHUP, so operators
+// can change pool settings without a restart. See NewReloadablePool.
+//
+// Unlike other ConnPools, Num changes when a reload changes the size.
+type ReloadablePool struct {
+	opts []Option
+
+	mu   sync.Mutex // serializes reloads until the pool is swapped
+	cfg  Config
+	ca   [sha256.Size]byte // of cfg.TLSCAFile, see caSum
+	pool atomic.Pointer[reloadedPool]
+}
+
+// reloadedPool is a pool of a ReloadablePool with the calls that loaded it but may not have reached it yet.
+type reloadedPool struct {
+	*Pool
+	active atomic.Int64 // calls between loading the pool and being counted by it
+}
+
+// acquire returns the current pool with its active count incremented, as Pool.acquire does for connSets.
+func (r *ReloadablePool) acquire() *reloadedPool {
+	for {
+		p := r.pool.Load()
+		p.active.Add(1)
+		if r.pool.Load() == p {
+			return p
+		}
+		p.active.Add(-1)
+	}
+}
+
+// drain blocks until the calls that loaded p were counted by it and finished, or ctx is done.
+func (p *reloadedPool) drain(ctx context.Context) error {
+	ticker := p.opts.clock.NewTicker(drainInterval)
+	defer ticker.Stop()
+	for p.active.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+		}
+	}
+	return p.set.Load().drain(ctx, p.opts.clock)
+}
+
+var _ ConnPool = &ReloadablePool{}
+
+// NewReloadablePool creates a ReloadablePool from cfg, see NewFromConfig. opts are applied to every pool built,
+// after the Options derived from the Config.
+func NewReloadablePool(ctx context.Context, cfg Config, opts ...Option) (*ReloadablePool, error) {
+	p, err := NewFromConfig(ctx, cfg, opts...)
+	if err != nil {
+		return nil, err
+	}
+	r := &ReloadablePool{opts: opts, cfg: cfg, ca: caSum(cfg)}
+	r.pool.Store(&reloadedPool{Pool: p})
+	return r, nil
+}
+
+// caSum returns the hash of the TLS CA file of cfg, so reloads pick up roots rotated in place. It is zero without a
+// CA file, or if it can't be read, which NewFromConfig reports.
+func caSum(cfg Config) [sha256.Size]byte {
+	if !cfg.TLS || cfg.TLSCAFile == "" {
+		return [sha256.Size]byte{}
+	}
+	pem, err := os.ReadFile(cfg.TLSCAFile)
+	if err != nil {
+		return [sha256.Size]byte{}
+	}
+	return sha256.Sum256(pem)
+}
+
+// Pool returns the current pool. It is closed once a reload replaced it and its calls finished.
+func (r *ReloadablePool) Pool() *Pool {
+	return r.pool.Load().Pool
+}
+
+// Config returns the Config of the current pool.
+func (r *ReloadablePool) Config() Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cfg
+}
+
+// Reload rebuilds the pool from cfg if it differs from the Config of the current pool, or its TLS CA file changed
+// content, and reports whether it did.
+//
+// The new pool is created, and made ready if cfg.DialTimeout is set, before new calls are sent to it; if that
+// fails, the current pool is kept and the error returned. The old pool is closed after its in-flight unary calls
+// and open streams finish, including the calls that loaded it just before the reload, or once ctx is done, in
+// which case ctx.Err() is returned; the reload itself has already taken effect then. Other reloads, Config and
+// Close don't wait for the old pool to drain.
+func (r *ReloadablePool) Reload(ctx context.Context, cfg Config) (bool, error) {
+	r.mu.Lock()
+	ca := caSum(cfg)
+	if reflect.DeepEqual(cfg, r.cfg) && ca == r.ca {
+		r.mu.Unlock()
+		return false, nil
+	}
+	p, err := NewFromConfig(ctx, cfg, r.opts...)
+	if err != nil {
+		r.mu.Unlock()
+		return false, err
+	}
+	old := r.pool.Swap(&reloadedPool{Pool: p})
+	r.cfg, r.ca = cfg, ca
+	r.mu.Unlock()
+
+	err = old.drain(ctx)
+	if cerr := old.Close(); err == nil {
+		err = cerr
+	}
+	return true, err
+}
+
+// ReloadOnSIGHUP reloads the pool with the Config returned by load, e.g. LoadConfig of its config file, whenever
+// the process receives SIGHUP, until stop is called. Errors of load and Reload are passed to onError, which may be
+// nil. Replaced pools get a minute to drain.
+func (r *ReloadablePool) ReloadOnSIGHUP(load func() (Config, error), onError func(error)) (stop func()) {
+	return r.reloadOn(syscall.SIGHUP, load, onError)
+}
+
+func (r *ReloadablePool) reloadOn(sig os.Signal, load func() (Config, error), onError func(error)) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, sig)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			case <-signals:
+			}
+			err := r.reloadFrom(load)
+			if err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+		wg.Wait()
+	}
+}
+
+func (r *ReloadablePool) reloadFrom(load func() (Config, error)) error {
+	cfg, err := load()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), reloadDrainTimeout)
+	defer cancel()
+	_, err = r.Reload(ctx, cfg)
+	return err
+}
+
+// Conn returns a ClientConn of the current pool.
+func (r *ReloadablePool) Conn() *grpc.ClientConn {
+	return r.pool.Load().Conn()
+}
+
+// Num returns the number of connections of the current pool.
+func (r *ReloadablePool) Num() int {
+	return r.pool.Load().Num()
+}
+
+// Close closes the current pool, after waiting for a reload in progress to replace it. Pools replaced before are
+// closed by their Reload once drained.
+func (r *ReloadablePool) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pool.Load().Close()
+}
+
+func (r *ReloadablePool) Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error {
+	p := r.acquire()
+	defer p.active.Add(-1)
+	return p.Invoke(ctx, method, args, reply, opts...)
+}
+
+func (r *ReloadablePool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	p := r.acquire()
+	defer p.active.Add(-1) // an open stream is counted by p from here on
+	return p.NewStream(ctx, desc, method, opts...)
+}
@@ -0,0 +1,156 @@
+package grpcpool
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestReload(t *testing.T) {
+	l1, l2 := echoStreamServer(t), echoStreamServer(t)
+	cfg := Config{Target: l1.Addr().String(), Size: 1}
+	r, err := NewReloadablePool(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if changed, err := r.Reload(context.Background(), cfg); changed || err != nil {
+		t.Errorf("Reload() of the same config got %v, %v; want no change", changed, err)
+	}
+
+	old := r.Pool()
+	cs, err := r.NewStream(context.Background(), &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}, "/test.Echo/Echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded := make(chan error, 1)
+	go func() {
+		_, err := r.Reload(context.Background(), Config{Target: l2.Addr().String(), Size: 2, DialTimeout: 5 * time.Second})
+		reloaded <- err
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for r.Pool() == old {
+		if time.Now().After(deadline) {
+			t.Fatal("pool not replaced")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if r.Num() != 2 || r.Pool().Conns()[0].Endpoint().Addr != l2.Addr().String() {
+		t.Errorf("reloaded pool has %d conns to %s; want 2 to %s", r.Num(), r.Pool().Conns()[0].Endpoint().Addr, l2.Addr())
+	}
+	select {
+	case err := <-reloaded:
+		t.Fatalf("Reload() returned %v before the stream on the old pool ended", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	configured := make(chan Config, 1)
+	go func() { configured <- r.Config() }()
+	select {
+	case got := <-configured:
+		if got.Target != l2.Addr().String() {
+			t.Errorf("Config().Target while draining got %s; want %s", got.Target, l2.Addr())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Config() waited for the old pool to drain")
+	}
+
+	// The stream on the old pool still works, and ends the drain.
+	if err := cs.SendMsg(&emptypb.Empty{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.RecvMsg(&emptypb.Empty{}); err != nil {
+		t.Fatal(err)
+	}
+	cs.CloseSend()
+	if err := cs.RecvMsg(&emptypb.Empty{}); err != io.EOF {
+		t.Fatalf("RecvMsg() got %v; want io.EOF", err)
+	}
+	if err := <-reloaded; err != nil {
+		t.Errorf("Reload() got %v", err)
+	}
+	waitForState(t, old.Conns()[0].ClientConn(), connectivity.Shutdown)
+}
+
+func TestReloadWaitsForLoadedCalls(t *testing.T) {
+	_, l := mockServer(t)
+	r, err := NewReloadablePool(context.Background(), Config{Target: l.Addr().String(), Size: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	// A call that loaded the pool but isn't counted by it yet.
+	loaded := r.acquire()
+	reloaded := make(chan error, 1)
+	go func() {
+		_, err := r.Reload(context.Background(), Config{Target: l.Addr().String(), Size: 2})
+		reloaded <- err
+	}()
+	select {
+	case err := <-reloaded:
+		t.Fatalf("Reload() returned %v before the loaded call finished", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if state := loaded.Conns()[0].State(); state == connectivity.Shutdown {
+		t.Fatal("old pool closed before the loaded call finished")
+	}
+	loaded.active.Add(-1)
+	if err := <-reloaded; err != nil {
+		t.Errorf("Reload() got %v", err)
+	}
+	waitForState(t, loaded.Conns()[0].ClientConn(), connectivity.Shutdown)
+}
+
+func TestReloadError(t *testing.T) {
+	_, l := mockServer(t)
+	cfg := Config{Target: l.Addr().String(), Size: 1}
+	r, err := NewReloadablePool(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	old := r.Pool()
+	if changed, err := r.Reload(context.Background(), Config{Target: l.Addr().String(), Picker: "nope"}); changed || err == nil {
+		t.Errorf("Reload() with a bad config got %v, %v; want an error", changed, err)
+	}
+	if r.Pool() != old || r.Config().Picker != "" {
+		t.Error("failed Reload() replaced the pool")
+	}
+}
+
+func TestReloadRotatedCA(t *testing.T) {
+	_, l := mockServer(t)
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writeCA := func() {
+		t.Helper()
+		ca := newTestCA(t)
+		if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeCA()
+	cfg := Config{Target: l.Addr().String(), Size: 1, TLS: true, TLSCAFile: caFile}
+	r, err := NewReloadablePool(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if changed, err := r.Reload(context.Background(), cfg); changed || err != nil {
+		t.Errorf("Reload() with the same CA got %v, %v; want no change", changed, err)
+	}
+	writeCA()
+	if changed, err := r.Reload(context.Background(), cfg); !changed || err != nil {
+		t.Errorf("Reload() with a CA rotated in place got %v, %v; want a reload", changed, err)
+	}
+}
@@ -0,0 +1,34 @@
+//go:build unix
+
+package grpcpool
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReloadOnSIGHUP(t *testing.T) {
+	_, l := mockServer(t)
+	r, err := NewReloadablePool(context.Background(), Config{Target: l.Addr().String(), Size: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	stop := r.ReloadOnSIGHUP(func() (Config, error) {
+		return Config{Target: l.Addr().String(), Size: 3}, nil
+	}, func(err error) { t.Error(err) })
+	defer stop()
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for r.Num() != 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("pool has %d conns after SIGHUP; want 3", r.Num())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,53 @@
+package grpcpool
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// RequestKeyFunc extracts a routing key from the request message of a unary call to method.
+type RequestKeyFunc func(method string, req interface{}) (key string, ok bool)
+
+// WithRequestHash routes unary calls by hashing their method together with the key f extracts from the request,
+// e.g. an order ID, so calls for the same method and key land on the same connection. This gives deterministic
+// routing for cache locality without changing service APIs.
+//
+// Calls for which f returns false, streams and calls with an affinity key use the pool's other routing.
+func WithRequestHash(f RequestKeyFunc) Option {
+	return func(o *options) {
+		o.requestKeyFunc = f
+	}
+}
+
+// ProtoField returns a RequestKeyFunc extracting the top-level field name, e.g. "order_id", from proto requests.
+//
+// Requests that aren't proto messages, don't have the field or have it unset don't get a key.
+func ProtoField(name string) RequestKeyFunc {
+	fieldName := protoreflect.Name(name)
+	return func(_ string, req interface{}) (string, bool) {
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return "", false
+		}
+		m := msg.ProtoReflect()
+		fd := m.Descriptor().Fields().ByName(fieldName)
+		if fd == nil || !m.Has(fd) {
+			return "", false
+		}
+		return fmt.Sprint(m.Get(fd).Interface()), true
+	}
+}
+
+// requestKey returns the routing key of a unary call, if any.
+func (o *options) requestKey(method string, req interface{}) (string, bool) {
+	if o.requestKeyFunc == nil || req == nil {
+		return "", false
+	}
+	key, ok := o.requestKeyFunc(method, req)
+	if !ok {
+		return "", false
+	}
+	return method + "\x00" + key, true
+}
@@ -0,0 +1,54 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestProtoField(t *testing.T) {
+	f := ProtoField("service")
+	if key, ok := f("/m", &healthpb.HealthCheckRequest{Service: "billing"}); !ok || key != "billing" {
+		t.Errorf("ProtoField(service) got %q, %v; want billing, true", key, ok)
+	}
+	if _, ok := f("/m", &healthpb.HealthCheckRequest{}); ok {
+		t.Error("ProtoField(service) got a key for an unset field")
+	}
+	if _, ok := ProtoField("missing")("/m", &healthpb.HealthCheckRequest{Service: "billing"}); ok {
+		t.Error("ProtoField(missing) got a key")
+	}
+	if _, ok := f("/m", "not a proto"); ok {
+		t.Error("ProtoField(service) got a key for a non-proto request")
+	}
+}
+
+func TestRequestHash(t *testing.T) {
+	l := healthTestServer(t, "", healthpb.HealthCheckResponse_SERVING)
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(4),
+		WithRequestHash(ProtoField("service")),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	client := healthpb.NewHealthClient(pool)
+	for i := 0; i < 10; i++ {
+		// The health server returns NOT_FOUND for unknown services, which is fine for routing.
+		client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "order-42"})
+	}
+	used := 0
+	for _, n := range callsPerConn(pool) {
+		if n > 0 {
+			used++
+		}
+	}
+	if used != 1 {
+		t.Errorf("calls with one request key used %d conns; want 1", used)
+	}
+}
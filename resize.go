@@ -0,0 +1,105 @@
+package grpcpool
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultMaxResize is the largest size Resize accepts unless WithMaxResize is given, or the pool is configured
+// with more connections.
+const DefaultMaxResize = 64
+
+// WithMaxResize sets the largest size Resize accepts, so a single call, e.g. through an admin API, can't dial an
+// unbounded number of connections. The default is DefaultMaxResize, or the size or MaxConns of the pool if larger.
+func WithMaxResize(n int) Option {
+	return func(o *options) {
+		o.maxResize = n
+	}
+}
+
+// resizeLimit returns the largest size Resize accepts.
+func (o *options) resizeLimit() int {
+	if o.maxResize > 0 {
+		return o.maxResize
+	}
+	n := DefaultMaxResize
+	if o.size > n {
+		n = o.size
+	}
+	if m := o.maxConns(); m > n {
+		n = m
+	}
+	return n
+}
+
+// Resize changes the number of connections of the pool to n, e.g. to adjust a pool live during an incident. n must
+// not exceed the maximum set by WithMaxResize; Resize fails with InvalidArgument otherwise.
+//
+// Connections are added as by WithStreamGrowth, next to the ungrouped connections, to the endpoint with the fewest
+// of them, dialed with ctx. Only ungrouped connections are removed, the last ones first, and at least one is kept,
+// so connection groups such as CanaryGroup keep their connections; grouped connections placed after a removed one
+// are re-dialed at their new index, and swapped in once ready. Removed connections stop taking new calls right
+// away, and are closed once their in-flight calls and open streams finish, or once ctx is done, in which case
+// ctx.Err() is returned; the resize itself has already taken effect then.
+func (p *Pool) Resize(ctx context.Context, n int) error {
+	if n < 1 {
+		return status.Error(codes.InvalidArgument, "grpcpool: pool size must be at least 1")
+	}
+	if max := p.opts.resizeLimit(); n > max {
+		return status.Errorf(codes.InvalidArgument, "grpcpool: pool size %d exceeds the maximum of %d", n, max)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	defer p.saveSize()
+	for len(p.set.Load().conns) < n {
+		if err := p.addConn(ctx, p.set.Load()); err != nil {
+			return err
+		}
+	}
+
+	old := p.set.Load()
+	if len(old.conns) == n {
+		return nil
+	}
+	return p.shrink(ctx, old, len(old.conns)-n)
+}
+
+// shrink removes the last k ungrouped connections of old, the current set of p. p.mu must be held.
+func (p *Pool) shrink(ctx context.Context, old *connSet, k int) error {
+	ungrouped := old.groups[""]
+	if ungrouped == nil || len(ungrouped.conns) <= k {
+		n := 0
+		if ungrouped != nil {
+			n = len(ungrouped.conns)
+		}
+		return status.Errorf(codes.InvalidArgument, "grpcpool: can't remove %d of %d ungrouped connections and keep one", k, n)
+	}
+	removed := ungrouped.conns[len(ungrouped.conns)-k:]
+	conns := make([]*PoolConn, 0, len(old.conns)-k)
+	var moved []int
+	for _, c := range old.conns {
+		if containsConn(removed, c) {
+			continue
+		}
+		if c.index != len(conns) {
+			moved = append(moved, len(conns))
+		}
+		conns = append(conns, c)
+	}
+	return p.redialAt(ctx, old, conns, moved, true, removed)
+}
+
+// ReplaceConn replaces the connection at index with a new one to the same endpoint, e.g. a connection stuck on a
+// bad backend. The new connection is swapped in once ready, and the old one closed after its in-flight calls and
+// open streams finish, as by Recycle.
+func (p *Pool) ReplaceConn(ctx context.Context, index int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if n := len(p.set.Load().conns); index < 0 || index >= n {
+		return fmt.Errorf("grpcpool: no connection %d in a pool of %d", index, n)
+	}
+	return p.redial(ctx, []int{index}, true)
+}
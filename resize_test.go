@@ -0,0 +1,125 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestResize(t *testing.T) {
+	_, l := mockServer(t)
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(2),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pool.Resize(ctx, 4); err != nil {
+		t.Fatal(err)
+	}
+	if pool.Num() != 4 {
+		t.Fatalf("pool.Num() after growing got %d; want 4", pool.Num())
+	}
+	for i, c := range pool.Conns() {
+		if c.Index() != i {
+			t.Errorf("conn %d has index %d", i, c.Index())
+		}
+	}
+
+	removed := pool.Conns()[1:]
+	if err := pool.Resize(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if pool.Num() != 1 {
+		t.Fatalf("pool.Num() after shrinking got %d; want 1", pool.Num())
+	}
+	for _, c := range removed {
+		if state := c.State(); state != connectivity.Shutdown {
+			t.Errorf("removed conn %d is %v; want SHUTDOWN", c.Index(), state)
+		}
+	}
+	if err := pool.Resize(ctx, 0); err == nil {
+		t.Error("Resize(0) succeeded")
+	}
+}
+
+func TestResizeKeepsGroups(t *testing.T) {
+	_, l := mockServer(t)
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(4),
+		WithStreamConns(1),
+		WithMaxResize(6),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pool.Resize(ctx, 7); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Resize() above the maximum got %v; want InvalidArgument", err)
+	}
+	canceled, cancelNow := context.WithCancel(ctx)
+	cancelNow()
+	if err := pool.Resize(canceled, 6); err == nil || pool.Num() != 4 {
+		t.Errorf("Resize() with a canceled context got %v and %d conns; want an error and 4 conns", err, pool.Num())
+	}
+
+	if err := pool.Resize(ctx, 1); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Resize() removing every ungrouped conn got %v; want InvalidArgument", err)
+	}
+	if err := pool.Resize(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	conns := pool.Conns()
+	if len(conns) != 2 || conns[0].Group() != "" || conns[1].Group() != StreamGroup {
+		t.Fatalf("conns after shrinking got %d with groups %q, %q; want an ungrouped and a stream conn", len(conns), conns[0].Group(), conns[1].Group())
+	}
+	for i, c := range conns {
+		if c.Index() != i {
+			t.Errorf("conn %d has index %d", i, c.Index())
+		}
+	}
+}
+
+func TestReplaceConn(t *testing.T) {
+	_, l := mockServer(t)
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(2),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	old := pool.Conns()
+	if err := pool.ReplaceConn(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	conns := pool.Conns()
+	if conns[0] != old[0] || conns[1] == old[1] || conns[1].Index() != 1 {
+		t.Error("ReplaceConn(1) didn't replace exactly conn 1")
+	}
+	if state := old[1].State(); state != connectivity.Shutdown {
+		t.Errorf("replaced conn is %v; want SHUTDOWN", state)
+	}
+	if err := pool.ReplaceConn(ctx, 2); err == nil {
+		t.Error("ReplaceConn of a missing conn succeeded")
+	}
+}
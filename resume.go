@@ -0,0 +1,142 @@
+package grpcpool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultResumeAttempts is how many times in a row a ResumableStream tries to resume before giving up.
+	DefaultResumeAttempts = 5
+
+	// resumeBackoff is the delay before the second attempt to resume a stream, doubled on every further attempt.
+	resumeBackoff = 50 * time.Millisecond
+)
+
+// ResumeFunc brings a new stream to the state of the one it replaces, e.g. by sending the subscription request
+// again with the last cursor seen. It is also called on the first stream, so it usually sends the initial request.
+type ResumeFunc func(ctx context.Context, cs grpc.ClientStream) error
+
+// ResumableStream is a grpc.ClientStream that transparently re-establishes itself on another connection of the
+// pool when its connection fails, see Pool.NewResumableStream.
+//
+// SendMsg and RecvMsg may be called concurrently as on any stream. Header and Trailer are those of the current
+// stream.
+type ResumableStream struct {
+	pool   *Pool
+	ctx    context.Context
+	desc   *grpc.StreamDesc
+	method string
+	opts   []grpc.CallOption
+	resume ResumeFunc
+
+	mu      sync.Mutex // serializes sends with resuming
+	cs      atomic.Pointer[grpc.ClientStream]
+	resumes atomic.Int64
+}
+
+// NewResumableStream opens a stream that resumes when its connection dies, for watch-style consumers.
+//
+// When RecvMsg fails with Unavailable, which is how a broken connection or a GOAWAY surface, the stream is opened
+// again on the pool, possibly on another connection, and resume is called on it before receiving again. Up to
+// DefaultResumeAttempts attempts are made with exponential backoff, failing on Unavailable from NewStream or the
+// first RecvMsg of the new stream. Other errors, including io.EOF at the end of the stream and those of resume, are
+// returned as they are.
+func (p *Pool) NewResumableStream(ctx context.Context, desc *grpc.StreamDesc, method string, resume ResumeFunc, opts ...grpc.CallOption) (*ResumableStream, error) {
+	rs := &ResumableStream{pool: p, ctx: ctx, desc: desc, method: method, opts: opts, resume: resume}
+	cs, err := rs.open()
+	if err != nil {
+		return nil, err
+	}
+	rs.cs.Store(&cs)
+	return rs, nil
+}
+
+// open opens a stream and calls the resume function on it.
+func (rs *ResumableStream) open() (grpc.ClientStream, error) {
+	cs, err := rs.pool.NewStream(rs.ctx, rs.desc, rs.method, rs.opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := rs.resume(rs.ctx, cs); err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+// Resumes returns how many times the stream was resumed.
+func (rs *ResumableStream) Resumes() int {
+	return int(rs.resumes.Load())
+}
+
+func (rs *ResumableStream) stream() grpc.ClientStream {
+	return *rs.cs.Load()
+}
+
+func (rs *ResumableStream) Header() (metadata.MD, error) {
+	return rs.stream().Header()
+}
+
+func (rs *ResumableStream) Trailer() metadata.MD {
+	return rs.stream().Trailer()
+}
+
+func (rs *ResumableStream) CloseSend() error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return rs.stream().CloseSend()
+}
+
+func (rs *ResumableStream) Context() context.Context {
+	return rs.stream().Context()
+}
+
+func (rs *ResumableStream) SendMsg(m interface{}) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return rs.stream().SendMsg(m)
+}
+
+// RecvMsg receives a message, resuming the stream if its connection failed.
+func (rs *ResumableStream) RecvMsg(m interface{}) error {
+	err := rs.stream().RecvMsg(m)
+	if status.Code(err) != codes.Unavailable {
+		return err
+	}
+
+	backoff := resumeBackoff
+	for attempt := 1; attempt <= DefaultResumeAttempts; attempt++ {
+		if attempt > 1 {
+			t := rs.pool.opts.clock.NewTimer(backoff)
+			select {
+			case <-rs.ctx.Done():
+				t.Stop()
+				return status.FromContextError(rs.ctx.Err()).Err()
+			case <-t.C():
+			}
+			backoff *= 2
+		}
+		var next grpc.ClientStream
+		rs.mu.Lock()
+		next, err = rs.open()
+		if err == nil {
+			rs.cs.Store(&next)
+		}
+		rs.mu.Unlock()
+		if err == nil {
+			rs.resumes.Add(1)
+			err = next.RecvMsg(m)
+		}
+		if status.Code(err) != codes.Unavailable {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,131 @@
+package grpcpool
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// cursorServer starts a server that streams two values from the cursor it receives, then blocks.
+func cursorServer(t *testing.T) (*grpc.Server, net.Listener, *atomic.Int64) {
+	t.Helper()
+	var served atomic.Int64
+	s := grpc.NewServer(grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+		served.Add(1)
+		cursor := &wrapperspb.Int64Value{}
+		if err := stream.RecvMsg(cursor); err != nil {
+			return err
+		}
+		for i := int64(0); i < 2; i++ {
+			if err := stream.SendMsg(wrapperspb.Int64(cursor.Value + i)); err != nil {
+				return err
+			}
+		}
+		<-stream.Context().Done()
+		return nil
+	}))
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+	return s, l, &served
+}
+
+func TestResumableStream(t *testing.T) {
+	s1, l1, served1 := cursorServer(t)
+	s2, l2, _ := cursorServer(t)
+
+	pool, err := NewEndpointPool(context.Background(), []Endpoint{{Addr: l1.Addr().String()}, {Addr: l2.Addr().String()}},
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pool.WaitForReady(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var last atomic.Int64
+	rs, err := pool.NewResumableStream(ctx, &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}, "/test.Watch/Watch",
+		func(_ context.Context, cs grpc.ClientStream) error {
+			return cs.SendMsg(wrapperspb.Int64(last.Load() + 1))
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recv := func() int64 {
+		t.Helper()
+		v := &wrapperspb.Int64Value{}
+		if err := rs.RecvMsg(v); err != nil {
+			t.Fatal(err)
+		}
+		last.Store(v.Value)
+		return v.Value
+	}
+	for want := int64(1); want <= 2; want++ {
+		if got := recv(); got != want {
+			t.Fatalf("RecvMsg() got %d; want %d", got, want)
+		}
+	}
+
+	// Kill the server with the stream; the stream resumes on the other one from the last cursor.
+	if served1.Load() == 1 {
+		s1.Stop()
+	} else {
+		s2.Stop()
+	}
+	if got := recv(); got != 3 {
+		t.Fatalf("RecvMsg() after resuming got %d; want 3", got)
+	}
+	if got := rs.Resumes(); got != 1 {
+		t.Errorf("Resumes() got %d; want 1", got)
+	}
+}
+
+func TestResumableStreamGivesUp(t *testing.T) {
+	s, l, _ := cursorServer(t)
+
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	rs, err := pool.NewResumableStream(ctx, &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}, "/test.Watch/Watch",
+		func(_ context.Context, cs grpc.ClientStream) error {
+			return cs.SendMsg(wrapperspb.Int64(0))
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := rs.RecvMsg(&wrapperspb.Int64Value{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.Stop()
+	if err := rs.RecvMsg(&wrapperspb.Int64Value{}); status.Code(err) != codes.Unavailable {
+		t.Errorf("RecvMsg() without servers got %v; want Unavailable", err)
+	}
+	if got := rs.Resumes(); got != 0 {
+		t.Errorf("Resumes() got %d; want 0", got)
+	}
+}
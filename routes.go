@@ -0,0 +1,97 @@
+package grpcpool
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+// WithConnGroup reserves n connections of the pool for the group name, optionally dialed with additional options.
+//
+// Calls are routed to the group with WithMethodRoutes.
+func WithConnGroup(name string, n int, dialOpts ...grpc.DialOption) Option {
+	return func(o *options) {
+		o.addGroup(groupSpec{name: name, conns: n, dialOpts: dialOpts})
+	}
+}
+
+// WithMethodRoutes routes calls by method to the connection groups added with WithConnGroup, so operationally
+// distinct classes of RPCs are physically separated while the application still uses a single stub.
+//
+// routes maps method patterns to group names. A pattern is either a full method name, e.g. "/pkg.Service/Export",
+// or a prefix followed by "*", e.g. "/pkg.Service/Export*". Full method names take precedence over prefixes and
+// longer prefixes over shorter ones. Calls to methods without a route use the connections not in any group.
+func WithMethodRoutes(routes map[string]string) Option {
+	return func(o *options) {
+		o.routes = newRouteTable(routes)
+	}
+}
+
+type route struct {
+	prefix string
+	group  string
+}
+
+// routeTable maps methods to group names.
+type routeTable struct {
+	exact    map[string]string
+	prefixes []route // longest first
+}
+
+func newRouteTable(routes map[string]string) *routeTable {
+	t := &routeTable{exact: map[string]string{}}
+	for pattern, group := range routes {
+		if strings.HasSuffix(pattern, "*") {
+			t.prefixes = append(t.prefixes, route{prefix: strings.TrimSuffix(pattern, "*"), group: group})
+		} else {
+			t.exact[pattern] = group
+		}
+	}
+	sort.Slice(t.prefixes, func(i, j int) bool {
+		if len(t.prefixes[i].prefix) != len(t.prefixes[j].prefix) {
+			return len(t.prefixes[i].prefix) > len(t.prefixes[j].prefix)
+		}
+		return t.prefixes[i].prefix < t.prefixes[j].prefix
+	})
+	return t
+}
+
+// lookup returns the group of method, if it has a route.
+func (t *routeTable) lookup(method string) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+	if group, ok := t.exact[method]; ok {
+		return group, true
+	}
+	for _, r := range t.prefixes {
+		if strings.HasPrefix(method, r.prefix) {
+			return r.group, true
+		}
+	}
+	return "", false
+}
+
+// validate checks that every route refers to a group of the pool.
+func (t *routeTable) validate(groups []groupSpec) error {
+	if t == nil {
+		return nil
+	}
+	known := map[string]bool{"": true}
+	for _, g := range groups {
+		known[g.name] = true
+	}
+	for pattern, group := range t.exact {
+		if !known[group] {
+			return fmt.Errorf("grpcpool: route %q refers to unknown group %q", pattern, group)
+		}
+	}
+	for _, r := range t.prefixes {
+		if !known[r.group] {
+			return fmt.Errorf("grpcpool: route %q refers to unknown group %q", r.prefix+"*", r.group)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestRouteTable(t *testing.T) {
+	table := newRouteTable(map[string]string{
+		"/pkg.Service/*":         "default",
+		"/pkg.Service/Export*":   "export",
+		"/pkg.Service/ExportLog": "logs",
+	})
+	for method, want := range map[string]string{
+		"/pkg.Service/Get":       "default",
+		"/pkg.Service/ExportAll": "export",
+		"/pkg.Service/ExportLog": "logs",
+		"/other.Service/Get":     "",
+	} {
+		if got, _ := table.lookup(method); got != want {
+			t.Errorf("lookup(%q) got %q; want %q", method, got, want)
+		}
+	}
+}
+
+func TestMethodRoutes(t *testing.T) {
+	l := healthTestServer(t, "", healthpb.HealthCheckResponse_SERVING)
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(4),
+		WithConnGroup("health", 2),
+		WithMethodRoutes(map[string]string{"/grpc.health.v1.Health/Check": "health"}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	client := healthpb.NewHealthClient(pool)
+	for i := 0; i < 6; i++ {
+		if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := pool.GroupStats("health"); got.Conns != 2 || got.Calls != 6 {
+		t.Errorf(`pool.GroupStats("health") got %+v; want 2 conns with 6 calls`, got)
+	}
+	if got := pool.GroupStats(""); got.Calls != 0 {
+		t.Errorf(`pool.GroupStats("") got %+v; want no calls`, got)
+	}
+}
+
+func TestMethodRoutesUnknownGroup(t *testing.T) {
+	_, err := NewPool(context.Background(), "localhost:1",
+		WithSize(2),
+		WithMethodRoutes(map[string]string{"/pkg.Service/*": "missing"}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err == nil {
+		t.Fatal("NewPool with a route to an unknown group succeeded")
+	}
+}
@@ -0,0 +1,74 @@
+package grpcpool
+
+// runtimeConfig holds the settings of a pool that can be changed while it serves calls. It is immutable: the
+// Set methods of Pool replace it copy-on-write, so calls only load an atomic.Pointer and never take a lock for it.
+type runtimeConfig struct {
+	picker  Picker
+	allowed *methodMatcher
+	denied  *methodMatcher
+	limiter *limiter
+}
+
+// runtime returns the initial runtimeConfig of a pool with o.
+func (o *options) runtime() *runtimeConfig {
+	return &runtimeConfig{picker: o.picker, allowed: o.allowed, denied: o.denied, limiter: o.limiter}
+}
+
+// runtime returns the current runtimeConfig of p.
+func (p *Pool) runtime() *runtimeConfig {
+	return p.rt.Load()
+}
+
+// update replaces the runtimeConfig of p with a copy changed by f.
+func (p *Pool) update(f func(rt *runtimeConfig)) {
+	p.rtMu.Lock()
+	defer p.rtMu.Unlock()
+	rt := *p.rt.Load()
+	f(&rt)
+	p.rt.Store(&rt)
+}
+
+// SetPicker replaces the Picker of the pool, see WithPicker. Calls already started keep their connection.
+func (p *Pool) SetPicker(picker Picker) {
+	p.update(func(rt *runtimeConfig) {
+		rt.picker = picker
+	})
+}
+
+// SetAllowedMethods replaces the allowed methods of the pool, see WithAllowedMethods.
+// Without patterns, every method is allowed. Calls made directly on ClientConns obtained from Conn are only checked
+// if the pool was created with WithAllowedMethods or WithDeniedMethods.
+func (p *Pool) SetAllowedMethods(patterns ...string) {
+	p.update(func(rt *runtimeConfig) {
+		rt.allowed = nil
+		if len(patterns) > 0 {
+			rt.allowed = newMethodMatcher(patterns)
+		}
+	})
+}
+
+// SetDeniedMethods replaces the denied methods of the pool, see WithDeniedMethods.
+// Without patterns, no method is denied. ClientConns obtained from Conn are checked as for SetAllowedMethods.
+func (p *Pool) SetDeniedMethods(patterns ...string) {
+	p.update(func(rt *runtimeConfig) {
+		rt.denied = nil
+		if len(patterns) > 0 {
+			rt.denied = newMethodMatcher(patterns)
+		}
+	})
+}
+
+// SetConcurrencyLimit replaces the concurrency limit of the pool, see WithConcurrencyLimit. A limit of zero
+// removes it.
+//
+// Calls admitted or queued by the previous limit are finished and admitted by it, so in-flight calls don't count
+// towards the new limit.
+func (p *Pool) SetConcurrencyLimit(limit, maxQueue int) {
+	p.update(func(rt *runtimeConfig) {
+		rt.limiter = nil
+		if limit > 0 {
+			rt.limiter = newLimiter(limit, maxQueue)
+			rt.limiter.caller = p.opts.fairCaller
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package grpcpool
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestSetPicker(t *testing.T) {
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithSize(3),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	last := pool.Conns()[2]
+	pool.SetPicker(PickerFunc(func(_ PickInfo, conns []*PoolConn) *PoolConn {
+		return conns[len(conns)-1]
+	}))
+	for i := 0; i < 3; i++ {
+		if got := pool.Conn(); got != last.ClientConn() {
+			t.Fatalf("pool.Conn() #%d after SetPicker got %v; want the last conn", i, got)
+		}
+	}
+}
+
+func TestSetPickerDuringSwap(t *testing.T) {
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	pool.mu.Lock() // as held by a swap draining its old connections
+	defer pool.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		pool.SetPicker(Random())
+		pool.SetConcurrencyLimit(10, 0)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("runtime config changes waited for the swap")
+	}
+}
+
+func TestSetMethods(t *testing.T) {
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithAllowedMethods("/pkg.A/*"),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithUnaryInterceptor(nopInvoker)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	call := func(method string) codes.Code {
+		return status.Code(pool.Invoke(context.Background(), method, nil, nil))
+	}
+	if got := call("/pkg.B/Get"); got != codes.PermissionDenied {
+		t.Errorf("call to a method not allowed got %v; want PermissionDenied", got)
+	}
+	pool.SetAllowedMethods()
+	if got := call("/pkg.B/Get"); got != codes.OK {
+		t.Errorf("call after clearing the allowed methods got %v; want OK", got)
+	}
+	pool.SetDeniedMethods("/pkg.B/Get")
+	if got := call("/pkg.B/Get"); got != codes.PermissionDenied {
+		t.Errorf("call to a denied method got %v; want PermissionDenied", got)
+	}
+	pool.SetDeniedMethods()
+	if got := call("/pkg.B/Get"); got != codes.OK {
+		t.Errorf("call after clearing the denied methods got %v; want OK", got)
+	}
+}
+
+func TestSetConcurrencyLimit(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithDialOptions(
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithUnaryInterceptor(func(_ context.Context, method string, _, _ interface{}, _ *grpc.ClientConn, _ grpc.UnaryInvoker, _ ...grpc.CallOption) error {
+				if method == "/test.Test/Block" {
+					started <- struct{}{}
+					<-release
+				}
+				return nil
+			}),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	pool.SetConcurrencyLimit(1, 0)
+	go pool.Invoke(context.Background(), "/test.Test/Block", nil, nil)
+	<-started
+	if err := pool.Invoke(context.Background(), "/test.Test/Call", nil, nil); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("call over the limit got %v; want ResourceExhausted", err)
+	}
+	pool.SetConcurrencyLimit(0, 0)
+	if err := pool.Invoke(context.Background(), "/test.Test/Call", nil, nil); err != nil {
+		t.Errorf("call after removing the limit got %v", err)
+	}
+	close(release)
+}
+
+func TestSetConcurrent(t *testing.T) {
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithSize(2),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithUnaryInterceptor(nopInvoker)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			pool.SetPicker(RoundRobin())
+			pool.SetConcurrencyLimit(100, 100)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			if err := pool.Invoke(context.Background(), "/test.Test/Call", nil, nil); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+}
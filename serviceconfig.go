@@ -0,0 +1,22 @@
+package grpcpool
+
+import "google.golang.org/grpc"
+
+// WithServiceConfig sets the gRPC service config JSON of every connection of the pool, e.g. its retry policy,
+// method config timeouts and load balancing policy, so the RPC policy of a dependency lives with its pool:
+//
+//	grpcpool.WithServiceConfig(`{
+//		"loadBalancingConfig": [{"round_robin": {}}],
+//		"methodConfig": [{
+//			"name": [{"service": "billing.v1.Billing"}],
+//			"timeout": "2s",
+//			"retryPolicy": {"maxAttempts": 3, "initialBackoff": "0.1s", "maxBackoff": "1s",
+//				"backoffMultiplier": 2, "retryableStatusCodes": ["UNAVAILABLE"]}
+//		}]
+//	}`)
+//
+// It is the default service config of the connections, see grpc.WithDefaultServiceConfig, so a service config
+// provided by the resolver, e.g. in DNS TXT records, takes precedence. NewPool fails if sc is invalid.
+func WithServiceConfig(sc string) Option {
+	return WithDialOptions(grpc.WithDefaultServiceConfig(sc))
+}
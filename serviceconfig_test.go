@@ -0,0 +1,53 @@
+package grpcpool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestServiceConfigRetryPolicy(t *testing.T) {
+	var attempts atomic.Int64
+	l := streamServer(t, func(_ interface{}, stream grpc.ServerStream) error {
+		if attempts.Add(1) == 1 {
+			return status.Error(codes.Unavailable, "overloaded")
+		}
+		if err := stream.RecvMsg(&emptypb.Empty{}); err != nil {
+			return err
+		}
+		return stream.SendMsg(&emptypb.Empty{})
+	})
+
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(1),
+		WithServiceConfig(`{"methodConfig": [{
+			"name": [{"service": "test.Test"}],
+			"retryPolicy": {"maxAttempts": 2, "initialBackoff": "0.01s", "maxBackoff": "0.01s",
+				"backoffMultiplier": 1, "retryableStatusCodes": ["UNAVAILABLE"]}
+		}]}`),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	if err := pool.Invoke(context.Background(), "/test.Test/Call", &emptypb.Empty{}, &emptypb.Empty{}); err != nil {
+		t.Fatalf("Invoke got %v; want it retried", err)
+	}
+	if n := attempts.Load(); n != 2 {
+		t.Errorf("server got %d attempts; want 2", n)
+	}
+}
+
+func TestServiceConfigInvalid(t *testing.T) {
+	_, err := NewFromConfig(context.Background(), Config{Target: "localhost:1", Size: 1, ServiceConfig: `{"methodConfig": 1}`})
+	if err == nil {
+		t.Error("NewFromConfig with an invalid service config succeeded")
+	}
+}
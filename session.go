@@ -0,0 +1,107 @@
+package grpcpool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// ReleaseFunc releases a session bound by BindSession.
+type ReleaseFunc func()
+
+type sessionKey struct{}
+
+type pinKey struct{}
+
+// pinKeysUsed is set once ContextWithPinKey is called, so calls without pin keys skip the context lookup.
+var pinKeysUsed atomic.Bool
+
+// ContextWithPinKey returns a context that pins every stream and call made with it, or a context derived from it,
+// to the connection chosen by hashing key.
+//
+// Related streams, e.g. a watch plus a command stream, opened with the same key land on the same connection of a
+// pool so the server sees them on one transport, without holding a session open as BindSession does.
+// The connection is chosen among all connections of the pool regardless of their health, so the placement only
+// changes when the pool's connections change.
+func ContextWithPinKey(ctx context.Context, key string) context.Context {
+	pinKeysUsed.Store(true)
+	return context.WithValue(ctx, pinKey{}, key)
+}
+
+// pinKeyFromContext returns the pin key of ctx, if any.
+func pinKeyFromContext(ctx context.Context) string {
+	if !pinKeysUsed.Load() || ctx == nil {
+		return ""
+	}
+	key, _ := ctx.Value(pinKey{}).(string)
+	return key
+}
+
+type session struct {
+	pool *Pool
+	set  *connSet
+	conn *PoolConn
+
+	mu       sync.Mutex
+	released bool
+}
+
+// BindSession picks a connection once and returns a context that makes every Invoke and NewStream on the pool
+// with it, or a context derived from it, use that connection until release is called.
+//
+// It is meant for workflows that require connection-level stickiness, e.g. several related streams that the server
+// must see on one transport. The connection is kept open until release is called, also if the pool's target is
+// swapped in the meantime, so release must always be called. After release, calls with the context are picked
+// normally again. Calling release more than once is a no-op.
+func (p *Pool) BindSession(ctx context.Context) (context.Context, ReleaseFunc) {
+	s, c := p.conn(p.pickInfo(ctx, "", false, nil, nil))
+	sess := &session{pool: p, set: s, conn: c}
+	p.sessions.Add(1)
+	return context.WithValue(ctx, sessionKey{}, sess), sess.release
+}
+
+// SessionConn returns the connection bound to ctx by BindSession, if any.
+func (p *Pool) SessionConn(ctx context.Context) (*PoolConn, bool) {
+	if sess := p.session(ctx); sess != nil {
+		sess.mu.Lock()
+		defer sess.mu.Unlock()
+		if !sess.released {
+			return sess.conn, true
+		}
+	}
+	return nil, false
+}
+
+// acquire increments the active count of the session's connSet, unless the session is released.
+func (s *session) acquire() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.released {
+		return false
+	}
+	s.set.active.Add(1)
+	return true
+}
+
+func (s *session) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.released {
+		return
+	}
+	s.released = true
+	s.pool.sessions.Add(-1)
+	s.set.active.Add(-1)
+}
+
+// session returns the session of p bound to ctx, if any.
+func (p *Pool) session(ctx context.Context) *session {
+	if p.sessions.Load() == 0 || ctx == nil {
+		return nil
+	}
+	sess, ok := ctx.Value(sessionKey{}).(*session)
+	if !ok || sess.pool != p {
+		return nil
+	}
+	return sess
+}
@@ -0,0 +1,117 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestBindSession(t *testing.T) {
+	l := healthTestServer(t, "", healthpb.HealthCheckResponse_SERVING)
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(4),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, release := pool.BindSession(context.Background())
+	bound, ok := pool.SessionConn(ctx)
+	if !ok {
+		t.Fatal("pool.SessionConn() found no session")
+	}
+
+	client := healthpb.NewHealthClient(pool)
+	for i := 0; i < 8; i++ {
+		if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	watchCtx, cancel := context.WithCancel(ctx)
+	watch, err := client.Watch(watchCtx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := watch.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	for i, n := range callsPerConn(pool) {
+		if i == bound.Index() && n < 8 {
+			t.Errorf("bound conn got %d calls; want at least 8", n)
+		}
+		if i != bound.Index() && n != 0 {
+			t.Errorf("conn #%d got %d calls in a session bound to conn #%d", i, n, bound.Index())
+		}
+	}
+
+	release()
+	release()
+	if _, ok := pool.SessionConn(ctx); ok {
+		t.Error("pool.SessionConn() found a released session")
+	}
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelDrain()
+	if err := pool.set.Load().drain(drainCtx, SystemClock()); err != nil {
+		t.Errorf("pool still active after release: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	used := 0
+	for _, n := range callsPerConn(pool) {
+		if n > 0 {
+			used++
+		}
+	}
+	if used != 4 {
+		t.Errorf("calls after release used %d conns; want 4", used)
+	}
+}
+
+func TestContextWithPinKey(t *testing.T) {
+	l := healthTestServer(t, "", healthpb.HealthCheckResponse_SERVING)
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(4),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithCancel(ContextWithPinKey(context.Background(), "session-1"))
+	defer cancel()
+	client := healthpb.NewHealthClient(pool)
+	for i := 0; i < 3; i++ {
+		watch, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := watch.Recv(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	used := 0
+	for _, c := range pool.Conns() {
+		if c.InFlight() > 0 {
+			used++
+			if c.InFlight() != 3 {
+				t.Errorf("pinned conn has %d open streams; want 3", c.InFlight())
+			}
+		}
+	}
+	if used != 1 {
+		t.Errorf("pinned streams used %d conns; want 1", used)
+	}
+}
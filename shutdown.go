@@ -0,0 +1,41 @@
+package grpcpool
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// WithShutdownHook calls f when Shutdown starts, so the application can end its open streams, e.g. by canceling
+// watches or sending their last messages. ctx is the context given to Shutdown.
+func WithShutdownHook(f func(ctx context.Context)) Option {
+	return func(o *options) {
+		o.shutdownHooks = append(o.shutdownHooks, f)
+	}
+}
+
+// Shutdown gracefully closes the pool: it stops new calls and streams, calls the functions given to
+// WithShutdownHook, waits for the open calls and streams to finish, and then closes the pool as by Close.
+//
+// If ctx is done first, the pool is closed anyway, ending the remaining streams, and ctx.Err() is returned. New
+// calls and streams fail with Unavailable while waiting, so steady traffic can't keep the pool from draining.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.shutdown.Store(true)
+	for _, f := range p.opts.shutdownHooks {
+		f(ctx)
+	}
+	err := p.set.Load().drain(ctx, p.opts.clock)
+	if cerr := p.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// checkShutdown returns an error for new calls and streams once Shutdown started.
+func (p *Pool) checkShutdown() error {
+	if p.shutdown.Load() {
+		return status.Error(codes.Unavailable, "grpcpool: pool is shutting down")
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package grpcpool
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// echoStreamServer starts a server echoing every message of a stream until the client closes it.
+func echoStreamServer(t *testing.T) net.Listener {
+	t.Helper()
+	s := grpc.NewServer(grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+		for {
+			m := &emptypb.Empty{}
+			if err := stream.RecvMsg(m); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			if err := stream.SendMsg(m); err != nil {
+				return err
+			}
+		}
+	}))
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+	return l
+}
+
+func TestShutdown(t *testing.T) {
+	l := echoStreamServer(t)
+
+	var cs grpc.ClientStream
+	hooked := make(chan struct{})
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithShutdownHook(func(context.Context) {
+			close(hooked)
+			go func() {
+				// The application ends its stream after the hook.
+				time.Sleep(50 * time.Millisecond)
+				cs.CloseSend()
+				cs.RecvMsg(&emptypb.Empty{})
+			}()
+		}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc := &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}
+	cs, err = pool.NewStream(context.Background(), desc, "/test.Echo/Echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- pool.Shutdown(ctx) }()
+	<-hooked
+	if _, err := pool.NewStream(ctx, desc, "/test.Echo/Echo"); status.Code(err) != codes.Unavailable {
+		t.Errorf("NewStream() while shutting down got %v; want Unavailable", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Shutdown() got %v", err)
+	}
+	if pool.ActiveStreams() != 0 {
+		t.Error("Shutdown returned with an open stream")
+	}
+	if state := pool.Conns()[0].State(); state != connectivity.Shutdown {
+		t.Errorf("conn state after Shutdown got %v; want SHUTDOWN", state)
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	l := echoStreamServer(t)
+
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := pool.NewStream(context.Background(), &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}, "/test.Echo/Echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := pool.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() with an open stream got %v; want DeadlineExceeded", err)
+	}
+	if err := cs.RecvMsg(&emptypb.Empty{}); status.Code(err) != codes.Canceled {
+		t.Errorf("RecvMsg() after Shutdown got %v; want Canceled", err)
+	}
+}
+
+func TestShutdownUnaryTraffic(t *testing.T) {
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithSize(2),
+		WithDialOptions(
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithUnaryInterceptor(func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, grpc.UnaryInvoker, ...grpc.CallOption) error {
+				time.Sleep(5 * time.Millisecond)
+				return nil
+			}),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	refused := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		go func() {
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if err := pool.Invoke(context.Background(), "/test.Test/Call", nil, nil); err != nil {
+					refused <- err
+					return
+				}
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pool.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() under steady unary traffic got %v", err)
+	}
+	if err := <-refused; status.Code(err) != codes.Unavailable {
+		t.Errorf("Invoke() while shutting down got %v; want Unavailable", err)
+	}
+}
@@ -0,0 +1,117 @@
+package grpcpool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// WithSingleflight coalesces concurrent identical unary calls to the methods idempotent reports true for into one
+// call whose reply and error are fanned out to every caller, cutting duplicate load when many callers miss a cache
+// at once. Calls are identical when their method and the deterministic proto encoding of their request are.
+//
+// Only mark methods whose reply doesn't depend on the caller: calls are coalesced regardless of their metadata, e.g.
+// credentials, and of their CallOptions, and the callers whose call is coalesced into another one get a copy of its
+// reply without headers or trailers. Requests and replies that aren't proto messages aren't coalesced. If the
+// caller whose call is made gives up first, the others make the call again. See Pool.Coalesced.
+func WithSingleflight(idempotent func(method string) bool) Option {
+	return func(o *options) {
+		o.singleflight = &singleflight{idempotent: idempotent, flights: map[string]*flight{}}
+	}
+}
+
+// IdempotentMethods returns a function reporting true for the full method names given, for WithSingleflight.
+func IdempotentMethods(methods ...string) func(method string) bool {
+	set := make(map[string]bool, len(methods))
+	for _, m := range methods {
+		set[m] = true
+	}
+	return func(method string) bool {
+		return set[method]
+	}
+}
+
+// Coalesced returns the number of unary calls answered with the reply of an identical call, see WithSingleflight.
+func (p *Pool) Coalesced() int64 {
+	if p.opts.singleflight == nil {
+		return 0
+	}
+	return p.opts.singleflight.coalesced.Load()
+}
+
+type singleflight struct {
+	idempotent func(method string) bool
+	coalesced  atomic.Int64
+
+	mu      sync.Mutex
+	flights map[string]*flight // by method and request
+}
+
+// flight is a call coalescing identical ones.
+type flight struct {
+	ctx     context.Context // of the caller making the call
+	waiters int             // callers waiting for the call, guarded by singleflight.mu
+	done    chan struct{}   // closed once reply and err are set
+	reply   proto.Message   // a copy for the waiters, as the caller making the call owns its reply
+	err     error
+}
+
+// coalesce makes the unary call, or waits for an identical one, see WithSingleflight.
+func (p *Pool) coalesce(ctx context.Context, method string, args interface{}, reply interface{}, opts []grpc.CallOption) error {
+	sf := p.opts.singleflight
+	if sf == nil || !sf.idempotent(method) {
+		return p.invoke(ctx, method, args, reply, opts)
+	}
+	req, ok := args.(proto.Message)
+	out, ok2 := reply.(proto.Message)
+	if !ok || !ok2 {
+		return p.invoke(ctx, method, args, reply, opts)
+	}
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return p.invoke(ctx, method, args, reply, opts)
+	}
+	key := method + "\x00" + string(b)
+
+	for {
+		sf.mu.Lock()
+		f, ok := sf.flights[key]
+		if !ok {
+			f = &flight{ctx: ctx, done: make(chan struct{})}
+			sf.flights[key] = f
+			sf.mu.Unlock()
+			f.err = p.invoke(ctx, method, args, reply, opts)
+			sf.mu.Lock()
+			delete(sf.flights, key)
+			waiters := f.waiters
+			sf.mu.Unlock()
+			if waiters > 0 && f.err == nil {
+				f.reply = proto.Clone(out)
+			}
+			close(f.done)
+			return f.err
+		}
+		f.waiters++
+		sf.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-f.done:
+		}
+		if code := status.Code(f.err); (code == codes.Canceled || code == codes.DeadlineExceeded) && f.ctx.Err() != nil && ctx.Err() == nil {
+			continue // the caller making the call gave up, not this one
+		}
+		sf.coalesced.Add(1)
+		if f.err == nil {
+			proto.Reset(out)
+			proto.Merge(out, f.reply)
+		}
+		return f.err
+	}
+}
@@ -0,0 +1,162 @@
+package grpcpool
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// blockingServer starts a server answering calls with their request once release is closed, counting them.
+func blockingServer(t *testing.T) (l net.Listener, calls *atomic.Int64, release chan struct{}) {
+	calls, release = &atomic.Int64{}, make(chan struct{})
+	l = streamServer(t, func(_ interface{}, stream grpc.ServerStream) error {
+		m := &wrapperspb.StringValue{}
+		if err := stream.RecvMsg(m); err != nil && err != io.EOF {
+			return err
+		}
+		calls.Add(1)
+		select {
+		case <-release:
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+		return stream.SendMsg(wrapperspb.String("reply " + m.Value))
+	})
+	return l, calls, release
+}
+
+func singleflightPool(t *testing.T, l net.Listener) *Pool {
+	t.Helper()
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(2),
+		WithSingleflight(IdempotentMethods("/test.Cache/Get")),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pool.Close() })
+	return pool
+}
+
+// waitFor waits until cond holds.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+}
+
+// waiters returns the number of callers waiting for an identical call.
+func waiters(p *Pool) int {
+	sf := p.opts.singleflight
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	n := 0
+	for _, f := range sf.flights {
+		n += f.waiters
+	}
+	return n
+}
+
+func TestSingleflight(t *testing.T) {
+	l, calls, release := blockingServer(t)
+	pool := singleflightPool(t, l)
+
+	const n = 5
+	var wg sync.WaitGroup
+	replies, errs := make([]*wrapperspb.StringValue, n), make([]error, n)
+	for i := 0; i < n; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			replies[i] = &wrapperspb.StringValue{Value: "stale"}
+			errs[i] = pool.Invoke(context.Background(), "/test.Cache/Get", wrapperspb.String("a"), replies[i])
+		}()
+	}
+	waitFor(t, "coalesced callers", func() bool { return waiters(pool) == n-1 })
+	close(release)
+	wg.Wait()
+
+	for i := range replies {
+		if errs[i] != nil || replies[i].Value != "reply a" {
+			t.Errorf("call %d got %q, %v; want %q", i, replies[i].Value, errs[i], "reply a")
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("server got %d calls; want 1", got)
+	}
+	if got := pool.Coalesced(); got != n-1 {
+		t.Errorf("Coalesced() = %d; want %d", got, n-1)
+	}
+}
+
+func TestSingleflightDistinct(t *testing.T) {
+	l, calls, release := blockingServer(t)
+	pool := singleflightPool(t, l)
+
+	var wg sync.WaitGroup
+	for _, call := range []struct{ method, req string }{
+		{"/test.Cache/Get", "a"},
+		{"/test.Cache/Get", "b"},
+		{"/test.Cache/Put", "a"},
+		{"/test.Cache/Put", "a"},
+	} {
+		call := call
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reply := &wrapperspb.StringValue{}
+			if err := pool.Invoke(context.Background(), call.method, wrapperspb.String(call.req), reply); err != nil || reply.Value != "reply "+call.req {
+				t.Errorf("%s(%s) got %q, %v", call.method, call.req, reply.Value, err)
+			}
+		}()
+	}
+	waitFor(t, "4 calls", func() bool { return calls.Load() == 4 })
+	close(release)
+	wg.Wait()
+	if got := pool.Coalesced(); got != 0 {
+		t.Errorf("Coalesced() = %d; want 0", got)
+	}
+}
+
+func TestSingleflightLeaderCanceled(t *testing.T) {
+	l, calls, release := blockingServer(t)
+	pool := singleflightPool(t, l)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error, 1)
+	go func() {
+		leader <- pool.Invoke(ctx, "/test.Cache/Get", wrapperspb.String("a"), &wrapperspb.StringValue{})
+	}()
+	waitFor(t, "the first call", func() bool { return calls.Load() == 1 })
+	follower := make(chan error, 1)
+	reply := &wrapperspb.StringValue{}
+	go func() {
+		follower <- pool.Invoke(context.Background(), "/test.Cache/Get", wrapperspb.String("a"), reply)
+	}()
+	waitFor(t, "the coalesced caller", func() bool { return waiters(pool) == 1 })
+
+	cancel()
+	if err := <-leader; status.Code(err) != codes.Canceled {
+		t.Errorf("canceled call got %v; want Canceled", err)
+	}
+	waitFor(t, "the call again", func() bool { return calls.Load() == 2 })
+	close(release)
+	if err := <-follower; err != nil || reply.Value != "reply a" {
+		t.Errorf("coalesced call got %q, %v; want %q", reply.Value, err, "reply a")
+	}
+}
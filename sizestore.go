@@ -0,0 +1,143 @@
+package grpcpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SizeStore persists the size a growing pool reached across restarts, see WithSizeStore.
+type SizeStore interface {
+	// LoadSize returns the saved size, with ok false if none was saved yet.
+	LoadSize() (n int, ok bool, err error)
+
+	// SaveSize saves the size n.
+	SaveSize(n int) error
+}
+
+// WithSizeStore saves the size of the pool to store whenever it grows or is resized, and starts the pool at the
+// saved size, so a service doesn't learn its capacity needs from cold after every deploy. It only takes effect
+// with WithStreamGrowth, WithThroughputGrowth or spillover connections of WithMaxStreamsPerConn: the pool starts
+// with the saved number of connections, at least the configured size and at most the largest MaxConns.
+//
+// onError, which may be nil, is called with the errors of store; the pool then starts at its configured size.
+func WithSizeStore(store SizeStore, onError func(error)) Option {
+	return func(o *options) {
+		o.sizeStore = &sizeStore{store: store, onError: onError}
+	}
+}
+
+type sizeStore struct {
+	store   SizeStore
+	onError func(error)
+	saved   int // last size loaded or saved, guarded by Pool.mu
+}
+
+func (s *sizeStore) error(err error) {
+	if s.onError != nil {
+		s.onError(err)
+	}
+}
+
+// maxConns returns the number of connections the pool grows to at most, zero if it doesn't grow.
+func (o *options) maxConns() int {
+	n := 0
+	if o.growth != nil && o.growth.MaxConns > n {
+		n = o.growth.MaxConns
+	}
+	if o.throughput != nil && o.throughput.MaxConns > n {
+		n = o.throughput.MaxConns
+	}
+	if o.maxStreams != nil && o.maxStreams.MaxConns > n {
+		n = o.maxStreams.MaxConns
+	}
+	return n
+}
+
+// restoreSize grows p to its saved size, see WithSizeStore.
+func (p *Pool) restoreSize(ctx context.Context) {
+	s, max := p.opts.sizeStore, p.opts.maxConns()
+	if s == nil || max == 0 {
+		return
+	}
+	n, ok, err := s.store.LoadSize()
+	if err != nil {
+		s.error(fmt.Errorf("grpcpool: loading the pool size: %w", err))
+		return
+	}
+	if !ok {
+		return
+	}
+	if n > max {
+		n = max
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s.saved = n
+	for len(p.set.Load().conns) < n {
+		if err := p.addConn(ctx, p.set.Load()); err != nil {
+			s.error(fmt.Errorf("grpcpool: restoring the pool size: %w", err))
+			return
+		}
+	}
+}
+
+// saveSize saves the size of p if it changed, see WithSizeStore. p.mu must be held.
+func (p *Pool) saveSize() {
+	s := p.opts.sizeStore
+	if s == nil || p.opts.maxConns() == 0 {
+		return
+	}
+	n := len(p.set.Load().conns)
+	if n == s.saved {
+		return
+	}
+	if err := s.store.SaveSize(n); err != nil {
+		s.error(fmt.Errorf("grpcpool: saving the pool size: %w", err))
+		return
+	}
+	s.saved = n
+}
+
+// FileSizeStore returns a SizeStore keeping the size in the file at path, e.g. on a volume that outlives the
+// container. The file is replaced atomically, so a crash never leaves it half written.
+func FileSizeStore(path string) SizeStore {
+	return fileSizeStore(path)
+}
+
+type fileSizeStore string
+
+func (f fileSizeStore) LoadSize() (int, bool, error) {
+	b, err := os.ReadFile(string(f))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		return 0, false, fmt.Errorf("%s: %w", f, err)
+	}
+	return n, true, nil
+}
+
+func (f fileSizeStore) SaveSize(n int) error {
+	tmp, err := os.CreateTemp(filepath.Dir(string(f)), filepath.Base(string(f))+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := fmt.Fprintln(tmp, n); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), string(f))
+}
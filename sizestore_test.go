@@ -0,0 +1,80 @@
+package grpcpool
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestFileSizeStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pool.size")
+	store := FileSizeStore(path)
+	if _, ok, err := store.LoadSize(); ok || err != nil {
+		t.Errorf("LoadSize() of a missing file got %v, %v; want none", ok, err)
+	}
+	if err := store.SaveSize(7); err != nil {
+		t.Fatal(err)
+	}
+	if n, ok, err := store.LoadSize(); n != 7 || !ok || err != nil {
+		t.Errorf("LoadSize() got %d, %v, %v; want 7", n, ok, err)
+	}
+	if err := os.WriteFile(path, []byte("seven\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.LoadSize(); err == nil {
+		t.Error("LoadSize() of a corrupt file succeeded")
+	}
+}
+
+type failingSizeStore struct{}
+
+func (failingSizeStore) LoadSize() (int, bool, error) { return 0, false, errors.New("unavailable") }
+func (failingSizeStore) SaveSize(int) error           { return errors.New("unavailable") }
+
+func TestSizeStore(t *testing.T) {
+	_, l := mockServer(t)
+	newPool := func(store SizeStore, onError func(error), opts ...Option) *Pool {
+		t.Helper()
+		opts = append(opts, WithSize(2), WithSizeStore(store, onError), WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())))
+		pool, err := NewPool(context.Background(), l.Addr().String(), opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { pool.Close() })
+		return pool
+	}
+	growth := WithStreamGrowth(StreamGrowthConfig{MaxConns: 5})
+	store := FileSizeStore(filepath.Join(t.TempDir(), "pool.size"))
+	if err := store.SaveSize(4); err != nil {
+		t.Fatal(err)
+	}
+
+	pool := newPool(store, nil, growth)
+	if n := pool.Num(); n != 4 {
+		t.Errorf("Num() of a restored pool got %d; want 4", n)
+	}
+	if err := pool.Resize(context.Background(), 3); err != nil {
+		t.Fatal(err)
+	}
+	if n, _, _ := store.LoadSize(); n != 3 {
+		t.Errorf("saved size after Resize got %d; want 3", n)
+	}
+
+	store.SaveSize(9)
+	if n := newPool(store, nil, growth).Num(); n != 5 {
+		t.Errorf("Num() restored beyond MaxConns got %d; want 5", n)
+	}
+	if n := newPool(store, nil).Num(); n != 2 {
+		t.Errorf("Num() of a pool without growth got %d; want 2", n)
+	}
+
+	var errs []error
+	if n := newPool(failingSizeStore{}, func(err error) { errs = append(errs, err) }, growth).Num(); n != 2 || len(errs) != 1 {
+		t.Errorf("Num() with a failing store got %d with errors %v; want 2 and the error", n, errs)
+	}
+}
@@ -0,0 +1,19 @@
+package grpcpool
+
+import "syscall"
+
+// SocketControl sets options on the socket of a transport of c before it connects, like net.Dialer.Control,
+// e.g. SO_MARK, IP_TOS or SO_BINDTODEVICE. Returning an error fails the dial.
+type SocketControl func(c *PoolConn, network, address string, conn syscall.RawConn) error
+
+// WithSocketControl calls f on the socket of every transport the pool dials, so traffic engineering policies
+// can be applied per connection, e.g. by its index, endpoint or group. With WithEgressProxies, f is called on the
+// sockets to the proxies.
+//
+// f is called by the pool's own dialer: a dialer given with grpc.WithContextDialer takes precedence over it, and
+// WithContextDialer can't be combined with WithSocketControl.
+func WithSocketControl(f SocketControl) Option {
+	return func(o *options) {
+		o.socketControl = f
+	}
+}
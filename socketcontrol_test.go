@@ -0,0 +1,63 @@
+package grpcpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestSocketControl(t *testing.T) {
+	_, l := mockServer(t)
+
+	var mu sync.Mutex
+	controlled := map[int]string{}
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(2),
+		WithSocketControl(func(c *PoolConn, network, address string, conn syscall.RawConn) error {
+			mu.Lock()
+			defer mu.Unlock()
+			controlled[c.Index()] = address
+			return conn.Control(func(uintptr) {})
+		}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pool.WaitForReady(ctx); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(controlled) != 2 || controlled[0] != l.Addr().String() || controlled[1] != l.Addr().String() {
+		t.Errorf("controlled sockets %v; want both conns to %s", controlled, l.Addr())
+	}
+}
+
+func TestSocketControlError(t *testing.T) {
+	_, l := mockServer(t)
+
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSocketControl(func(*PoolConn, string, string, syscall.RawConn) error {
+			return errors.New("no fwmark")
+		}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	waitForState(t, pool.Conns()[0].ClientConn(), connectivity.TransientFailure)
+}
@@ -0,0 +1,35 @@
+package grpcpool
+
+import (
+	"net"
+)
+
+// sourcePorts is the range of local ports connections are dialed from, see WithSourcePorts.
+type sourcePorts struct {
+	first, n int
+}
+
+// WithSourcePorts dials connection i of the pool from local port first + i%n, so every connection is a distinct
+// TCP flow with a fixed 5-tuple.
+//
+// ECMP routers and L4 load balancers hash flows by their 5-tuple; with the source ports spread over a range, the
+// connections of a pool take different paths and backends instead of depending on the luck of ephemeral ports,
+// and a link isn't bottlenecked on the path of a single flow. The ports should be outside the ephemeral range of
+// the host. A connection reconnects from the same port, which fails while the previous socket of the port is in
+// TIME_WAIT on the client; the dial is then retried with the usual backoff. Only TCP endpoints are supported.
+//
+// The ports are set on the pool's own dialer: a dialer given with grpc.WithContextDialer takes precedence over it,
+// and WithContextDialer can't be combined with WithSourcePorts.
+func WithSourcePorts(first, n int) Option {
+	return func(o *options) {
+		o.sourcePorts = &sourcePorts{first: first, n: n}
+	}
+}
+
+// localAddr returns the local address c is dialed from, or nil for any.
+func (o *options) localAddr(c *PoolConn) net.Addr {
+	if o.sourcePorts == nil || o.sourcePorts.n <= 0 {
+		return nil
+	}
+	return &net.TCPAddr{Port: o.sourcePorts.first + c.index%o.sourcePorts.n}
+}
@@ -0,0 +1,82 @@
+package grpcpool
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+)
+
+// freePorts returns the first of n consecutive local ports that are free.
+func freePorts(t *testing.T, n int) int {
+	t.Helper()
+	for try := 0; try < 20; try++ {
+		l, err := net.Listen("tcp", "localhost:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		first := l.Addr().(*net.TCPAddr).Port
+		l.Close()
+		free := true
+		for p := first; p < first+n && free; p++ {
+			l, err := net.Listen("tcp", net.JoinHostPort("localhost", strconv.Itoa(p)))
+			if err != nil {
+				free = false
+				continue
+			}
+			l.Close()
+		}
+		if free {
+			return first
+		}
+	}
+	t.Fatalf("no %d consecutive free ports", n)
+	return 0
+}
+
+func TestSourcePorts(t *testing.T) {
+	var mu sync.Mutex
+	ports := map[int]bool{}
+	s := grpc.NewServer(grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+		if p, ok := peer.FromContext(stream.Context()); ok {
+			mu.Lock()
+			ports[p.Addr.(*net.TCPAddr).Port] = true
+			mu.Unlock()
+		}
+		return nil
+	}))
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(l)
+	defer s.Stop()
+
+	first := freePorts(t, 2)
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(2),
+		WithSourcePorts(first, 2),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pool.WarmStreams(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(ports) != 2 || !ports[first] || !ports[first+1] {
+		t.Errorf("server saw source ports %v; want %d and %d", ports, first, first+1)
+	}
+}
@@ -0,0 +1,339 @@
+package grpcpool
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// SPIFFEEndpointEnv is the environment variable with the address of the SPIFFE Workload API, e.g.
+// "unix:///run/spire/sockets/agent.sock".
+const SPIFFEEndpointEnv = "SPIFFE_ENDPOINT_SOCKET"
+
+// fetchX509SVID is the method of the Workload API streaming X.509-SVIDs.
+const fetchX509SVID = "/SpiffeWorkloadAPI/FetchX509SVID"
+
+// workloadAPIRetry is how long WorkloadAPI waits before reopening a failed stream.
+const workloadAPIRetry = time.Second
+
+// SVIDSource is a source of the X.509-SVID of the workload and the trust bundle of its trust domain,
+// see WithSPIFFE.
+type SVIDSource interface {
+	// SVID returns the current X.509-SVID of the workload.
+	SVID() (*tls.Certificate, error)
+
+	// Bundle returns the current X.509 roots of the trust domain.
+	Bundle() (*x509.CertPool, error)
+}
+
+// WithSPIFFE dials with mTLS based on the SPIFFE identities of src, e.g. a WorkloadAPI, and rolls the pool's
+// connections when the SVID renews.
+//
+// The client presents its X.509-SVID, and verifies the server certificate against the trust bundle of src rather
+// than by host name. If serverID is not empty, the server must have that SPIFFE ID, e.g.
+// "spiffe://example.org/backend". SVID renewals are picked up every interval as by WithCertificateRotation.
+func WithSPIFFE(src SVIDSource, serverID string, interval time.Duration) Option {
+	cfg := &tls.Config{
+		// The chain and the SPIFFE ID are verified by verifySPIFFE instead of against a host name.
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifySPIFFE(src, serverID, rawCerts)
+		},
+	}
+	return WithCertificateRotation(cfg, src.SVID, interval)
+}
+
+// verifySPIFFE verifies that rawCerts are a chain to the trust bundle of src, for serverID if not empty.
+func verifySPIFFE(src SVIDSource, serverID string, rawCerts [][]byte) error {
+	if len(rawCerts) == 0 {
+		return errors.New("grpcpool: server presented no certificate")
+	}
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for i, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("grpcpool: parsing server certificate: %w", err)
+		}
+		certs[i] = cert
+	}
+	roots, err := src.Bundle()
+	if err != nil {
+		return err
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return fmt.Errorf("grpcpool: verifying server SVID: %w", err)
+	}
+	id, err := spiffeID(certs[0])
+	if err != nil {
+		return err
+	}
+	if serverID != "" && id != serverID {
+		return fmt.Errorf("grpcpool: server SPIFFE ID is %s, want %s", id, serverID)
+	}
+	return nil
+}
+
+// spiffeID returns the SPIFFE ID of an X.509-SVID, its only URI SAN.
+func spiffeID(cert *x509.Certificate) (string, error) {
+	if len(cert.URIs) != 1 || cert.URIs[0].Scheme != "spiffe" {
+		return "", errors.New("grpcpool: certificate is not an X.509-SVID")
+	}
+	return cert.URIs[0].String(), nil
+}
+
+// WorkloadAPI is an SVIDSource streaming the X.509-SVID of the workload from the SPIFFE Workload API, e.g. from
+// a SPIRE agent. Renewed SVIDs and bundles are picked up as the Workload API pushes them.
+type WorkloadAPI struct {
+	cc     *grpc.ClientConn
+	cancel context.CancelFunc
+	clock  Clock
+
+	mu     sync.Mutex
+	svid   *tls.Certificate
+	id     string
+	bundle *x509.CertPool
+	err    error // of the last update, if none succeeded yet
+}
+
+var _ SVIDSource = (*WorkloadAPI)(nil)
+
+// WorkloadAPIOption configures a WorkloadAPI.
+type WorkloadAPIOption func(*WorkloadAPI)
+
+// WithWorkloadAPIClock sets the Clock of the delay before a failed stream is reopened. The default is SystemClock.
+func WithWorkloadAPIClock(c Clock) WorkloadAPIOption {
+	return func(w *WorkloadAPI) {
+		w.clock = c
+	}
+}
+
+// NewWorkloadAPI connects to the Workload API at addr, or at SPIFFEEndpointEnv if addr is empty, and waits for
+// the first SVID until ctx is done.
+func NewWorkloadAPI(ctx context.Context, addr string, opts ...WorkloadAPIOption) (*WorkloadAPI, error) {
+	if addr == "" {
+		addr = os.Getenv(SPIFFEEndpointEnv)
+	}
+	if addr == "" {
+		return nil, fmt.Errorf("grpcpool: no Workload API address and %s not set", SPIFFEEndpointEnv)
+	}
+	cc, err := grpc.DialContext(ctx, addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	wctx, cancel := context.WithCancel(context.Background())
+	w := &WorkloadAPI{cc: cc, cancel: cancel, clock: SystemClock(), err: errors.New("grpcpool: no SVID received yet")}
+	for _, o := range opts {
+		o(w)
+	}
+	first := make(chan struct{})
+	go w.watch(wctx, first)
+	select {
+	case <-first:
+		return w, nil
+	case <-ctx.Done():
+		w.Close()
+		return nil, fmt.Errorf("grpcpool: waiting for an SVID from %s: %w", addr, ctx.Err())
+	}
+}
+
+// watch applies the responses of the Workload API until ctx is done, closing first after the first SVID.
+func (w *WorkloadAPI) watch(ctx context.Context, first chan struct{}) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "workload.spiffe.io", "true")
+	desc := &grpc.StreamDesc{ServerStreams: true}
+	for ctx.Err() == nil {
+		cs, err := w.cc.NewStream(ctx, desc, fetchX509SVID, grpc.ForceCodec(rawCodec{}), grpc.WaitForReady(true))
+		if err == nil {
+			if err = cs.SendMsg([]byte(nil)); err == nil {
+				err = cs.CloseSend()
+			}
+		}
+		for err == nil {
+			var msg []byte
+			if err = cs.RecvMsg(&msg); err == nil {
+				if w.update(msg) == nil && first != nil {
+					close(first)
+					first = nil
+				}
+			}
+		}
+		retry := w.clock.NewTimer(workloadAPIRetry)
+		select {
+		case <-ctx.Done():
+		case <-retry.C():
+		}
+		retry.Stop()
+	}
+}
+
+// update applies an X509SVIDResponse.
+func (w *WorkloadAPI) update(msg []byte) error {
+	svid, id, bundle, err := parseX509SVIDResponse(msg)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		if w.svid == nil {
+			w.err = err
+		}
+		return err
+	}
+	w.svid, w.id, w.bundle, w.err = svid, id, bundle, nil
+	return nil
+}
+
+// SVID returns the current X.509-SVID of the workload.
+func (w *WorkloadAPI) SVID() (*tls.Certificate, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.svid, w.err
+}
+
+// Bundle returns the current X.509 roots of the trust domain of the workload.
+func (w *WorkloadAPI) Bundle() (*x509.CertPool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.bundle, w.err
+}
+
+// ID returns the SPIFFE ID of the workload.
+func (w *WorkloadAPI) ID() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.id
+}
+
+// Close stops watching the Workload API.
+func (w *WorkloadAPI) Close() error {
+	w.cancel()
+	return w.cc.Close()
+}
+
+// Fields of the Workload API messages.
+const (
+	x509SVIDResponseSVIDs = 1 // X509SVIDResponse.svids
+
+	x509SVIDID     = 1 // X509SVID.spiffe_id
+	x509SVIDCerts  = 2 // X509SVID.x509_svid, concatenated DER certificates
+	x509SVIDKey    = 3 // X509SVID.x509_svid_key, PKCS#8 DER
+	x509SVIDBundle = 4 // X509SVID.bundle, concatenated DER certificates
+)
+
+// parseX509SVIDResponse returns the first, default SVID of a serialized X509SVIDResponse.
+func parseX509SVIDResponse(b []byte) (*tls.Certificate, string, *x509.CertPool, error) {
+	svid, err := firstField(b, x509SVIDResponseSVIDs)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	var id string
+	var certsDER, keyDER, bundleDER []byte
+	for len(svid) > 0 {
+		num, typ, n := protowire.ConsumeTag(svid)
+		if n < 0 {
+			return nil, "", nil, errors.New("grpcpool: malformed X509SVID")
+		}
+		svid = svid[n:]
+		var v []byte
+		if typ == protowire.BytesType {
+			v, n = protowire.ConsumeBytes(svid)
+		} else {
+			n = protowire.ConsumeFieldValue(num, typ, svid)
+		}
+		if n < 0 {
+			return nil, "", nil, errors.New("grpcpool: malformed X509SVID")
+		}
+		svid = svid[n:]
+		switch num {
+		case x509SVIDID:
+			id = string(v)
+		case x509SVIDCerts:
+			certsDER = v
+		case x509SVIDKey:
+			keyDER = v
+		case x509SVIDBundle:
+			bundleDER = v
+		}
+	}
+
+	certs, err := x509.ParseCertificates(certsDER)
+	if err != nil || len(certs) == 0 {
+		return nil, "", nil, fmt.Errorf("grpcpool: parsing X.509-SVID %s: %v", id, err)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(keyDER)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("grpcpool: parsing X.509-SVID key %s: %w", id, err)
+	}
+	roots, err := x509.ParseCertificates(bundleDER)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("grpcpool: parsing trust bundle of %s: %w", id, err)
+	}
+	if u, err := url.Parse(id); err != nil || u.Scheme != "spiffe" {
+		return nil, "", nil, fmt.Errorf("grpcpool: invalid SPIFFE ID %q", id)
+	}
+
+	cert := &tls.Certificate{PrivateKey: key, Leaf: certs[0]}
+	for _, c := range certs {
+		cert.Certificate = append(cert.Certificate, c.Raw)
+	}
+	bundle := x509.NewCertPool()
+	for _, c := range roots {
+		bundle.AddCert(c)
+	}
+	return cert, id, bundle, nil
+}
+
+// firstField returns the first length-delimited field num of a serialized message.
+func firstField(b []byte, num protowire.Number) ([]byte, error) {
+	for len(b) > 0 {
+		n0, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			break
+		}
+		b = b[n:]
+		if n0 == num && typ == protowire.BytesType {
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				break
+			}
+			return v, nil
+		}
+		if n = protowire.ConsumeFieldValue(n0, typ, b); n < 0 {
+			break
+		}
+		b = b[n:]
+	}
+	return nil, errors.New("grpcpool: Workload API response has no SVID")
+}
+
+// rawCodec passes messages through as []byte.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	return v.([]byte), nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	*v.(*[]byte) = append([]byte(nil), data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
@@ -0,0 +1,186 @@
+package grpcpool
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// x509SVIDResponse returns a serialized X509SVIDResponse with cert as the SVID id and ca as the bundle.
+func x509SVIDResponse(t *testing.T, id string, cert tls.Certificate, ca *testCA) []byte {
+	t.Helper()
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var svid []byte
+	svid = protowire.AppendTag(svid, x509SVIDID, protowire.BytesType)
+	svid = protowire.AppendString(svid, id)
+	svid = protowire.AppendTag(svid, x509SVIDCerts, protowire.BytesType)
+	svid = protowire.AppendBytes(svid, cert.Certificate[0])
+	svid = protowire.AppendTag(svid, x509SVIDKey, protowire.BytesType)
+	svid = protowire.AppendBytes(svid, key)
+	svid = protowire.AppendTag(svid, x509SVIDBundle, protowire.BytesType)
+	svid = protowire.AppendBytes(svid, ca.cert.Raw)
+	resp := protowire.AppendTag(nil, x509SVIDResponseSVIDs, protowire.BytesType)
+	return protowire.AppendBytes(resp, svid)
+}
+
+// workloadAPIServer starts a fake Workload API on a unix socket sending the responses sent on updates. A nil
+// response ends the stream with Unavailable.
+func workloadAPIServer(t *testing.T, updates <-chan []byte) string {
+	t.Helper()
+	s := grpc.NewServer(grpc.ForceServerCodec(rawCodec{}), grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+		if method, _ := grpc.MethodFromServerStream(stream); method != fetchX509SVID {
+			return status.Errorf(codes.Unimplemented, "unknown method %s", method)
+		}
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		if v := md.Get("workload.spiffe.io"); len(v) != 1 || v[0] != "true" {
+			return status.Error(codes.InvalidArgument, "security header missing")
+		}
+		for {
+			select {
+			case <-stream.Context().Done():
+				return nil
+			case msg := <-updates:
+				if msg == nil {
+					return status.Error(codes.Unavailable, "stream reset")
+				}
+				if err := stream.SendMsg(msg); err != nil {
+					return err
+				}
+			}
+		}
+	}))
+	path := filepath.Join(t.TempDir(), "agent.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+	return "unix://" + path
+}
+
+func TestSPIFFE(t *testing.T) {
+	ca := newTestCA(t)
+	l, seen := mtlsServer(t, ca)
+
+	const clientID = "spiffe://example.org/client"
+	updates := make(chan []byte, 1)
+	updates <- x509SVIDResponse(t, clientID, ca.issue(t, 1, time.Now().Add(time.Hour), clientID), ca)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	t.Setenv(SPIFFEEndpointEnv, workloadAPIServer(t, updates))
+	api, err := NewWorkloadAPI(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer api.Close()
+	if id := api.ID(); id != clientID {
+		t.Fatalf("ID() got %q; want %q", id, clientID)
+	}
+
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(2),
+		WithSPIFFE(api, testServerID, time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	if err := pool.WarmStreams(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := seen(); len(got) != 1 || !got[1] {
+		t.Fatalf("server saw client SVIDs %v; want serial 1", got)
+	}
+
+	// The SVID renews.
+	updates <- x509SVIDResponse(t, clientID, ca.issue(t, 2, time.Now().Add(time.Hour), clientID), ca)
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		if svid, _ := api.SVID(); svid.Leaf.SerialNumber.Int64() == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Workload API update never applied")
+		}
+	}
+	pool.checkCertificate(ctx)
+	if err := pool.WarmStreams(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := seen(); len(got) != 1 || !got[2] {
+		t.Fatalf("server saw client SVIDs %v after renewal; want serial 2", got)
+	}
+}
+
+func TestWorkloadAPIRetry(t *testing.T) {
+	ca := newTestCA(t)
+	const id = "spiffe://example.org/client"
+	updates := make(chan []byte, 1)
+	updates <- x509SVIDResponse(t, id, ca.issue(t, 1, time.Now().Add(time.Hour), id), ca)
+	clock := &timerClock{timers: make(chan chan time.Time, 1)}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	api, err := NewWorkloadAPI(ctx, workloadAPIServer(t, updates), WithWorkloadAPIClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer api.Close()
+
+	updates <- nil // the stream fails
+	var retry chan time.Time
+	select {
+	case retry = <-clock.timers:
+	case <-ctx.Done():
+		t.Fatal("WorkloadAPI never waited to reopen the stream")
+	}
+	updates <- x509SVIDResponse(t, id, ca.issue(t, 2, time.Now().Add(time.Hour), id), ca)
+	retry <- time.Now()
+	for ; ; time.Sleep(time.Millisecond) {
+		if svid, _ := api.SVID(); svid.Leaf.SerialNumber.Int64() == 2 {
+			break
+		}
+		if ctx.Err() != nil {
+			t.Fatal("SVID of the reopened stream never applied")
+		}
+	}
+}
+
+func TestSPIFFEWrongServer(t *testing.T) {
+	ca := newTestCA(t)
+	l, _ := mtlsServer(t, ca)
+
+	const clientID = "spiffe://example.org/client"
+	updates := make(chan []byte, 1)
+	updates <- x509SVIDResponse(t, clientID, ca.issue(t, 1, time.Now().Add(time.Hour), clientID), ca)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	api, err := NewWorkloadAPI(ctx, workloadAPIServer(t, updates))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer api.Close()
+
+	pool, err := NewPool(context.Background(), l.Addr().String(), WithSPIFFE(api, "spiffe://example.org/other", time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	wctx, wcancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer wcancel()
+	if err := pool.WarmStreams(wctx, 1); err == nil {
+		t.Fatal("WarmStreams() to a server with another SPIFFE ID got no error")
+	}
+}
@@ -0,0 +1,126 @@
+package grpcpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/hashicorp/go-multierror"
+	"google.golang.org/grpc"
+)
+
+// splitScale is the resolution of a SplitPool's weight, in parts per splitScale.
+const splitScale = 10000
+
+var _ ConnPool = &SplitPool{}
+
+// SplitPool is a ConnPool that splits traffic between a blue and a green pool by percentage.
+//
+// It can be used to drive blue/green or canary migrations between two targets from the client side.
+// The split can be adjusted at runtime with SetGreenPercent.
+type SplitPool struct {
+	blue, green ConnPool
+
+	weight uint64 // green share in parts per splitScale, access via sync/atomic
+	idx    uint64 // access via sync/atomic
+}
+
+// NewSplitPool creates a SplitPool sending greenPercent percent of calls to green and the rest to blue.
+func NewSplitPool(blue, green ConnPool, greenPercent float64) (*SplitPool, error) {
+	if blue == nil || green == nil {
+		return nil, errors.New("grpcpool: split pools must not be nil")
+	}
+	p := &SplitPool{blue: blue, green: green}
+	if err := p.SetGreenPercent(greenPercent); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// NewSplitPoolTargets creates a SplitPool with a Pool to blueTarget and a Pool to greenTarget, both dialed with opts.
+func NewSplitPoolTargets(ctx context.Context, blueTarget, greenTarget string, greenPercent float64, opts ...Option) (*SplitPool, error) {
+	blue, err := NewPool(ctx, blueTarget, opts...)
+	if err != nil {
+		return nil, err
+	}
+	green, err := NewPool(ctx, greenTarget, opts...)
+	if err != nil {
+		blue.Close()
+		return nil, err
+	}
+	p, err := NewSplitPool(blue, green, greenPercent)
+	if err != nil {
+		blue.Close()
+		green.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetGreenPercent changes the percentage of calls sent to the green pool.
+//
+// percent must be between 0 and 100.
+func (p *SplitPool) SetGreenPercent(percent float64) error {
+	if !(percent >= 0 && percent <= 100) { // also rejects NaN
+		return errors.New("grpcpool: split percent must be between 0 and 100")
+	}
+	atomic.StoreUint64(&p.weight, uint64(percent*splitScale/100+0.5))
+	return nil
+}
+
+// GreenPercent returns the percentage of calls sent to the green pool.
+func (p *SplitPool) GreenPercent() float64 {
+	return float64(atomic.LoadUint64(&p.weight)) * 100 / splitScale
+}
+
+// Blue returns the blue pool.
+func (p *SplitPool) Blue() ConnPool {
+	return p.blue
+}
+
+// Green returns the green pool.
+func (p *SplitPool) Green() ConnPool {
+	return p.green
+}
+
+// pick returns the pool for the next call.
+//
+// Green calls are spread evenly over the sequence of calls rather than sent in bursts.
+func (p *SplitPool) pick() ConnPool {
+	i := atomic.AddUint64(&p.idx, 1)
+	w := atomic.LoadUint64(&p.weight)
+	if (i*w)/splitScale != ((i-1)*w)/splitScale {
+		return p.green
+	}
+	return p.blue
+}
+
+// Conn returns a ClientConn from the blue or the green pool.
+func (p *SplitPool) Conn() *grpc.ClientConn {
+	return p.pick().Conn()
+}
+
+// Num returns the number of connections in both pools.
+func (p *SplitPool) Num() int {
+	return p.blue.Num() + p.green.Num()
+}
+
+// Close closes both pools.
+func (p *SplitPool) Close() error {
+	var errs error
+	if err := p.blue.Close(); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	if err := p.green.Close(); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	return errs
+}
+
+func (p *SplitPool) Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error {
+	return p.pick().Invoke(ctx, method, args, reply, opts...)
+}
+
+func (p *SplitPool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return p.pick().NewStream(ctx, desc, method, opts...)
+}
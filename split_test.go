@@ -0,0 +1,63 @@
+package grpcpool
+
+import (
+	"math"
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+func TestSplitPool(t *testing.T) {
+	blueConn := &grpc.ClientConn{}
+	greenConn := &grpc.ClientConn{}
+	blue := &roundRobinConnPool{conns: []*grpc.ClientConn{blueConn}}
+	green := &roundRobinConnPool{conns: []*grpc.ClientConn{greenConn}}
+
+	pool, err := NewSplitPool(blue, green, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pool.Num() != 2 {
+		t.Errorf("pool.Num() got %d; want 2", pool.Num())
+	}
+
+	count := func() int {
+		n := 0
+		for i := 0; i < 1000; i++ {
+			if pool.Conn() == greenConn {
+				n++
+			}
+		}
+		return n
+	}
+	if n := count(); n != 50 {
+		t.Errorf("green got %d of 1000 calls at 5%%; want 50", n)
+	}
+
+	if err := pool.SetGreenPercent(100); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(); n != 1000 {
+		t.Errorf("green got %d of 1000 calls at 100%%; want 1000", n)
+	}
+
+	if err := pool.SetGreenPercent(0); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(); n != 0 {
+		t.Errorf("green got %d of 1000 calls at 0%%; want 0", n)
+	}
+
+	if err := pool.SetGreenPercent(101); err == nil {
+		t.Error("SetGreenPercent(101) succeeded")
+	}
+	if err := pool.SetGreenPercent(math.NaN()); err == nil {
+		t.Error("SetGreenPercent(NaN) succeeded")
+	}
+	if _, err := NewSplitPool(blue, green, math.NaN()); err == nil {
+		t.Error("NewSplitPool with NaN percent succeeded")
+	}
+	if got := pool.GreenPercent(); got != 0 {
+		t.Errorf("pool.GreenPercent() got %v; want 0", got)
+	}
+}
@@ -0,0 +1,67 @@
+package grpcpool
+
+import (
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// StreamEvent describes a stream of the pool to StreamHooks.
+type StreamEvent struct {
+	Method string
+	Conn   int // index of the connection of the stream
+	Start  time.Time
+
+	// Duration and Code are set when the stream ended, to how long it was open and the code it ended with.
+	Duration time.Duration
+	Code     codes.Code
+}
+
+// StreamHooks are called on the lifecycle of every stream of the pool, see WithStreamHooks.
+type StreamHooks struct {
+	// OnStreamStart is called when a stream is opened on a connection, before it is started, so it is always
+	// called before OnStreamEnd.
+	OnStreamStart func(StreamEvent)
+
+	// OnStreamEnd is called once when a stream ended, including streams that failed to start.
+	OnStreamEnd func(StreamEvent)
+}
+
+// WithStreamHooks calls hooks on the start and end of every stream of the pool, e.g. to meter long-lived streams
+// per connection. Hooks are called synchronously by the goroutine opening or ending the stream, so they must be
+// fast. Nil hooks are skipped; given several times, all hooks are called in order.
+func WithStreamHooks(hooks StreamHooks) Option {
+	return func(o *options) {
+		o.streamHooks = append(o.streamHooks, hooks)
+	}
+}
+
+// streamStart calls the OnStreamStart hooks for sc.
+func (p *Pool) streamStart(sc *streamCall) {
+	e := StreamEvent{Method: sc.method, Conn: sc.conn.index, Start: sc.start}
+	for _, h := range p.opts.streamHooks {
+		if h.OnStreamStart != nil {
+			h.OnStreamStart(e)
+		}
+	}
+}
+
+// streamEnd calls the OnStreamEnd hooks for sc ending with err.
+func (p *Pool) streamEnd(sc *streamCall, err error) {
+	if len(p.opts.streamHooks) == 0 {
+		return
+	}
+	e := StreamEvent{
+		Method:   sc.method,
+		Conn:     sc.conn.index,
+		Start:    sc.start,
+		Duration: p.opts.clock.Now().Sub(sc.start),
+		Code:     status.Code(err),
+	}
+	for _, h := range p.opts.streamHooks {
+		if h.OnStreamEnd != nil {
+			h.OnStreamEnd(e)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package grpcpool
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestStreamHooks(t *testing.T) {
+	l := echoStreamServer(t)
+	var mu sync.Mutex
+	var events []string
+	var ends []StreamEvent
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(2),
+		WithStreamHooks(StreamHooks{
+			OnStreamStart: func(e StreamEvent) {
+				mu.Lock()
+				events = append(events, "start "+e.Method)
+				mu.Unlock()
+			},
+			OnStreamEnd: func(e StreamEvent) {
+				mu.Lock()
+				events = append(events, "end "+e.Method)
+				ends = append(ends, e)
+				mu.Unlock()
+			},
+		}),
+		WithStreamHooks(StreamHooks{}), // nil hooks are skipped
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	desc := &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}
+
+	cs, err := pool.NewStream(context.Background(), desc, "/test.Echo/Done")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs.CloseSend()
+	if err := cs.RecvMsg(&emptypb.Empty{}); err != io.EOF {
+		t.Fatalf("RecvMsg() got %v; want io.EOF", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cs, err = pool.NewStream(ctx, desc, "/test.Echo/Canceled")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if err := cs.RecvMsg(&emptypb.Empty{}); err == nil {
+		t.Fatal("RecvMsg() of a canceled stream succeeded")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"start /test.Echo/Done", "end /test.Echo/Done", "start /test.Echo/Canceled", "end /test.Echo/Canceled"}
+	if len(events) != len(want) {
+		t.Fatalf("hooks got %v; want %v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("hook %d got %q; want %q", i, events[i], want[i])
+		}
+	}
+	if ends[0].Code != codes.OK || ends[1].Code != codes.Canceled {
+		t.Errorf("end codes got %v, %v; want OK, Canceled", ends[0].Code, ends[1].Code)
+	}
+	if ends[0].Conn == ends[1].Conn {
+		t.Errorf("both streams on conn %d; want round robin over both", ends[0].Conn)
+	}
+	if ends[0].Start.IsZero() || ends[0].Duration <= 0 {
+		t.Errorf("end event got start %v, duration %v; want them set", ends[0].Start, ends[0].Duration)
+	}
+}
@@ -0,0 +1,81 @@
+package grpcpool
+
+import (
+	"context"
+	"time"
+)
+
+// StreamLoad is the load of the open streams of a connection or group of connections, for capacity planning of
+// stream-heavy workloads, see PoolConn.StreamLoad.
+type StreamLoad struct {
+	// Open is the number of open streams, as PoolConn.ActiveStreams.
+	Open int64
+
+	// Sending is the number of open client-streaming and bidirectional streams that didn't call CloseSend yet.
+	Sending int64
+
+	// Watching is the number of open streams only receiving: server-streaming streams, e.g. watches and feeds, and
+	// the streams that called CloseSend.
+	Watching int64
+}
+
+func (l StreamLoad) add(o StreamLoad) StreamLoad {
+	return StreamLoad{Open: l.Open + o.Open, Sending: l.Sending + o.Sending, Watching: l.Watching + o.Watching}
+}
+
+// StreamLoad returns the load of the streams open on the connection.
+func (c *PoolConn) StreamLoad() StreamLoad {
+	open, sending := c.streams.Load(), c.sending.Load()
+	if sending > open { // a stream opened between the loads
+		sending = open
+	}
+	return StreamLoad{Open: open, Sending: sending, Watching: open - sending}
+}
+
+// StreamLoad returns the load of the streams open on the pool.
+func (p *Pool) StreamLoad() StreamLoad {
+	var l StreamLoad
+	for _, c := range p.set.Load().conns {
+		l = l.add(c.StreamLoad())
+	}
+	return l
+}
+
+// WithStreamLoadReport calls f with the stream load of every connection of the pool every interval, e.g. to
+// export it as metrics. f is called from a single goroutine, in the order of the connections.
+func WithStreamLoadReport(interval time.Duration, f func(c *PoolConn, load StreamLoad)) Option {
+	return func(o *options) {
+		o.streamLoad = &streamLoadReport{interval: interval, f: f}
+	}
+}
+
+type streamLoadReport struct {
+	interval time.Duration
+	f        func(*PoolConn, StreamLoad)
+}
+
+// startStreamLoadReport reports the stream load until stop is called.
+func (p *Pool) startStreamLoadReport() (stop func()) {
+	r := p.opts.streamLoad
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	ticker := p.opts.clock.NewTicker(r.interval)
+	go func() {
+		defer close(done)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+				for _, c := range p.set.Load().conns {
+					r.f(c, c.StreamLoad())
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
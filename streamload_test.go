@@ -0,0 +1,83 @@
+package grpcpool
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestStreamLoad(t *testing.T) {
+	l := echoStreamServer(t)
+	var mu sync.Mutex
+	reported := map[int]StreamLoad{}
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithStreamLoadReport(10*time.Millisecond, func(c *PoolConn, load StreamLoad) {
+			mu.Lock()
+			reported[c.Index()] = load
+			mu.Unlock()
+		}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	bidi, err := pool.NewStream(ctx, &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}, "/test.Echo/Chat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	watch, err := pool.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, "/test.Echo/Watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := watch.SendMsg(&emptypb.Empty{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := pool.StreamLoad(), (StreamLoad{Open: 2, Sending: 1, Watching: 1}); got != want {
+		t.Errorf("StreamLoad() got %+v; want %+v", got, want)
+	}
+	bidi.CloseSend()
+	want := StreamLoad{Open: 2, Watching: 2}
+	if got := pool.Conns()[0].Stats().Streams; got != want {
+		t.Errorf("Stats().Streams after CloseSend got %+v; want %+v", got, want)
+	}
+	if got := pool.GroupStats("").Streams; got != want {
+		t.Errorf("GroupStats().Streams after CloseSend got %+v; want %+v", got, want)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		got := reported[0]
+		mu.Unlock()
+		if got == want {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("reported load got %+v; want %+v", got, want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	watch.CloseSend()
+	for _, cs := range []grpc.ClientStream{bidi, watch} {
+		var err error
+		for err == nil {
+			err = cs.RecvMsg(&emptypb.Empty{})
+		}
+		if err != io.EOF {
+			t.Fatalf("RecvMsg() got %v; want io.EOF", err)
+		}
+	}
+	if got := pool.StreamLoad(); got != (StreamLoad{}) {
+		t.Errorf("StreamLoad() after the streams ended got %+v; want none", got)
+	}
+}
@@ -0,0 +1,219 @@
+package grpcpool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	// managedStreamBackoff is the delay before the first restart of a failed managed stream, doubled on every
+	// further restart up to managedStreamMaxBackoff.
+	managedStreamBackoff    = 100 * time.Millisecond
+	managedStreamMaxBackoff = 30 * time.Second
+)
+
+// StreamHandler runs a managed stream until it ends, see StreamManager.Start. It opens its streams on cc with ctx,
+// which binds them to the connection chosen for the stream, and returns once they end or ctx is done.
+type StreamHandler func(ctx context.Context, cc grpc.ClientConnInterface) error
+
+// StreamHealth is the health of a managed stream, see StreamManager.Health.
+type StreamHealth struct {
+	Name string
+
+	// Running reports whether the handler is running, false while waiting to restart it.
+	Running bool
+
+	// Conn is the index of the connection of the current or last run.
+	Conn int
+
+	// Since is when the current run started, or when the last one ended while waiting to restart.
+	Since time.Time
+
+	// Restarts is the number of times the handler was restarted.
+	Restarts int
+
+	// LastError is the error the last run ended with, nil if it returned nil.
+	LastError error
+}
+
+// StreamManager owns named long-lived streams on a pool, e.g. config watches and event feeds. It spreads them
+// over the connections of the pool, restarts them with backoff when they end, and exposes their health.
+type StreamManager struct {
+	pool *Pool
+
+	mu      sync.Mutex
+	streams map[string]*managedStream
+}
+
+type managedStream struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu     sync.Mutex
+	health StreamHealth
+	conn   *PoolConn // of the current run, nil while not running
+}
+
+// NewStreamManager returns a StreamManager running streams on p.
+func NewStreamManager(p *Pool) *StreamManager {
+	return &StreamManager{pool: p, streams: map[string]*managedStream{}}
+}
+
+// Start runs h under name until Stop or Close is called, restarting it whenever it returns.
+//
+// Every run is bound to one connection of the pool, as by BindSession, preferring healthy connections with the
+// fewest managed streams and, on restarts, other connections than the one of the failed run. Restarts back off
+// exponentially from 100ms to 30s, and back off from the start again after a run that lasted 30s.
+func (m *StreamManager) Start(name string, h StreamHandler) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.streams[name]; ok {
+		return fmt.Errorf("grpcpool: managed stream %s already started", name)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ms := &managedStream{cancel: cancel, done: make(chan struct{}), health: StreamHealth{Name: name, Conn: -1}}
+	m.streams[name] = ms
+	go m.run(ctx, ms, h)
+	return nil
+}
+
+// Stop stops the stream started under name and waits for its handler to return.
+func (m *StreamManager) Stop(name string) {
+	m.mu.Lock()
+	ms, ok := m.streams[name]
+	delete(m.streams, name)
+	m.mu.Unlock()
+	if ok {
+		ms.cancel()
+		<-ms.done
+	}
+}
+
+// Close stops every stream of m.
+func (m *StreamManager) Close() {
+	m.mu.Lock()
+	streams := m.streams
+	m.streams = map[string]*managedStream{}
+	m.mu.Unlock()
+	for _, ms := range streams {
+		ms.cancel()
+	}
+	for _, ms := range streams {
+		<-ms.done
+	}
+}
+
+// Health returns the health of every stream of m, sorted by name.
+func (m *StreamManager) Health() []StreamHealth {
+	m.mu.Lock()
+	health := make([]StreamHealth, 0, len(m.streams))
+	for _, ms := range m.streams {
+		ms.mu.Lock()
+		health = append(health, ms.health)
+		ms.mu.Unlock()
+	}
+	m.mu.Unlock()
+	sort.Slice(health, func(i, j int) bool { return health[i].Name < health[j].Name })
+	return health
+}
+
+// run runs h until ctx is done.
+func (m *StreamManager) run(ctx context.Context, ms *managedStream, h StreamHandler) {
+	defer close(ms.done)
+	clock := m.pool.opts.clock
+	backoff := managedStreamBackoff
+	var last *PoolConn
+	for {
+		set := m.pool.acquire()
+		c := m.place(set, last)
+		sessCtx, release := m.pool.bindConn(ctx, set, c)
+		start := clock.Now()
+		ms.mu.Lock()
+		ms.conn = c
+		ms.health.Running, ms.health.Conn, ms.health.Since = true, c.index, start
+		ms.mu.Unlock()
+
+		err := h(sessCtx, m.pool)
+		release()
+		end := clock.Now()
+		ms.mu.Lock()
+		ms.conn = nil
+		ms.health.Running, ms.health.Since, ms.health.LastError = false, end, err
+		ms.mu.Unlock()
+		if ctx.Err() != nil {
+			return
+		}
+
+		if end.Sub(start) >= managedStreamMaxBackoff {
+			backoff = managedStreamBackoff
+		}
+		t := clock.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C():
+		}
+		if backoff *= 2; backoff > managedStreamMaxBackoff {
+			backoff = managedStreamMaxBackoff
+		}
+		last = c
+		ms.mu.Lock()
+		ms.health.Restarts++
+		ms.mu.Unlock()
+	}
+}
+
+// place returns the connection of s for the next run of a stream whose last run was on last, nil on the first run.
+func (m *StreamManager) place(s *connSet, last *PoolConn) *PoolConn {
+	placed := map[*PoolConn]int{}
+	m.mu.Lock()
+	for _, ms := range m.streams {
+		ms.mu.Lock()
+		if ms.conn != nil {
+			placed[ms.conn]++
+		}
+		ms.mu.Unlock()
+	}
+	m.mu.Unlock()
+
+	var best *PoolConn
+	rank := func(c *PoolConn) [3]int {
+		// Lower is better: unhealthy, then the failed connection, then by the streams placed on it.
+		r := [3]int{0, 0, placed[c]}
+		if !c.Healthy() {
+			r[0] = 1
+		}
+		if c == last {
+			r[1] = 1
+		}
+		return r
+	}
+	for _, c := range s.conns {
+		if best == nil || rankLess(rank(c), rank(best)) {
+			best = c
+		}
+	}
+	return best
+}
+
+func rankLess(a, b [3]int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// bindConn binds a session to c of s, acquired by the caller, as BindSession does with the connection it picks.
+func (p *Pool) bindConn(ctx context.Context, s *connSet, c *PoolConn) (context.Context, ReleaseFunc) {
+	sess := &session{pool: p, set: s, conn: c}
+	p.sessions.Add(1)
+	return context.WithValue(ctx, sessionKey{}, sess), sess.release
+}
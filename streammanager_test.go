@@ -0,0 +1,107 @@
+package grpcpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestStreamManager(t *testing.T) {
+	l := echoStreamServer(t)
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(2),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	m := NewStreamManager(pool)
+	defer m.Close()
+	watch := func(ctx context.Context, cc grpc.ClientConnInterface) error {
+		cs, err := cc.NewStream(ctx, &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}, "/test.Echo/Echo")
+		if err != nil {
+			return err
+		}
+		return cs.RecvMsg(&emptypb.Empty{})
+	}
+	for _, name := range []string{"a", "b"} {
+		if err := m.Start(name, watch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Start("a", watch); err == nil {
+		t.Error("Start() of a started stream succeeded")
+	}
+
+	waitStreams := func(want int) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); pool.ActiveStreams() != want; time.Sleep(time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("got %d active streams; want %d", pool.ActiveStreams(), want)
+			}
+		}
+	}
+	waitStreams(2)
+	health := m.Health()
+	if len(health) != 2 || !health[0].Running || !health[1].Running || health[0].Conn == health[1].Conn {
+		t.Errorf("Health() got %+v; want a and b running on different conns", health)
+	}
+
+	m.Stop("a")
+	waitStreams(1)
+	if health := m.Health(); len(health) != 1 || health[0].Name != "b" {
+		t.Errorf("Health() after Stop got %+v; want only b", health)
+	}
+}
+
+func TestStreamManagerRestart(t *testing.T) {
+	_, l := mockServer(t)
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(2),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	m := NewStreamManager(pool)
+	defer m.Close()
+	errBroken := errors.New("broken")
+	var runs atomic.Int64
+	conns := make(chan *PoolConn, 3)
+	m.Start("feed", func(ctx context.Context, _ grpc.ClientConnInterface) error {
+		c, _ := pool.SessionConn(ctx)
+		conns <- c
+		if runs.Add(1) < 3 {
+			return errBroken
+		}
+		<-ctx.Done()
+		return nil
+	})
+
+	var got []*PoolConn
+	for i := 0; i < 3; i++ {
+		select {
+		case c := <-conns:
+			got = append(got, c)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("run %d never started", i+1)
+		}
+	}
+	if got[0] == got[1] || got[1] == got[2] {
+		t.Error("restarted on the conn of the failed run")
+	}
+	health := m.Health()[0]
+	if !health.Running || health.Restarts != 2 || !errors.Is(health.LastError, errBroken) {
+		t.Errorf("Health() got %+v; want running after 2 restarts, last error %v", health, errBroken)
+	}
+}
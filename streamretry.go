@@ -0,0 +1,164 @@
+package grpcpool
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// WithStreamRetry retries streams on another connection, up to attempts times, when they fail before their first
+// message was sent, which is always safe as the server saw no request.
+//
+// A stream is retried when NewStream fails with Unavailable, e.g. at transport setup, when RecvMsg fails with
+// Unavailable before any message was sent or received, and when the first SendMsg fails because the connection
+// broke. gRPC's own transparent retry only retries on the same connection, which doesn't help once it is down.
+func WithStreamRetry(attempts int) Option {
+	return func(o *options) {
+		o.streamRetry = attempts
+	}
+}
+
+// other returns another connection in the group of c, a healthy one if possible, or c if it is the only one.
+func (s *connSet) other(c *PoolConn) *PoolConn {
+	alt := c
+	for _, o := range s.groups[c.group].conns {
+		if o == c {
+			continue
+		}
+		if o.Healthy() {
+			return o
+		}
+		alt = o
+	}
+	return alt
+}
+
+// retryStream is a stream retried on another connection while its first message wasn't sent, see WithStreamRetry.
+type retryStream struct {
+	pool   *Pool
+	ctx    context.Context
+	desc   *grpc.StreamDesc
+	method string
+	opts   []grpc.CallOption
+
+	mu       sync.Mutex  // serializes retries
+	attempts int         // retries left
+	started  atomic.Bool // a message was sent or received
+	cs       atomic.Pointer[streamCall]
+}
+
+func (p *Pool) newRetryStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts []grpc.CallOption) (grpc.ClientStream, error) {
+	rs := &retryStream{pool: p, ctx: ctx, desc: desc, method: method, opts: opts, attempts: p.opts.streamRetry}
+	sc, err := p.newStream(ctx, desc, method, opts, nil)
+	for err != nil && sc != nil && rs.retryable(err) {
+		rs.attempts--
+		sc, err = p.newStream(ctx, desc, method, opts, sc.conn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	rs.cs.Store(sc)
+	return rs, nil
+}
+
+// retryable reports whether a stream failing with err before its first message is retried.
+func (rs *retryStream) retryable(err error) bool {
+	return rs.attempts > 0 && status.Code(err) == codes.Unavailable && rs.ctx.Err() == nil
+}
+
+// retry replaces the failed stream sc with one on another connection. rs.mu must be held.
+func (rs *retryStream) retry(sc *streamCall, err error) (*streamCall, error) {
+	sc.release(err)
+	for { // sc is the last failed stream
+		rs.attempts--
+		next, nerr := rs.pool.newStream(rs.ctx, rs.desc, rs.method, rs.opts, sc.conn)
+		if nerr == nil {
+			rs.cs.Store(next)
+			return next, nil
+		}
+		if next == nil || !rs.retryable(nerr) {
+			return nil, nerr
+		}
+		sc = next
+	}
+}
+
+func (rs *retryStream) Header() (metadata.MD, error) {
+	return rs.cs.Load().Header()
+}
+
+func (rs *retryStream) Trailer() metadata.MD {
+	return rs.cs.Load().Trailer()
+}
+
+func (rs *retryStream) CloseSend() error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return rs.cs.Load().CloseSend()
+}
+
+func (rs *retryStream) Context() context.Context {
+	return rs.cs.Load().Context()
+}
+
+func (rs *retryStream) SendMsg(m interface{}) error {
+	if rs.started.Load() {
+		return rs.cs.Load().SendMsg(m)
+	}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	for {
+		sc := rs.cs.Load()
+		err := sc.SendMsg(m)
+		if err == nil {
+			rs.started.Store(true)
+			return nil
+		}
+		// io.EOF means the stream failed; it was the connection if it isn't ready anymore.
+		if err != io.EOF || sc.conn.cc.GetState() == connectivity.Ready {
+			return err
+		}
+		unavailable := status.Error(codes.Unavailable, "grpcpool: connection failed before the first message")
+		if !rs.retryable(unavailable) {
+			return err
+		}
+		if _, err := rs.retry(sc, unavailable); err != nil {
+			return err
+		}
+	}
+}
+
+func (rs *retryStream) RecvMsg(m interface{}) error {
+	for {
+		sc := rs.cs.Load()
+		err := sc.RecvMsg(m)
+		if err == nil {
+			rs.started.Store(true)
+			return nil
+		}
+		if rs.started.Load() {
+			return err
+		}
+		rs.mu.Lock()
+		if rs.cs.Load() != sc {
+			rs.mu.Unlock() // retried by SendMsg meanwhile
+			continue
+		}
+		if rs.started.Load() || !rs.retryable(err) {
+			rs.mu.Unlock()
+			return err
+		}
+		_, rerr := rs.retry(sc, err)
+		rs.mu.Unlock()
+		if rerr != nil {
+			return rerr
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package grpcpool
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// streamServer starts a server running handler for every stream.
+func streamServer(t *testing.T, handler grpc.StreamHandler) net.Listener {
+	t.Helper()
+	s := grpc.NewServer(grpc.UnknownServiceHandler(handler))
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+	return l
+}
+
+func TestStreamRetryNewStream(t *testing.T) {
+	l := echoStreamServer(t)
+	desc := &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}
+
+	for _, attempts := range []int{0, 1} {
+		pool, err := NewEndpointPool(context.Background(), []Endpoint{{Addr: "localhost:1"}, {Addr: l.Addr().String()}},
+			WithStreamRetry(attempts),
+			WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		waitForState(t, pool.Conns()[0].ClientConn(), connectivity.TransientFailure)
+
+		failed := 0
+		for i := 0; i < 4; i++ {
+			cs, err := pool.NewStream(context.Background(), desc, "/test.Echo/Echo")
+			if err != nil {
+				if status.Code(err) != codes.Unavailable {
+					t.Errorf("NewStream() got %v; want Unavailable", err)
+				}
+				failed++
+				continue
+			}
+			if err := cs.SendMsg(&emptypb.Empty{}); err != nil {
+				t.Errorf("SendMsg() got %v", err)
+			}
+			if err := cs.RecvMsg(&emptypb.Empty{}); err != nil {
+				t.Errorf("RecvMsg() got %v", err)
+			}
+			cs.CloseSend()
+		}
+		if attempts == 0 && failed != 2 {
+			t.Errorf("without retries %d streams failed; want the 2 on the dead conn", failed)
+		}
+		if attempts > 0 && failed != 0 {
+			t.Errorf("with retries %d streams failed; want none", failed)
+		}
+		pool.Close()
+	}
+}
+
+func TestStreamRetryRecvBeforeSend(t *testing.T) {
+	flaky := streamServer(t, func(interface{}, grpc.ServerStream) error {
+		return status.Error(codes.Unavailable, "overloaded")
+	})
+	healthy := streamServer(t, func(_ interface{}, stream grpc.ServerStream) error {
+		return stream.SendMsg(&emptypb.Empty{})
+	})
+
+	pool, err := NewEndpointPool(context.Background(), []Endpoint{{Addr: flaky.Addr().String()}, {Addr: healthy.Addr().String()}},
+		WithStreamRetry(1),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for i := 0; i < 2; i++ {
+		cs, err := pool.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}, "/test.Feed/Feed")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cs.RecvMsg(&emptypb.Empty{}); err != nil {
+			t.Errorf("stream %d RecvMsg() got %v; want the message of the healthy server", i, err)
+		}
+		if err := cs.RecvMsg(&emptypb.Empty{}); err != io.EOF {
+			t.Errorf("stream %d RecvMsg() got %v; want io.EOF", i, err)
+		}
+	}
+	for _, c := range pool.Conns() {
+		if got := c.InFlight(); got != 0 {
+			t.Errorf("conn %d has %d in flight after the streams ended", c.Index(), got)
+		}
+	}
+}
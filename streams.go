@@ -0,0 +1,15 @@
+package grpcpool
+
+// StreamGroup is the group of the connections reserved for streams, see WithStreamConns.
+const StreamGroup = "stream"
+
+// WithStreamConns reserves n connections of the pool exclusively for NewStream.
+//
+// Long-lived streams then don't consume the HTTP/2 stream slots and bandwidth of the connections serving
+// latency-sensitive unary calls, which use the remaining connections.
+func WithStreamConns(n int) Option {
+	return func(o *options) {
+		o.streamConns = true
+		o.addGroup(groupSpec{name: StreamGroup, conns: n})
+	}
+}
@@ -0,0 +1,135 @@
+package grpcpool
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestStreamConns(t *testing.T) {
+	l := healthTestServer(t, "", healthpb.HealthCheckResponse_SERVING)
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(3),
+		WithStreamConns(1),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	streamConn := pool.Conns()[2]
+	if streamConn.Group() != StreamGroup {
+		t.Fatalf("last conn group got %q; want %q", streamConn.Group(), StreamGroup)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := healthpb.NewHealthClient(pool)
+	for i := 0; i < 4; i++ {
+		watch, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := watch.Recv(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := streamConn.InFlight(); got != 4 {
+		t.Errorf("stream conn has %d open streams; want 4", got)
+	}
+	if got := streamConn.calls.Load(); got != 0 {
+		t.Errorf("stream conn got %d unary calls; want 0", got)
+	}
+	if got := pool.GroupStats("").Calls; got != 4 {
+		t.Errorf("unary conns got %d calls; want 4", got)
+	}
+}
+
+func TestStreamConnsInvalid(t *testing.T) {
+	_, err := NewPool(context.Background(), "localhost:1",
+		WithSize(2),
+		WithStreamConns(2),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err == nil {
+		t.Fatal("NewPool reserving every conn for streams succeeded")
+	}
+}
+
+func TestActiveStreams(t *testing.T) {
+	s := grpc.NewServer(grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+		method, _ := grpc.MethodFromServerStream(stream)
+		if strings.HasSuffix(method, "/Block") {
+			<-stream.Context().Done()
+			return nil
+		}
+		return stream.SendMsg(&emptypb.Empty{})
+	}))
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(l)
+	defer s.Stop()
+
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	conn := pool.Conns()[0]
+
+	desc := &grpc.StreamDesc{ServerStreams: true}
+	cs, err := pool.NewStream(context.Background(), desc, "/test.Service/Send")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	if got := conn.ActiveStreams(); got != 1 {
+		t.Errorf("ActiveStreams() after NewStream got %d; want 1", got)
+	}
+	if err := cs.RecvMsg(&emptypb.Empty{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.RecvMsg(&emptypb.Empty{}); err != io.EOF {
+		t.Fatalf("RecvMsg() got %v; want io.EOF", err)
+	}
+	if got := conn.ActiveStreams(); got != 0 {
+		t.Errorf("ActiveStreams() after io.EOF got %d; want 0", got)
+	}
+	if got := conn.InFlight(); got != 0 {
+		t.Errorf("InFlight() after io.EOF got %d; want 0", got)
+	}
+
+	// Streams abandoned by canceling their context end too.
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := pool.NewStream(ctx, desc, "/test.Service/Block"); err != nil {
+		t.Fatal(err)
+	}
+	if got := pool.ActiveStreams(); got != 1 {
+		t.Errorf("pool ActiveStreams() got %d; want 1", got)
+	}
+	cancel()
+	for deadline := time.Now().Add(5 * time.Second); pool.ActiveStreams() != 0; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("canceled stream still active")
+		}
+	}
+}
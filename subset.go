@@ -0,0 +1,53 @@
+package grpcpool
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// Endpoint is a backend the pool can dial.
+type Endpoint struct {
+	// Addr is the target passed to grpc.DialContext.
+	Addr string
+
+	// Zone is the locality of the endpoint, see WithLocality.
+	Zone string
+
+	// Priority is the failover tier of the endpoint, see WithPriorityFailover. Lower values are preferred.
+	Priority int
+
+	// Authority is the :authority of the calls to the endpoint, and the server name its certificate is verified
+	// against. Empty uses the gRPC default derived from Addr. Dials fail with transport credentials for another
+	// server name. See WithAuthorityFunc.
+	Authority string
+}
+
+// Subset returns a deterministic subset of size endpoints for the client with clientIndex.
+//
+// It implements the deterministic subsetting algorithm from the Google SRE book: clients are grouped into rounds,
+// every round shuffles the endpoints with the same seed and each client in a round takes a distinct slice of the
+// shuffled endpoints. Client indexes must be consecutive, e.g. the ordinals of a StatefulSet or task numbers: then
+// every full round connects each endpoint to the same number of clients, so the aggregate load over all endpoints
+// is balanced, while every client only holds connections to size endpoints.
+//
+// The result only depends on clientIndex, size and the set of endpoint addresses, not on the order of endpoints.
+// If size is not smaller than the number of endpoints, all endpoints are returned.
+func Subset(endpoints []Endpoint, clientIndex uint, size int) []Endpoint {
+	sorted := make([]Endpoint, len(endpoints))
+	copy(sorted, endpoints)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Addr < sorted[j].Addr
+	})
+	if size <= 0 || size >= len(sorted) {
+		return sorted
+	}
+
+	subsetCount := uint(len(sorted) / size)
+	round := clientIndex / subsetCount
+	rand.New(rand.NewSource(int64(round))).Shuffle(len(sorted), func(i, j int) {
+		sorted[i], sorted[j] = sorted[j], sorted[i]
+	})
+
+	start := int(clientIndex%subsetCount) * size
+	return sorted[start : start+size]
+}
@@ -0,0 +1,91 @@
+package grpcpool
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func testEndpoints(n int) []Endpoint {
+	endpoints := make([]Endpoint, n)
+	for i := range endpoints {
+		endpoints[i] = Endpoint{Addr: fmt.Sprintf("10.0.0.%d:443", i)}
+	}
+	return endpoints
+}
+
+func TestSubsetDeterministic(t *testing.T) {
+	endpoints := testEndpoints(100)
+	reversed := make([]Endpoint, len(endpoints))
+	for i, e := range endpoints {
+		reversed[len(endpoints)-1-i] = e
+	}
+
+	a := Subset(endpoints, 1, 10)
+	b := Subset(reversed, 1, 10)
+	if len(a) != 10 {
+		t.Fatalf("len(Subset) got %d; want 10", len(a))
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("Subset depends on endpoint order: %v != %v", a, b)
+		}
+	}
+}
+
+func TestSubsetBalanced(t *testing.T) {
+	endpoints := testEndpoints(30)
+	counts := map[string]int{}
+	for c := uint(0); c < 300; c++ {
+		subset := Subset(endpoints, c, 5)
+		seen := map[string]bool{}
+		for _, e := range subset {
+			if seen[e.Addr] {
+				t.Fatalf("Subset returned %s twice", e.Addr)
+			}
+			seen[e.Addr] = true
+			counts[e.Addr]++
+		}
+	}
+	// 300 consecutive clients make 50 full rounds of 6 clients, each connecting every endpoint once.
+	for addr, n := range counts {
+		if n != 50 {
+			t.Errorf("endpoint %s got %d clients; want 50", addr, n)
+		}
+	}
+}
+
+func TestSubsetAll(t *testing.T) {
+	endpoints := testEndpoints(3)
+	if got := Subset(endpoints, 0, 5); len(got) != 3 {
+		t.Errorf("len(Subset) got %d; want 3", len(got))
+	}
+}
+
+func TestNewEndpointPoolSubset(t *testing.T) {
+	_, l := mockServer(t)
+	endpoints := []Endpoint{{Addr: l.Addr().String()}}
+	endpoints = append(endpoints, testEndpoints(9)...)
+
+	pool, err := NewEndpointPool(context.Background(), endpoints,
+		WithSubset(2, 3),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	if pool.Num() != 3 {
+		t.Errorf("pool.Num() got %d; want 3", pool.Num())
+	}
+	want := Subset(endpoints, 2, 3)
+	for i, e := range pool.Endpoints() {
+		if e != want[i] {
+			t.Errorf("pool.Endpoints()[%d] got %v; want %v", i, e, want[i])
+		}
+	}
+}
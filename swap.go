@@ -0,0 +1,206 @@
+package grpcpool
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// drainInterval is how often a drained connSet is checked for remaining calls.
+const drainInterval = 10 * time.Millisecond
+
+// SwapTarget migrates the pool to newTarget without dropping in-flight RPCs.
+//
+// It dials the same number of connections to newTarget, using the pool's dial options, and waits for all of them
+// to become ready. Once they are, new calls are sent to the new connections, and the old connections are closed
+// after their in-flight unary calls and open streams finish.
+//
+// If ctx is done before the new connections are ready, they are closed and the pool keeps using the old ones.
+// If ctx is done while the old connections drain, they are closed anyway and ctx.Err() is returned;
+// the swap itself has already taken effect.
+func (p *Pool) SwapTarget(ctx context.Context, newTarget string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := p.replace(ctx, []Endpoint{{Addr: newTarget}})
+	return err
+}
+
+// Recycle replaces every connection of the pool with a new one to the same endpoint, without dropping in-flight
+// RPCs, e.g. so new TLS handshakes pick up rotated certificates.
+//
+// The new connections are dialed, made ready and swapped in the same way as by SwapTarget.
+func (p *Pool) Recycle(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := p.replace(ctx, p.set.Load().endpoints())
+	return err
+}
+
+// endpoints returns the endpoint of every connection of s.
+func (s *connSet) endpoints() []Endpoint {
+	endpoints := make([]Endpoint, len(s.conns))
+	for i, c := range s.conns {
+		endpoints[i] = c.endpoint
+	}
+	return endpoints
+}
+
+// replace dials as many connections to endpoints as the pool has, waits for them to become ready, swaps them in
+// and drains the old ones. It reports whether the new connections were swapped in. p.mu must be held.
+func (p *Pool) replace(ctx context.Context, endpoints []Endpoint) (bool, error) {
+	old := p.set.Load()
+	s, err := p.dialSize(ctx, endpoints, len(old.conns))
+	if err != nil {
+		return false, err
+	}
+	if err := s.waitReady(ctx); err != nil {
+		s.close()
+		return false, err
+	}
+
+	p.set.Store(s)
+	err = old.drain(ctx, p.opts.clock)
+	if cerr := old.close(); err == nil {
+		err = cerr
+	}
+	return true, err
+}
+
+// waitReady blocks until every connection in s is ready or ctx is done.
+func (s *connSet) waitReady(ctx context.Context) error {
+	for _, c := range s.conns {
+		if err := waitReady(ctx, c.cc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitReady blocks until cc is ready or ctx is done.
+func waitReady(ctx context.Context, cc *grpc.ClientConn) error {
+	cc.Connect()
+	for {
+		state := cc.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		if !cc.WaitForStateChange(ctx, state) {
+			return ctx.Err()
+		}
+	}
+}
+
+// drain blocks until s, and the sets it was grown from, have no in-flight calls or open streams, or ctx is done.
+func (s *connSet) drain(ctx context.Context, clock Clock) error {
+	if s.activeCalls() == 0 {
+		return nil
+	}
+	ticker := clock.NewTicker(drainInterval)
+	defer ticker.Stop()
+	for s.activeCalls() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+		}
+	}
+	return nil
+}
+
+// activeCalls returns the in-flight calls and open streams of s and the sets it was grown from. Sets without any,
+// and so the sets they were grown from too, are unlinked so they can be collected.
+func (s *connSet) activeCalls() int64 {
+	n := s.active.Load()
+	if prev := s.prev.Load(); prev != nil {
+		if m := prev.activeCalls(); m > 0 {
+			n += m
+		} else {
+			s.prev.CompareAndSwap(prev, nil)
+		}
+	}
+	return n
+}
+
+// storeGrown makes s, which keeps some of the conns of old, the current set of p. p.mu must be held.
+//
+// If p was closed meanwhile, the conns of s not in old are closed instead, as Close only closes the current set,
+// and errPoolClosed is returned.
+func (p *Pool) storeGrown(old, s *connSet) error {
+	if p.closed.Load() {
+		for _, c := range s.conns {
+			if !containsConn(old.conns, c) {
+				c.close()
+			}
+		}
+		return errPoolClosed
+	}
+	old.activeCalls() // unlinks the drained sets old was grown from
+	s.prev.Store(old) // still counts calls on the conns kept
+	p.set.Store(s)
+	if old.stopWatch != nil {
+		old.stopWatch() // s watches the conns kept
+	}
+	return nil
+}
+
+// redial replaces the connections at indexes of the current set with new ones, and closes the old ones once
+// their calls and streams finish or ctx is done. If ready, the new connections are swapped in once ready, else
+// right away. p.mu must be held.
+func (p *Pool) redial(ctx context.Context, indexes []int, ready bool) error {
+	old := p.set.Load()
+	conns := make([]*PoolConn, len(old.conns))
+	copy(conns, old.conns)
+	return p.redialAt(ctx, old, conns, indexes, ready, nil)
+}
+
+// redialAt replaces the connections at indexes of conns, the connections of the next set of p after old, with new
+// ones at the same index, swaps in the set, and closes the replaced connections and the removed ones once their
+// calls and streams finish or ctx is done. If ready, the set is swapped in once the new connections are ready, else
+// right away. p.mu must be held.
+func (p *Pool) redialAt(ctx context.Context, old *connSet, conns []*PoolConn, indexes []int, ready bool, removed []*PoolConn) error {
+	if p.closed.Load() {
+		return errPoolClosed
+	}
+	dialed := make([]*PoolConn, 0, len(indexes))
+	replaced := removed
+	for _, i := range indexes {
+		prev := conns[i]
+		c := &PoolConn{endpoint: prev.endpoint, index: i, group: prev.group, replacedExpiry: prev.certExpiry.Load()}
+		if err := p.newConn(ctx, c); err != nil {
+			(&connSet{conns: dialed}).close()
+			return err
+		}
+		dialed = append(dialed, c)
+		if !ready {
+			c.cc.Connect()
+		} else if err := waitReady(ctx, c.cc); err != nil {
+			(&connSet{conns: dialed}).close()
+			return err
+		}
+		conns[i] = c
+		replaced = append(replaced, prev)
+	}
+
+	if err := p.storeGrown(old, p.newConnSet(conns)); err != nil {
+		return err
+	}
+	return p.closeDrained(ctx, replaced)
+}
+
+// closeDrained closes conns, no longer in the current set, once their calls and streams finish or ctx is done.
+func (p *Pool) closeDrained(ctx context.Context, conns []*PoolConn) error {
+	ticker := p.opts.clock.NewTicker(drainInterval)
+	defer ticker.Stop()
+	for _, c := range conns {
+		for c.inflight.Load() > 0 && ctx.Err() == nil {
+			select {
+			case <-ctx.Done():
+			case <-ticker.C():
+			}
+		}
+		c.close()
+	}
+	return ctx.Err()
+}
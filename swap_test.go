@@ -0,0 +1,112 @@
+package grpcpool
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthTestServer starts a server exposing the health service with service reporting status.
+func healthTestServer(t *testing.T, service string, status healthpb.HealthCheckResponse_ServingStatus) net.Listener {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	hs := health.NewServer()
+	hs.SetServingStatus(service, status)
+	healthpb.RegisterHealthServer(s, hs)
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+
+	return l
+}
+
+func TestSwapTarget(t *testing.T) {
+	oldL := healthTestServer(t, "svc", healthpb.HealthCheckResponse_SERVING)
+	newL := healthTestServer(t, "svc", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pool, err := NewPool(ctx, oldL.Addr().String(),
+		WithSize(2),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	client := healthpb.NewHealthClient(pool)
+	streamCtx, cancelStream := context.WithCancel(ctx)
+	watch, err := client.Watch(streamCtx, &healthpb.HealthCheckRequest{Service: "svc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := watch.Recv(); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- pool.SwapTarget(ctx, newL.Addr().String())
+	}()
+
+	// New calls move to the new target while the watch keeps the old conns alive.
+	for {
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "svc"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Status == healthpb.HealthCheckResponse_NOT_SERVING {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("SwapTarget returned %v before the watch finished", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancelStream()
+	if err := <-done; err != nil {
+		t.Fatalf("SwapTarget: %v", err)
+	}
+	if pool.Num() != 2 {
+		t.Errorf("pool.Num() got %d; want 2", pool.Num())
+	}
+	if got := pool.Endpoints()[0].Addr; got != newL.Addr().String() {
+		t.Errorf("pool.Endpoints()[0] got %s; want %s", got, newL.Addr())
+	}
+}
+
+func TestSwapTargetNotReady(t *testing.T) {
+	oldL := healthTestServer(t, "svc", healthpb.HealthCheckResponse_SERVING)
+
+	pool, err := NewPool(context.Background(), oldL.Addr().String(),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := pool.SwapTarget(ctx, "localhost:1"); err == nil {
+		t.Fatal("SwapTarget to an unreachable target succeeded")
+	}
+	if got := pool.Endpoints()[0].Addr; got != oldL.Addr().String() {
+		t.Errorf("pool.Endpoints()[0] got %s; want %s", got, oldL.Addr())
+	}
+}
@@ -0,0 +1,265 @@
+package grpcpool
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"google.golang.org/grpc"
+)
+
+// TenantConfig configures a TenantPool.
+type TenantConfig struct {
+	// Tenant returns the tenant of a call. Calls without a tenant share the sub-pool of the empty tenant.
+	Tenant func(ctx context.Context) (tenant string, ok bool)
+
+	// ConnsPerTenant is the number of connections in every tenant's sub-pool. Zero means one.
+	ConnsPerTenant uint
+
+	// MaxTenants is the number of sub-pools kept open. When a new tenant exceeds it, the least recently used idle
+	// sub-pools are closed. Zero means no limit.
+	MaxTenants int
+
+	// IdleTimeout closes sub-pools that had no calls for this long, checked every IdleTimeout on the Clock of the
+	// pool. Zero disables idle eviction.
+	IdleTimeout time.Duration
+}
+
+var _ ConnPool = &TenantPool{}
+
+// TenantPool is a ConnPool partitioned into isolated sub-pools per tenant, so one tenant's load or failures cannot
+// affect others through shared HTTP/2 connections.
+//
+// Sub-pools are dialed on a tenant's first call, without holding up the calls of other tenants, and evicted in
+// least recently used order.
+type TenantPool struct {
+	target string
+	cfg    TenantConfig
+	opts   []Option
+	clock  Clock
+
+	mu      sync.Mutex
+	tenants map[string]*list.Element // of *tenantPool
+	lru     *list.List               // most recently used first
+	closed  bool
+
+	stopEvict func() // nil without IdleTimeout
+}
+
+type tenantPool struct {
+	tenant string
+	ready  chan struct{} // closed once pool or err is set
+	pool   *Pool
+	err    error
+
+	lastUsed time.Time // guarded by TenantPool.mu
+	inflight int       // guarded by TenantPool.mu
+}
+
+var errTenantPoolClosed = errors.New("grpcpool: tenant pool is closed")
+
+// NewTenantPool creates a TenantPool dialing sub-pools to target with opts.
+func NewTenantPool(target string, cfg TenantConfig, opts ...Option) (*TenantPool, error) {
+	if cfg.Tenant == nil {
+		return nil, errors.New("grpcpool: tenant func must not be nil")
+	}
+	if cfg.ConnsPerTenant == 0 {
+		cfg.ConnsPerTenant = 1
+	}
+	opts = append(opts[:len(opts):len(opts)], WithSize(cfg.ConnsPerTenant))
+	p := &TenantPool{
+		target:  target,
+		cfg:     cfg,
+		opts:    opts,
+		clock:   newOptions(opts).clock,
+		tenants: map[string]*list.Element{},
+		lru:     list.New(),
+	}
+	if cfg.IdleTimeout > 0 {
+		p.stopEvict = p.startEvict()
+	}
+	return p, nil
+}
+
+// startEvict starts closing idle sub-pools every IdleTimeout until stop is called.
+func (p *TenantPool) startEvict() (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ticker := p.clock.NewTicker(p.cfg.IdleTimeout)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+				p.mu.Lock()
+				p.evictLocked(p.clock.Now())
+				p.mu.Unlock()
+			}
+		}
+	}()
+	return cancel
+}
+
+// Tenants returns the tenants with an open sub-pool, most recently used first.
+func (p *TenantPool) Tenants() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	tenants := make([]string, 0, p.lru.Len())
+	for e := p.lru.Front(); e != nil; e = e.Next() {
+		tenants = append(tenants, e.Value.(*tenantPool).tenant)
+	}
+	return tenants
+}
+
+// acquire returns the sub-pool of tenant with its in-flight count incremented, dialing it if needed. The first
+// call of a tenant dials its sub-pool without holding p.mu; later calls of the tenant wait for that dial.
+func (p *TenantPool) acquire(tenant string) (*tenantPool, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, errTenantPoolClosed
+	}
+	now := p.clock.Now()
+	p.evictLocked(now)
+
+	if e, ok := p.tenants[tenant]; ok {
+		tp := e.Value.(*tenantPool)
+		tp.inflight++
+		tp.lastUsed = now
+		p.lru.MoveToFront(e)
+		p.mu.Unlock()
+		<-tp.ready
+		if tp.err != nil {
+			p.release(tp)
+			return nil, tp.err
+		}
+		return tp, nil
+	}
+
+	tp := &tenantPool{tenant: tenant, ready: make(chan struct{}), lastUsed: now, inflight: 1}
+	p.tenants[tenant] = p.lru.PushFront(tp)
+	p.evictLocked(now)
+	p.mu.Unlock()
+
+	pool, err := NewPool(context.Background(), p.target, p.opts...)
+	p.mu.Lock()
+	if err == nil && p.closed {
+		pool.Close()
+		err = errTenantPoolClosed
+	}
+	if err != nil {
+		if e, ok := p.tenants[tenant]; ok && e.Value == tp {
+			p.lru.Remove(e)
+			delete(p.tenants, tenant)
+		}
+	}
+	tp.pool, tp.err = pool, err
+	p.mu.Unlock()
+	close(tp.ready)
+	if err != nil {
+		return nil, err
+	}
+	return tp, nil
+}
+
+func (p *TenantPool) release(tp *tenantPool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	tp.inflight--
+	tp.lastUsed = p.clock.Now()
+}
+
+// evictLocked closes idle sub-pools past the idle timeout or beyond the tenant limit. Sub-pools being dialed have
+// calls in flight and are kept.
+func (p *TenantPool) evictLocked(now time.Time) {
+	for e := p.lru.Back(); e != nil; {
+		prev := e.Prev()
+		tp := e.Value.(*tenantPool)
+		overLimit := p.cfg.MaxTenants > 0 && p.lru.Len() > p.cfg.MaxTenants
+		expired := p.cfg.IdleTimeout > 0 && now.Sub(tp.lastUsed) >= p.cfg.IdleTimeout
+		if tp.inflight == 0 && (overLimit || expired) {
+			p.lru.Remove(e)
+			delete(p.tenants, tp.tenant)
+			tp.pool.Close()
+		}
+		e = prev
+	}
+}
+
+func (p *TenantPool) tenant(ctx context.Context) string {
+	tenant, _ := p.cfg.Tenant(ctx)
+	return tenant
+}
+
+// Conn returns a ClientConn from the sub-pool of the empty tenant.
+func (p *TenantPool) Conn() *grpc.ClientConn {
+	tp, err := p.acquire("")
+	if err != nil {
+		return nil
+	}
+	defer p.release(tp)
+	return tp.pool.Conn()
+}
+
+// Num returns the number of connections in all open sub-pools.
+func (p *TenantPool) Num() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lru.Len() * int(p.cfg.ConnsPerTenant)
+}
+
+// Close closes every sub-pool. Sub-pools still being dialed are closed once dialed.
+func (p *TenantPool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	if p.stopEvict != nil {
+		p.stopEvict()
+	}
+	var errs error
+	for e := p.lru.Front(); e != nil; e = e.Next() {
+		tp := e.Value.(*tenantPool)
+		if tp.pool == nil {
+			continue // closed by acquire once dialed
+		}
+		if err := tp.pool.Close(); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	p.lru.Init()
+	p.tenants = map[string]*list.Element{}
+	return errs
+}
+
+func (p *TenantPool) Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error {
+	tp, err := p.acquire(p.tenant(ctx))
+	if err != nil {
+		return err
+	}
+	defer p.release(tp)
+	return tp.pool.Invoke(ctx, method, args, reply, opts...)
+}
+
+func (p *TenantPool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	tp, err := p.acquire(p.tenant(ctx))
+	if err != nil {
+		return nil, err
+	}
+	var once sync.Once
+	release := func(error) {
+		once.Do(func() { p.release(tp) })
+	}
+	opts = append(opts[:len(opts):len(opts)], grpc.OnFinish(release))
+	cs, err := tp.pool.NewStream(ctx, desc, method, opts...)
+	if err != nil {
+		release(err)
+	}
+	return cs, err
+}
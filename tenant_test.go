@@ -0,0 +1,169 @@
+package grpcpool
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestTenantPool(t *testing.T) {
+	l := healthTestServer(t, "", healthpb.HealthCheckResponse_SERVING)
+	pool, err := NewTenantPool(l.Addr().String(), TenantConfig{
+		Tenant: func(ctx context.Context) (string, bool) {
+			tenant, ok := ctx.Value(tenantKey{}).(string)
+			return tenant, ok
+		},
+		ConnsPerTenant: 2,
+		MaxTenants:     2,
+	}, WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	client := healthpb.NewHealthClient(pool)
+	check := func(tenant string) {
+		t.Helper()
+		ctx := context.WithValue(context.Background(), tenantKey{}, tenant)
+		if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	check("a")
+	check("b")
+	check("a")
+	if got := pool.Tenants(); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("pool.Tenants() got %v; want [a b]", got)
+	}
+	if pool.Num() != 4 {
+		t.Errorf("pool.Num() got %d; want 4", pool.Num())
+	}
+
+	// c evicts the least recently used tenant b.
+	check("c")
+	if got := pool.Tenants(); len(got) != 2 || got[0] != "c" || got[1] != "a" {
+		t.Errorf("pool.Tenants() got %v; want [c a]", got)
+	}
+}
+
+func TestTenantPoolIdleTimeout(t *testing.T) {
+	_, l := mockServer(t)
+	pool, err := NewTenantPool(l.Addr().String(), TenantConfig{
+		Tenant: func(ctx context.Context) (string, bool) {
+			tenant, ok := ctx.Value(tenantKey{}).(string)
+			return tenant, ok
+		},
+		IdleTimeout: 10 * time.Millisecond,
+	}, WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	if pool.Conn() == nil {
+		t.Fatal("pool.Conn() got nil")
+	}
+	time.Sleep(20 * time.Millisecond)
+	tp, err := pool.acquire("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.release(tp)
+	if got := pool.Tenants(); len(got) != 1 || got[0] != "a" {
+		t.Errorf("pool.Tenants() got %v; want [a]", got)
+	}
+}
+
+func TestTenantPoolSlowDial(t *testing.T) {
+	_, l := mockServer(t)
+	release := make(chan struct{})
+	var dials atomic.Int64
+	pool, err := NewTenantPool(l.Addr().String(), TenantConfig{
+		Tenant: func(ctx context.Context) (string, bool) {
+			tenant, ok := ctx.Value(tenantKey{}).(string)
+			return tenant, ok
+		},
+	}, WithDialOptions(
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			if dials.Add(1) == 1 { // only the first tenant's dial is slow
+				<-release
+			}
+			return (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+		}),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	slow := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			tp, err := pool.acquire("slow")
+			if err == nil {
+				pool.release(tp)
+			}
+			slow <- err
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	fast := make(chan error, 1)
+	go func() {
+		tp, err := pool.acquire("fast")
+		if err == nil {
+			pool.release(tp)
+		}
+		fast <- err
+	}()
+	select {
+	case err := <-fast:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("acquire of a tenant waited for the dial of another tenant")
+	}
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-slow; err != nil {
+			t.Errorf("acquire of the slowly dialed tenant got %v", err)
+		}
+	}
+	if got := pool.Tenants(); len(got) != 2 {
+		t.Errorf("pool.Tenants() got %v; want both tenants", got)
+	}
+}
+
+func TestTenantPoolIdleTicker(t *testing.T) {
+	_, l := mockServer(t)
+	clock := &tickerClock{manualClock: manualClock{now: time.Unix(0, 0)}, ticks: make(chan time.Time)}
+	pool, err := NewTenantPool(l.Addr().String(), TenantConfig{
+		Tenant:      func(ctx context.Context) (string, bool) { return "", false },
+		IdleTimeout: time.Minute,
+	}, WithClock(clock), WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	if pool.Conn() == nil {
+		t.Fatal("pool.Conn() got nil")
+	}
+	clock.advance(time.Minute)
+	clock.ticks <- clock.Now()
+	clock.ticks <- clock.Now() // the first tick was handled once the second is received
+	if got := pool.Tenants(); len(got) != 0 {
+		t.Errorf("pool.Tenants() after the idle timeout without calls got %v; want none", got)
+	}
+}
@@ -0,0 +1,189 @@
+package grpcpool
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+)
+
+// DefaultStallFraction is the stall fraction at which WithThroughputGrowth grows the pool unless configured.
+const DefaultStallFraction = 0.5
+
+// ThroughputGrowthConfig configures throughput-driven growth of the pool, see WithThroughputGrowth.
+type ThroughputGrowthConfig struct {
+	// MaxConns is the number of connections the pool grows to at most.
+	MaxConns int
+
+	// Interval is how often the throughput of the connections is checked. Zero checks every second.
+	Interval time.Duration
+
+	// StallFraction is the average fraction of an interval the ungrouped connections spend stalled sending
+	// messages at which a connection is added. Zero uses DefaultStallFraction.
+	StallFraction float64
+}
+
+// WithThroughputGrowth adds connections to the pool, up to cfg.MaxConns, when its connections are bandwidth
+// saturated.
+//
+// Large messages run into HTTP/2 flow control windows long before a connection runs out of streams: sends block
+// until the server grants window, and more streams on the same connection share the same window. The pool
+// measures the goodput of every connection and the time its calls and streams spend sending messages, and dials
+// another connection once the ungrouped connections are stalled for cfg.StallFraction of an interval on average.
+// See PoolConn.Throughput.
+//
+// Num of a growing pool increases over time. Added connections are never in a group.
+func WithThroughputGrowth(cfg ThroughputGrowthConfig) Option {
+	return func(o *options) {
+		if cfg.Interval <= 0 {
+			cfg.Interval = time.Second
+		}
+		if cfg.StallFraction <= 0 {
+			cfg.StallFraction = DefaultStallFraction
+		}
+		o.throughput = &cfg
+	}
+}
+
+// ThroughputStats are the traffic counters of a connection, see WithThroughputGrowth.
+type ThroughputStats struct {
+	// BytesSent and BytesReceived are the wire bytes of the messages sent and received.
+	BytesSent, BytesReceived int64
+
+	// Stall is the total time calls and streams spent sending messages, waiting for flow control window.
+	Stall time.Duration
+}
+
+// Throughput returns the traffic counters of c. They are only counted with WithThroughputGrowth.
+func (c *PoolConn) Throughput() ThroughputStats {
+	t := c.traffic
+	if t == nil {
+		return ThroughputStats{}
+	}
+	return ThroughputStats{
+		BytesSent:     t.sent.Load(),
+		BytesReceived: t.received.Load(),
+		Stall:         time.Duration(t.stall.Load()),
+	}
+}
+
+// connTraffic counts the traffic of a connection. It is the stats.Handler of the connection.
+type connTraffic struct {
+	sent, received atomic.Int64
+	stall          atomic.Int64 // nanoseconds
+
+	lastStall int64 // stall at the last check, guarded by Pool.mu
+}
+
+// setTraffic starts counting the traffic of c if the pool grows on throughput.
+func (o *options) setTraffic(c *PoolConn) {
+	if o.throughput != nil {
+		c.traffic = &connTraffic{}
+	}
+}
+
+// unaryKey is the context key of the *time.Time a unary call started sending at.
+type unaryKey struct{}
+
+func (t *connTraffic) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, unaryKey{}, new(time.Time))
+}
+
+func (t *connTraffic) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	switch s := s.(type) {
+	case *stats.Begin:
+		// Stream sends are timed by trafficStream.
+		if begin, ok := ctx.Value(unaryKey{}).(*time.Time); ok && !s.IsClientStream && !s.IsServerStream {
+			*begin = s.BeginTime
+		}
+	case *stats.OutPayload:
+		t.sent.Add(int64(s.WireLength))
+		if begin, ok := ctx.Value(unaryKey{}).(*time.Time); ok && !begin.IsZero() {
+			t.stall.Add(int64(s.SentTime.Sub(*begin)))
+		}
+	case *stats.InPayload:
+		t.received.Add(int64(s.WireLength))
+	}
+}
+
+func (t *connTraffic) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (t *connTraffic) HandleConn(context.Context, stats.ConnStats) {}
+
+// stream wraps the streams of a connection in a trafficStream.
+func (t *connTraffic) stream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	cs, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &trafficStream{ClientStream: cs, t: t}, nil
+}
+
+// trafficStream measures the time SendMsg blocks.
+type trafficStream struct {
+	grpc.ClientStream
+	t *connTraffic
+}
+
+func (s *trafficStream) SendMsg(m interface{}) error {
+	start := time.Now()
+	err := s.ClientStream.SendMsg(m)
+	s.t.stall.Add(int64(time.Since(start)))
+	return err
+}
+
+// startThroughputGrowth starts checking the throughput of p until stop is called.
+func (p *Pool) startThroughputGrowth() (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ticker := p.opts.clock.NewTicker(p.opts.throughput.Interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+				p.checkThroughput(p.opts.throughput.Interval)
+			}
+		}
+	}()
+	return cancel
+}
+
+// checkThroughput adds a connection if the ungrouped connections of p were stalled for the configured fraction
+// of the last interval.
+func (p *Pool) checkThroughput(interval time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cfg := p.opts.throughput
+	s := p.set.Load()
+	ungrouped := s.groups[""].conns
+	var stalled float64
+	for _, c := range ungrouped {
+		stall := c.traffic.stall.Load()
+		f := float64(stall-c.traffic.lastStall) / float64(interval)
+		c.traffic.lastStall = stall
+		if f > 1 {
+			f = 1 // concurrent sends overlap
+		}
+		stalled += f
+	}
+	if len(s.conns) < cfg.MaxConns && stalled >= cfg.StallFraction*float64(len(ungrouped)) {
+		if p.addConn(context.Background(), s) == nil {
+			p.saveSize()
+		}
+	}
+}
+
+// trafficDialOptions returns the dial options counting the traffic of c, if it is counted.
+func (c *PoolConn) trafficDialOptions() []grpc.DialOption {
+	if c.traffic == nil {
+		return nil
+	}
+	return []grpc.DialOption{grpc.WithStatsHandler(c.traffic), grpc.WithChainStreamInterceptor(c.traffic.stream)}
+}
@@ -0,0 +1,98 @@
+package grpcpool
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestThroughputStats(t *testing.T) {
+	s := grpc.NewServer(grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+		var req wrapperspb.BytesValue
+		if err := stream.RecvMsg(&req); err != nil {
+			return err
+		}
+		return stream.SendMsg(&wrapperspb.BytesValue{Value: req.Value[:1024]})
+	}))
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(l)
+	defer s.Stop()
+
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithThroughputGrowth(ThroughputGrowthConfig{MaxConns: 1}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req := &wrapperspb.BytesValue{Value: bytes.Repeat([]byte{1}, 1<<20)}
+	if err := pool.Invoke(ctx, "/test.Test/Call", req, &wrapperspb.BytesValue{}); err != nil {
+		t.Fatal(err)
+	}
+	cs, err := pool.NewStream(ctx, &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}, "/test.Test/Stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.SendMsg(req); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.RecvMsg(&wrapperspb.BytesValue{}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := pool.Conns()[0].Throughput()
+	if got.BytesSent < 2<<20 || got.BytesReceived < 2*1024 || got.Stall <= 0 {
+		t.Errorf("Throughput() got %+v; want 2 MiB sent, 2 KiB received and some stall", got)
+	}
+}
+
+func TestThroughputGrowth(t *testing.T) {
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithSize(2),
+		WithThroughputGrowth(ThroughputGrowthConfig{MaxConns: 3, Interval: time.Hour}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	interval := time.Second
+	conns := pool.Conns()
+	conns[0].traffic.stall.Add(int64(interval / 2))
+	pool.checkThroughput(interval)
+	if n := pool.Num(); n != 2 {
+		t.Fatalf("pool grew to %d conns at a stall fraction of 0.25", n)
+	}
+
+	conns[0].traffic.stall.Add(int64(2 * interval)) // capped at one interval
+	conns[1].traffic.stall.Add(int64(interval / 2))
+	pool.checkThroughput(interval)
+	if n := pool.Num(); n != 3 {
+		t.Fatalf("pool got %d conns at a stall fraction of 0.75; want 3", n)
+	}
+	if pool.Conns()[2].traffic == nil {
+		t.Error("added conn doesn't count its traffic")
+	}
+
+	for _, c := range pool.Conns() {
+		c.traffic.stall.Add(int64(interval))
+	}
+	pool.checkThroughput(interval)
+	if n := pool.Num(); n != 3 {
+		t.Fatalf("pool grew to %d conns; want at most MaxConns 3", n)
+	}
+}
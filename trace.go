@@ -0,0 +1,28 @@
+package grpcpool
+
+import (
+	"context"
+	"runtime/trace"
+)
+
+// Names of the runtime/trace tasks and regions of pool operations, so `go tool trace` shows time spent in the
+// pool, e.g. waiting for the concurrency limit or picking, apart from time spent in the RPC itself.
+const (
+	traceInvoke    = "grpcpool.Invoke"
+	traceNewStream = "grpcpool.NewStream"
+	traceAdmit     = "grpcpool.admit"
+	tracePick      = "grpcpool.pick"
+	traceRPC       = "grpcpool.rpc"
+	traceDial      = "grpcpool.dial"
+)
+
+func noopEnd() {}
+
+// traceTask starts a runtime/trace task named name if tracing is enabled, returning the function ending it.
+func traceTask(ctx context.Context, name string) (context.Context, func()) {
+	if !trace.IsEnabled() {
+		return ctx, noopEnd
+	}
+	ctx, task := trace.NewTask(ctx, name)
+	return ctx, task.End
+}
@@ -0,0 +1,43 @@
+package grpcpool
+
+import (
+	"bytes"
+	"context"
+	"runtime/trace"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestTrace(t *testing.T) {
+	_, l := mockServer(t)
+	var buf bytes.Buffer
+	if err := trace.Start(&buf); err != nil {
+		t.Skipf("tracing unavailable: %v", err)
+	}
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithDialOptions(
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithUnaryInterceptor(func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, grpc.UnaryInvoker, ...grpc.CallOption) error {
+				return nil
+			}),
+		),
+	)
+	if err != nil {
+		trace.Stop()
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	err = pool.Invoke(context.Background(), "/test.Test/Call", nil, nil)
+	trace.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{traceInvoke, traceAdmit, tracePick, traceRPC, traceDial} {
+		if !bytes.Contains(buf.Bytes(), []byte(name)) {
+			t.Errorf("trace has no %s task or region", name)
+		}
+	}
+}
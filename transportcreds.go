@@ -0,0 +1,14 @@
+package grpcpool
+
+import "google.golang.org/grpc/credentials"
+
+// WithTransportCredentialsFunc sets a function building the transport credentials of the connections to every
+// endpoint, e.g. to verify different SANs or against different roots per region.
+//
+// The credentials override those in WithDialOptions and WithCertificateRotation; connection groups dialed with their
+// own credentials, such as WithCredentialsMigration's, keep them. NewEndpointPool and SwapTarget fail if f does.
+func WithTransportCredentialsFunc(f func(e Endpoint) (credentials.TransportCredentials, error)) Option {
+	return func(o *options) {
+		o.credsFunc = f
+	}
+}
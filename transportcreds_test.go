@@ -0,0 +1,54 @@
+package grpcpool
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+func TestTransportCredentialsFunc(t *testing.T) {
+	caA, caB := newTestCA(t), newTestCA(t)
+	lA, seenA := mtlsServer(t, caA)
+	lB, seenB := mtlsServer(t, caB)
+	cas := map[string]*testCA{lA.Addr().String(): caA, lB.Addr().String(): caB}
+
+	pool, err := NewEndpointPool(context.Background(), []Endpoint{{Addr: lA.Addr().String()}, {Addr: lB.Addr().String()}},
+		WithTransportCredentialsFunc(func(e Endpoint) (credentials.TransportCredentials, error) {
+			ca := cas[e.Addr]
+			return credentials.NewTLS(&tls.Config{
+				RootCAs:      ca.pool,
+				ServerName:   "localhost",
+				Certificates: []tls.Certificate{ca.issue(t, 1, time.Now().Add(time.Hour))},
+			}), nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pool.WarmStreams(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if !seenA()[1] || !seenB()[1] {
+		t.Errorf("servers saw client certificates %v and %v; want serial 1 on both", seenA(), seenB())
+	}
+}
+
+func TestTransportCredentialsFuncError(t *testing.T) {
+	errNoCreds := errors.New("no credentials")
+	_, err := NewPool(context.Background(), "localhost:1",
+		WithTransportCredentialsFunc(func(Endpoint) (credentials.TransportCredentials, error) {
+			return nil, errNoCreds
+		}),
+	)
+	if !errors.Is(err, errNoCreds) {
+		t.Errorf("NewPool() got %v; want %v", err, errNoCreds)
+	}
+}
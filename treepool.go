@@ -0,0 +1,214 @@
+package grpcpool
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// treeLatencyWeight is the weight of a new sample in the latency average of a TreePool child.
+const treeLatencyWeight = 0.2
+
+// treeErrorPenalty is added to the latency of a call of a TreePool child failed by the child, see childFailed, so
+// children failing fast don't look fastest.
+const treeErrorPenalty = time.Second
+
+// childFailed reports whether err, of a call of a TreePool child, is a failure of the child rather than of the
+// call: only server and transport failures count, not errors caused by the request.
+func childFailed(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Internal, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
+
+// TreeChild is a child of a TreePool: a Pool, another TreePool or any ConnPool.
+type TreeChild struct {
+	Name string
+
+	// Zone is the locality of the child, compared with TreeConfig.Zone: a zone for per-zone pools, a region for
+	// per-region ones.
+	Zone string
+
+	Pool ConnPool
+}
+
+// TreeConfig configures a TreePool.
+type TreeConfig struct {
+	// Zone is the locality of the client. Healthy children in Zone are preferred.
+	Zone string
+
+	// MinHealthy is the fraction of the connections of a child that must be healthy for it to take calls while
+	// other children are healthy. Zero requires one healthy connection.
+	MinHealthy float64
+
+	// Clock times the calls of the children. Nil uses SystemClock.
+	Clock Clock
+}
+
+var _ ConnPool = &TreePool{}
+
+// TreePool is a ConnPool composing pools into a tree, e.g. per-zone pools under a regional parent and regional
+// parents under a global one, for structured multi-cluster routing behind a single grpc.ClientConnInterface.
+//
+// Every call picks a child by health, locality and latency, and the child picks the connection. Calls use the
+// healthy children in the zone of the client, or the healthy children in any zone if none is, or else any child.
+// Among those, the faster of two random children is picked, by the moving average of the latency of the unary
+// calls they completed, where failed calls count a second longer; children without calls yet count as fastest, so
+// they are tried early. The health of a
+// child is that of its connections as for ReadinessChecksHandler.
+type TreePool struct {
+	cfg      TreeConfig
+	children []*treeChild
+}
+
+type treeChild struct {
+	TreeChild
+	latency atomic.Uint64 // float64 bits of the average latency in nanoseconds, zero until measured
+}
+
+// NewTreePool creates a TreePool routing calls to children.
+func NewTreePool(cfg TreeConfig, children ...TreeChild) (*TreePool, error) {
+	if len(children) == 0 {
+		return nil, errors.New("grpcpool: tree pool has no children")
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = SystemClock()
+	}
+	p := &TreePool{cfg: cfg, children: make([]*treeChild, len(children))}
+	for i, c := range children {
+		if c.Pool == nil {
+			return nil, errors.New("grpcpool: tree child pool must not be nil")
+		}
+		p.children[i] = &treeChild{TreeChild: c}
+	}
+	return p, nil
+}
+
+// Children returns the children of the pool.
+func (p *TreePool) Children() []TreeChild {
+	children := make([]TreeChild, len(p.children))
+	for i, c := range p.children {
+		children[i] = c.TreeChild
+	}
+	return children
+}
+
+// Latency returns the average latency of the unary calls the child name completed, including the penalty of the
+// ones it failed with Unavailable, Internal or ResourceExhausted, zero if it has none. Calls whose context was done
+// first aren't counted.
+func (p *TreePool) Latency(name string) time.Duration {
+	for _, c := range p.children {
+		if c.Name == name {
+			return time.Duration(math.Float64frombits(c.latency.Load()))
+		}
+	}
+	return 0
+}
+
+// pick returns the child for the next call.
+func (p *TreePool) pick() *treeChild {
+	var buf [16]*treeChild
+	candidates := buf[:0]
+	if p.cfg.Zone != "" {
+		for _, c := range p.children {
+			if c.Zone == p.cfg.Zone && c.healthy(p.cfg.MinHealthy) {
+				candidates = append(candidates, c)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		for _, c := range p.children {
+			if c.healthy(p.cfg.MinHealthy) {
+				candidates = append(candidates, c)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = p.children
+	}
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+
+	i := rand.Intn(len(candidates))
+	j := rand.Intn(len(candidates) - 1)
+	if j >= i {
+		j++
+	}
+	a, b := candidates[i], candidates[j]
+	if math.Float64frombits(b.latency.Load()) < math.Float64frombits(a.latency.Load()) {
+		return b
+	}
+	return a
+}
+
+// healthy reports whether at least a minHealthy fraction of the connections of c is healthy, and at least one.
+func (c *treeChild) healthy(minHealthy float64) bool {
+	healthy, total := connHealth(c.Pool)
+	return healthy > 0 && healthy >= healthWant(total, minHealthy)
+}
+
+// observe adds the latency d of a call to the average of c. Concurrent updates may lose samples.
+func (c *treeChild) observe(d time.Duration) {
+	avg := math.Float64frombits(c.latency.Load())
+	if avg == 0 {
+		avg = float64(d)
+	} else {
+		avg += treeLatencyWeight * (float64(d) - avg)
+	}
+	c.latency.Store(math.Float64bits(avg))
+}
+
+// Conn returns a ClientConn from the child picked for a call.
+func (p *TreePool) Conn() *grpc.ClientConn {
+	return p.pick().Pool.Conn()
+}
+
+// Num returns the number of connections of every child.
+func (p *TreePool) Num() int {
+	n := 0
+	for _, c := range p.children {
+		n += c.Pool.Num()
+	}
+	return n
+}
+
+// Close closes every child.
+func (p *TreePool) Close() error {
+	var errs error
+	for _, c := range p.children {
+		if err := c.Pool.Close(); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	return errs
+}
+
+func (p *TreePool) Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error {
+	c := p.pick()
+	start := p.cfg.Clock.Now()
+	err := c.Pool.Invoke(ctx, method, args, reply, opts...)
+	if ctx.Err() != nil {
+		return err // the caller gave up, the call says nothing about the latency of c
+	}
+	d := p.cfg.Clock.Now().Sub(start)
+	if childFailed(err) {
+		d += treeErrorPenalty
+	}
+	c.observe(d)
+	return err
+}
+
+func (p *TreePool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return p.pick().Pool.NewStream(ctx, desc, method, opts...)
+}
@@ -0,0 +1,184 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestTreePool(t *testing.T) {
+	creds := WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials()))
+	newPool := func(target string) *Pool {
+		t.Helper()
+		p, err := NewPool(context.Background(), target, WithSize(1), creds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { p.Close() })
+		return p
+	}
+	_, l1 := mockServer(t)
+	_, l2 := mockServer(t)
+	dead, localUp, remoteUp := newPool("localhost:1"), newPool(l1.Addr().String()), newPool(l2.Addr().String())
+	waitForState(t, dead.Conn(), connectivity.TransientFailure)
+	waitForState(t, localUp.Conn(), connectivity.Ready)
+	waitForState(t, remoteUp.Conn(), connectivity.Ready)
+	children := []TreeChild{
+		{Name: "a-dead", Zone: "a", Pool: dead},
+		{Name: "a-up", Zone: "a", Pool: localUp},
+		{Name: "b-up", Zone: "b", Pool: remoteUp},
+	}
+	calls := func(p *Pool) int64 { return p.Conns()[0].Stats().Calls }
+	invoke := func(tree *TreePool, n int) {
+		for i := 0; i < n; i++ {
+			tree.Invoke(context.Background(), "/test.Test/Call", nil, nil)
+		}
+	}
+
+	local, err := NewTreePool(TreeConfig{Zone: "a"}, children...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	invoke(local, 20)
+	if calls(dead) != 0 || calls(localUp) != 20 || calls(remoteUp) != 0 {
+		t.Errorf("calls of a-dead, a-up and b-up got %d, %d, %d; want all 20 on a-up", calls(dead), calls(localUp), calls(remoteUp))
+	}
+
+	elsewhere, err := NewTreePool(TreeConfig{Zone: "c"}, children...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	invoke(elsewhere, 40)
+	if calls(dead) != 0 || calls(localUp) == 20 || calls(remoteUp) == 0 {
+		t.Errorf("calls of a-dead, a-up and b-up got %d, %d, %d; want them spread over a-up and b-up", calls(dead), calls(localUp), calls(remoteUp))
+	}
+
+	root, err := NewTreePool(TreeConfig{}, TreeChild{Name: "region", Pool: elsewhere}, TreeChild{Name: "dead", Pool: dead})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := root.Num(); n != 4 {
+		t.Errorf("Num() of the root got %d; want 4", n)
+	}
+	if healthy, total := connHealth(root); healthy != 2 || total != 4 {
+		t.Errorf("health of the root got %d of %d; want 2 of 4", healthy, total)
+	}
+	before := calls(dead)
+	invoke(root, 10)
+	if calls(dead) != before {
+		t.Error("root picked the dead child")
+	}
+}
+
+func TestTreePoolLatency(t *testing.T) {
+	tree, err := NewTreePool(TreeConfig{},
+		TreeChild{Name: "slow", Pool: &SplitPool{}},
+		TreeChild{Name: "fast", Pool: &SplitPool{}},
+		TreeChild{Name: "new", Pool: &SplitPool{}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree.children[0].observe(time.Second)
+	tree.children[1].observe(time.Millisecond)
+	tree.children[1].observe(3 * time.Millisecond)
+	if got, want := tree.Latency("fast"), 1400*time.Microsecond; got != want {
+		t.Errorf("Latency(fast) got %v; want %v", got, want)
+	}
+	picked := map[string]int{}
+	for i := 0; i < 300; i++ {
+		picked[tree.pick().Name]++
+	}
+	if picked["slow"] != 0 || picked["fast"] == 0 || picked["new"] == 0 {
+		t.Errorf("picks got %v; want the slow child never picked", picked)
+	}
+}
+
+func TestTreePoolFailures(t *testing.T) {
+	clock := &manualClock{now: time.Unix(0, 0)}
+	newPool := func(interceptor grpc.UnaryClientInterceptor) *Pool {
+		t.Helper()
+		p, err := NewPool(context.Background(), "localhost:1",
+			WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithUnaryInterceptor(interceptor)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { p.Close() })
+		return p
+	}
+	failing := newPool(func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, grpc.UnaryInvoker, ...grpc.CallOption) error {
+		return status.Error(codes.Unavailable, "down")
+	})
+	working := newPool(func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, grpc.UnaryInvoker, ...grpc.CallOption) error {
+		clock.advance(10 * time.Millisecond)
+		return nil
+	})
+	tree, err := NewTreePool(TreeConfig{Clock: clock},
+		TreeChild{Name: "failing", Pool: failing},
+		TreeChild{Name: "working", Pool: working},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		tree.Invoke(context.Background(), "/test.Test/Call", nil, nil)
+	}
+	if got := tree.Latency("failing"); got < treeErrorPenalty {
+		t.Errorf("Latency(failing) got %v; want at least the error penalty %v", got, treeErrorPenalty)
+	}
+	if got := tree.Latency("working"); got != 10*time.Millisecond {
+		t.Errorf("Latency(working) got %v; want 10ms", got)
+	}
+	if n := failing.Conns()[0].Stats().Calls; n > 2 {
+		t.Errorf("failing child got %d calls; want it avoided after its first failure", n)
+	}
+}
+
+func TestTreePoolCallerErrors(t *testing.T) {
+	clock := &manualClock{now: time.Unix(0, 0)}
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithDialOptions(
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithUnaryInterceptor(func(ctx context.Context, method string, _, _ interface{}, _ *grpc.ClientConn, _ grpc.UnaryInvoker, _ ...grpc.CallOption) error {
+				clock.advance(10 * time.Millisecond)
+				if method == "/test.Test/Bad" {
+					return status.Error(codes.InvalidArgument, "bad request")
+				}
+				return status.FromContextError(ctx.Err()).Err()
+			}),
+		))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	tree, err := NewTreePool(TreeConfig{Clock: clock}, TreeChild{Name: "child", Pool: pool})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree.Invoke(context.Background(), "/test.Test/Bad", nil, nil)
+	if got := tree.Latency("child"); got != 10*time.Millisecond {
+		t.Errorf("Latency(child) after a bad request got %v; want 10ms without the error penalty", got)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tree.Invoke(ctx, "/test.Test/Call", nil, nil)
+	if got := tree.Latency("child"); got != 10*time.Millisecond {
+		t.Errorf("Latency(child) after a canceled call got %v; want it not counted", got)
+	}
+}
+
+func TestNewTreePoolErrors(t *testing.T) {
+	if _, err := NewTreePool(TreeConfig{}); err == nil {
+		t.Error("NewTreePool without children succeeded")
+	}
+	if _, err := NewTreePool(TreeConfig{}, TreeChild{Name: "nil"}); err == nil {
+		t.Error("NewTreePool with a nil child succeeded")
+	}
+}
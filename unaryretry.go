@@ -0,0 +1,105 @@
+package grpcpool
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// trackUnsent returns the peer a unary call on c records its transport in, if the call may fail without leaving
+// the client, see retryUnsent. It returns nil for other calls, which then add no option.
+func (p *Pool) trackUnsent(c *PoolConn, opts []grpc.CallOption) *peer.Peer {
+	if !failFast(opts) || c.cc.GetState() != connectivity.TransientFailure {
+		return nil
+	}
+	return &peer.Peer{}
+}
+
+// unsent reports whether the unary call that failed with err never left the client, see retryUnsent. pr is the
+// peer returned by trackUnsent for the call.
+func (p *Pool) unsent(ctx context.Context, err error, pr *peer.Peer) bool {
+	if err == nil || ctx.Err() != nil || p.session(ctx) != nil {
+		return false
+	}
+	st, _ := status.FromError(err)
+	if closing, _ := status.FromError(grpc.ErrClientConnClosing); st.Code() == closing.Code() && st.Message() == closing.Message() {
+		return true
+	}
+	// gRPC only sets the peer of a call once it created a transport stream for it.
+	return st.Code() == codes.Unavailable && pr != nil && pr.Addr == nil
+}
+
+// failFast reports whether opts leave the call fail-fast, i.e. without grpc.WaitForReady(true).
+func failFast(opts []grpc.CallOption) bool {
+	ff := true
+	for _, o := range opts {
+		if o, ok := o.(grpc.FailFastCallOption); ok {
+			ff = o.FailFast
+		}
+	}
+	return ff
+}
+
+// retryUnsent makes the unary call on c, reserved by reserve, and retries it on the other connections of its group
+// while it didn't leave the client, trying each connection at most once. It returns the connection and error of
+// the last attempt. This is always safe, regardless of retry policies, as the server never saw the call.
+//
+// Only two failures qualify: calls on a closed connection, which fail with grpc.ErrClientConnClosing, and
+// fail-fast calls on a connection in TRANSIENT_FAILURE that gRPC failed with Unavailable before creating a
+// transport stream for them. Calls bound to a session aren't retried, see BindSession. Retries are reserved on
+// their connection as calls are, within WithMaxStreamsPerConn.
+func (p *Pool) retryUnsent(ctx context.Context, s *connSet, c *PoolConn, method string, args interface{}, reply interface{}, opts []grpc.CallOption) (*PoolConn, error) {
+	var tried []*PoolConn
+	for {
+		callOpts := opts
+		pr := p.trackUnsent(c, opts)
+		if pr != nil {
+			callOpts = append(opts[:len(opts):len(opts)], grpc.Peer(pr))
+		}
+		err := p.attempt(ctx, c, method, args, reply, callOpts)
+		if !p.unsent(ctx, err, pr) {
+			return c, err
+		}
+		tried = append(tried, c)
+		next := s.untried(c.group, tried)
+		if next == nil {
+			return c, err
+		}
+		next, rerr := p.reserveConn(ctx, next)
+		if rerr != nil {
+			return c, err
+		}
+		c.finish(err)
+		c = next
+	}
+}
+
+// untried returns a connection of group in s not in tried, preferring healthy ones, or nil if every one was.
+func (s *connSet) untried(group string, tried []*PoolConn) *PoolConn {
+	var alt *PoolConn
+	for _, o := range s.groups[group].conns {
+		if containsConn(tried, o) {
+			continue
+		}
+		if o.Healthy() {
+			return o
+		}
+		if alt == nil {
+			alt = o
+		}
+	}
+	return alt
+}
+
+func containsConn(conns []*PoolConn, c *PoolConn) bool {
+	for _, o := range conns {
+		if o == c {
+			return true
+		}
+	}
+	return false
+}
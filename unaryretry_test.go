@@ -0,0 +1,117 @@
+package grpcpool
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryUnsent(t *testing.T) {
+	l := healthTestServer(t, "", healthpb.HealthCheckResponse_SERVING)
+	pool, err := NewEndpointPool(context.Background(), []Endpoint{{Addr: "localhost:1"}, {Addr: l.Addr().String()}},
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	dead := pool.Conns()[0]
+	waitForState(t, dead.ClientConn(), connectivity.TransientFailure)
+
+	check := func(opts ...grpc.CallOption) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		return pool.Invoke(ctx, "/grpc.health.v1.Health/Check", &healthpb.HealthCheckRequest{}, &healthpb.HealthCheckResponse{}, opts...)
+	}
+	for i := 0; i < 4; i++ {
+		if err := check(); err != nil {
+			t.Errorf("call %d on a pool with a failing conn got %v; want it retried on the healthy conn", i, err)
+		}
+	}
+	if got := dead.Stats().Errors; got != 2 {
+		t.Errorf("failing conn got %d errors; want the 2 attempts picked on it", got)
+	}
+
+	// Calls waiting for the conn to be ready may have left the client, and aren't retried.
+	failed := 0
+	for i := 0; i < 2; i++ {
+		if err := check(grpc.WaitForReady(true)); status.Code(err) == codes.DeadlineExceeded {
+			failed++
+		}
+	}
+	if failed != 1 {
+		t.Errorf("%d of 2 calls waiting for ready failed; want the one on the failing conn", failed)
+	}
+
+	dead.ClientConn().Close()
+	for i := 0; i < 2; i++ {
+		if err := check(grpc.WaitForReady(true)); err != nil {
+			t.Errorf("call %d on a pool with a closed conn got %v; want it retried on the healthy conn", i, err)
+		}
+	}
+	for _, c := range pool.Conns() {
+		if got := c.InFlight(); got != 0 {
+			t.Errorf("conn %d has %d calls in flight; want none", c.Index(), got)
+		}
+	}
+}
+
+func TestRetryUnsentTriesEachConnOnce(t *testing.T) {
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithSize(4),
+		WithMaxStreamsPerConn(1, StreamSpillover{}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	for _, c := range pool.Conns() {
+		waitForState(t, c.ClientConn(), connectivity.TransientFailure)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pool.Invoke(ctx, "/grpc.health.v1.Health/Check", &healthpb.HealthCheckRequest{}, &healthpb.HealthCheckResponse{}); status.Code(err) != codes.Unavailable {
+		t.Fatalf("Invoke() on failing conns got %v; want Unavailable", err)
+	}
+	for _, c := range pool.Conns() {
+		if got := c.Stats().Errors; got != 1 {
+			t.Errorf("conn %d got %d attempts; want every conn tried once", c.Index(), got)
+		}
+		if got := c.InFlight(); got != 0 {
+			t.Errorf("conn %d has %d calls in flight; want none", c.Index(), got)
+		}
+	}
+}
+
+func TestUnsent(t *testing.T) {
+	pool := &Pool{}
+	unavailable := status.Error(codes.Unavailable, "connection error")
+	sent := &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 443}}
+	for name, tc := range map[string]struct {
+		err  error
+		pr   *peer.Peer
+		want bool
+	}{
+		"no transport stream": {unavailable, &peer.Peer{}, true},
+		"transport stream":    {unavailable, sent, false},
+		"untracked":           {unavailable, nil, false},
+		"closed conn":         {grpc.ErrClientConnClosing, nil, true},
+		"other code":          {status.Error(codes.Internal, "boom"), &peer.Peer{}, false},
+		"succeeded":           {nil, &peer.Peer{}, false},
+	} {
+		if got := pool.unsent(context.Background(), tc.err, tc.pr); got != tc.want {
+			t.Errorf("%s: unsent() got %v; want %v", name, got, tc.want)
+		}
+	}
+}
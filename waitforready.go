@@ -0,0 +1,10 @@
+package grpcpool
+
+import "google.golang.org/grpc"
+
+// WithWaitForReady sets grpc.WaitForReady(wait) on every call and stream of the pool, so with true, calls made
+// while their connection reconnects wait for it until their deadline instead of failing with Unavailable. It is a
+// default CallOption, see WithCallOptions, so grpc.WaitForReady given to a call overrides it.
+func WithWaitForReady(wait bool) Option {
+	return WithCallOptions(grpc.WaitForReady(wait))
+}
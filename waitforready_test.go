@@ -0,0 +1,40 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestWaitForReady(t *testing.T) {
+	for i, tc := range []struct {
+		opts     []Option
+		callOpts []grpc.CallOption
+		want     codes.Code
+	}{
+		{nil, nil, codes.Unavailable},
+		{[]Option{WithWaitForReady(true)}, nil, codes.DeadlineExceeded},
+		{[]Option{WithWaitForReady(true)}, []grpc.CallOption{grpc.WaitForReady(false)}, codes.Unavailable},
+		{[]Option{WithWaitForReady(false)}, []grpc.CallOption{grpc.WaitForReady(true)}, codes.DeadlineExceeded},
+	} {
+		opts := append(tc.opts, WithSize(1), WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())))
+		pool, err := NewPool(context.Background(), "localhost:1", opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		waitForState(t, pool.Conn(), connectivity.TransientFailure)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		err = pool.Invoke(ctx, "/test.Test/Call", nil, nil, tc.callOpts...)
+		cancel()
+		pool.Close()
+		if status.Code(err) != tc.want {
+			t.Errorf("case %d: Invoke got %v; want %v", i, err, tc.want)
+		}
+	}
+}
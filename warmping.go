@@ -0,0 +1,100 @@
+package grpcpool
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// PingFunc sends a lightweight call on cc, see WithWarmPings.
+type PingFunc func(ctx context.Context, cc *grpc.ClientConn) error
+
+// HealthPing is the default PingFunc, a grpc.health.v1 health check of the server. Servers without the health
+// service answer Unimplemented, which still keeps the connection warm.
+func HealthPing(ctx context.Context, cc *grpc.ClientConn) error {
+	_, err := healthpb.NewHealthClient(cc).Check(ctx, &healthpb.HealthCheckRequest{})
+	return err
+}
+
+type warmPings struct {
+	interval time.Duration
+	ping     PingFunc
+	sent     atomic.Int64
+	failed   atomic.Int64
+}
+
+// WithWarmPings sends ping, HealthPing if nil, every interval over every connection that had no calls since the
+// last interval, so NATs, load balancers and proxies don't silently drop long idle connections, which otherwise
+// shows as a burst of Unavailable errors after quiet periods.
+//
+// Unlike keepalive pings (see WithKeepalive), these are regular calls, which intermediaries terminating HTTP/2 see
+// as traffic and servers never reject as too many pings. Pings aren't counted in the call stats of connections.
+// Each ping times out after interval. Connections in TRANSIENT_FAILURE aren't pinged.
+func WithWarmPings(interval time.Duration, ping PingFunc) Option {
+	if ping == nil {
+		ping = HealthPing
+	}
+	return func(o *options) {
+		o.warmPings = &warmPings{interval: interval, ping: ping}
+	}
+}
+
+// WarmPings returns the number of warm pings sent and of those that failed, see WithWarmPings. Pings answered
+// with Unimplemented don't fail.
+func (p *Pool) WarmPings() (sent, failed int64) {
+	if p.opts.warmPings == nil {
+		return 0, 0
+	}
+	return p.opts.warmPings.sent.Load(), p.opts.warmPings.failed.Load()
+}
+
+// startWarmPings starts pinging idle connections until stop is called.
+func (p *Pool) startWarmPings() (stop func()) {
+	w := p.opts.warmPings
+	ctx, cancel := context.WithCancel(context.Background())
+	ticker := p.opts.clock.NewTicker(w.interval)
+	go func() {
+		defer ticker.Stop()
+		lastCalls := map[*PoolConn]int64{}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+				lastCalls = p.pingIdle(ctx, lastCalls)
+			}
+		}
+	}()
+	return cancel
+}
+
+// pingIdle pings the connections without calls since lastCalls, and returns their call counts.
+func (p *Pool) pingIdle(ctx context.Context, lastCalls map[*PoolConn]int64) map[*PoolConn]int64 {
+	w := p.opts.warmPings
+	conns := p.set.Load().conns
+	calls := make(map[*PoolConn]int64, len(conns))
+	for _, c := range conns {
+		n := c.calls.Load()
+		calls[c] = n
+		last, seen := lastCalls[c]
+		if !seen || n != last || c.inflight.Load() > 0 || c.cc.GetState() == connectivity.TransientFailure {
+			continue
+		}
+		go func(c *PoolConn) {
+			pingCtx, cancel := context.WithTimeout(ctx, w.interval)
+			defer cancel()
+			w.sent.Add(1)
+			err := w.ping(pingCtx, c.cc)
+			if err != nil && status.Code(err) != codes.Unimplemented && ctx.Err() == nil { // not cut by Close
+				w.failed.Add(1)
+			}
+		}(c)
+	}
+	return calls
+}
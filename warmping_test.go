@@ -0,0 +1,76 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestWarmPingsIdleOnly(t *testing.T) {
+	_, l := mockServer(t)
+
+	pinged := make(chan *grpc.ClientConn, 4)
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(2),
+		WithWarmPings(time.Hour, func(_ context.Context, cc *grpc.ClientConn) error {
+			pinged <- cc
+			return nil
+		}),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	calls := pool.pingIdle(ctx, nil) // records the call counts
+	busy, idle := pool.Conns()[0], pool.Conns()[1]
+	busy.ClientConn().Invoke(ctx, "/test.Test/Call", nil, nil)
+	busy.calls.Add(1) // as if the call went through the pool
+	pool.pingIdle(ctx, calls)
+
+	select {
+	case cc := <-pinged:
+		if cc != idle.ClientConn() {
+			t.Error("pinged the conn with calls")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("idle conn not pinged")
+	}
+	select {
+	case <-pinged:
+		t.Error("pinged both conns; want only the idle one")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if sent, failed := pool.WarmPings(); sent != 1 || failed != 0 {
+		t.Errorf("WarmPings() got %d, %d; want 1, 0", sent, failed)
+	}
+}
+
+func TestWarmPingsHealth(t *testing.T) {
+	serving := healthTestServer(t, "", healthpb.HealthCheckResponse_SERVING)
+	_, noHealth := mockServer(t)
+	for name, addr := range map[string]string{"health service": serving.Addr().String(), "no health service": noHealth.Addr().String()} {
+		pool, err := NewPool(context.Background(), addr,
+			WithWarmPings(20*time.Millisecond, nil),
+			WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+			if sent, _ := pool.WarmPings(); sent >= 2 || time.Now().After(deadline) {
+				break
+			}
+		}
+		pool.Close()
+		if sent, failed := pool.WarmPings(); sent < 2 || failed != 0 {
+			t.Errorf("%s: WarmPings() got %d, %d; want at least 2 sent and none failed", name, sent, failed)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package grpcpool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// WarmupMethod is the method of the streams opened by WarmStreams. Servers don't have to implement it:
+// an Unimplemented status still completes a round trip over the connection.
+const WarmupMethod = "/grpcpool.Warmup/Warm"
+
+var warmupDesc = &grpc.StreamDesc{StreamName: "Warm", ClientStreams: true, ServerStreams: true}
+
+// WarmStreams opens and closes n concurrent streams on every connection of the pool, waiting for the connections
+// to become ready, so dials, TLS handshakes, HTTP/2 settings and flow control windows are done before real
+// traffic arrives.
+//
+// The warmup streams bypass the picker and aren't counted in the call stats. Any status returned by the server
+// counts as success; the returned error has an error for every connection that couldn't be warmed.
+func (p *Pool) WarmStreams(ctx context.Context, n int) error {
+	conns := p.set.Load().conns
+	errs := make([]error, len(conns)*n) // by conn, then stream
+	var wg sync.WaitGroup
+	for i, c := range conns {
+		wg.Add(n)
+		for j := 0; j < n; j++ {
+			go func(i int, c *PoolConn) {
+				defer wg.Done()
+				if err := warmStream(ctx, c.cc); err != nil {
+					errs[i] = fmt.Errorf("grpcpool: warming conn %d: %w", c.index, err)
+				}
+			}(i*n+j, c)
+		}
+	}
+	wg.Wait()
+
+	var merr error
+	for i := 0; i < len(errs); i += n {
+		for _, err := range errs[i : i+n] {
+			if err != nil {
+				merr = multierror.Append(merr, err)
+				break // one error per conn
+			}
+		}
+	}
+	return merr
+}
+
+// warmStream opens a stream on cc and waits for the server to finish it.
+func warmStream(ctx context.Context, cc *grpc.ClientConn) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cs, err := cc.NewStream(ctx, warmupDesc, WarmupMethod, grpc.WaitForReady(true))
+	if err == nil {
+		if err = cs.CloseSend(); err == nil {
+			err = cs.RecvMsg(&struct{}{})
+		}
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Canceled, codes.DeadlineExceeded:
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package grpcpool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestWarmStreams(t *testing.T) {
+	_, l := mockServer(t)
+
+	pool, err := NewPool(context.Background(), l.Addr().String(),
+		WithSize(3),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pool.WarmStreams(ctx, 4); err != nil {
+		t.Fatalf("WarmStreams() got %v", err)
+	}
+	for _, c := range pool.Conns() {
+		if state := c.State(); state != connectivity.Ready {
+			t.Errorf("conn %d after warmup got %v; want READY", c.Index(), state)
+		}
+		if stats := c.Stats(); stats.Calls != 0 || stats.InFlight != 0 {
+			t.Errorf("conn %d stats after warmup got %+v; want the warmup uncounted", c.Index(), stats)
+		}
+	}
+}
+
+func TestWarmStreamsUnavailable(t *testing.T) {
+	pool, err := NewPool(context.Background(), "localhost:1",
+		WithSize(2),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := pool.WarmStreams(ctx, 2); err == nil {
+		t.Fatal("WarmStreams() to an unreachable target got no error")
+	}
+}